@@ -0,0 +1,35 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrAbsent 缓存中有 "已知不存在" 的标记(AbsentValue)，即数据源中也不存在该数据
+// 会被当做缓存不存在处理：errors.Is(ErrAbsent, ErrNotExists) 为 true
+var ErrAbsent = fmt.Errorf("%w: known absent", ErrNotExists)
+
+// absentPayload 不会和 Codec 编码后的数据冲突的标记
+const absentPayload = "\x00fscache:absent\x00"
+
+// AbsentValue "已知不存在" 的标记值，用于负缓存(negative caching)
+// 是一个 RawValue，可以写入任意支持 RawValue 的缓存，读取后 GetResult.Value 返回不存在，
+// 可以使用 IsAbsent 和缓存不存在区分
+var AbsentValue = RawValue(absentPayload)
+
+// IsAbsent 读取的结果是否为 "已知不存在" 的标记
+func IsAbsent(ret GetResult) bool {
+	if ret.Err != nil {
+		return errors.Is(ret.Err, ErrAbsent)
+	}
+	return isAbsentPayload(ret.Payload)
+}
+
+func isAbsentPayload(payload []byte) bool {
+	return bytes.Equal(payload, []byte(absentPayload))
+}
@@ -0,0 +1,46 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+)
+
+func TestAbsent(t *testing.T) {
+	fst.True(t, errors.Is(ErrAbsent, ErrNotExists))
+	fst.False(t, IsAbsent(GetResult{Err: ErrNotExists}))
+	fst.True(t, IsAbsent(GetResult{Err: ErrAbsent}))
+
+	ret := GetResult{Payload: AbsentValue, UnmarshalFunc: DefaultCodec.Unmarshal}
+	fst.True(t, IsAbsent(ret))
+	has, err := ret.Value(new(string))
+	fst.NoError(t, err)
+	fst.False(t, has)
+
+	ret = GetResult{Payload: []byte(`"v"`), UnmarshalFunc: DefaultCodec.Unmarshal}
+	fst.False(t, IsAbsent(ret))
+
+	has, err = GetResult{Err: ErrAbsent}.Value(new(string))
+	fst.NoError(t, err)
+	fst.False(t, has)
+}
+
+func TestProS_SetAbsent(t *testing.T) {
+	ctx := context.Background()
+	sc := &recordSCache{}
+	pc := &ProS[string, string]{SCache: sc}
+	fst.NoError(t, pc.SetAbsent(ctx, "k1", time.Minute))
+	fst.Equal[any](t, AbsentValue, sc.values["k1"])
+
+	sc.ret = GetResult{Payload: AbsentValue, UnmarshalFunc: DefaultCodec.Unmarshal}
+	_, err := pc.Get(ctx, "k1")
+	fst.ErrorIs(t, err, ErrAbsent)
+	fst.ErrorIs(t, err, ErrNotExists)
+}
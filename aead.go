@@ -0,0 +1,158 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// aeadMagic 加密数据头部的标记位
+const aeadMagic byte = 0xFB
+
+// aeadHeaderLen 加密数据头部的长度：1 字节标记位 + 4 字节 key ID
+const aeadHeaderLen = 5
+
+// NewAEADCodec 创建使用 AES-GCM 加密数据的编解码器
+//
+// 参数 codec：实际的编解码器，可选，为 nil 时使用 DefaultCodec；
+// 参数 keyID：加密时使用的 key 的 ID，必须在 keys 中存在；
+// 参数 keys：所有的 key，key 的长度需为 16、24 或 32 字节，旧的 key 用于解密已有的数据
+func NewAEADCodec(codec Codec, keyID uint32, keys map[uint32][]byte) (*AEADCodec, error) {
+	if _, ok := keys[keyID]; !ok {
+		return nil, fmt.Errorf("key id %d not found in keys", keyID)
+	}
+	if codec == nil {
+		codec = DefaultCodec
+	}
+	ac := &AEADCodec{
+		codec: codec,
+		keyID: keyID,
+		aeads: make(map[uint32]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", id, err)
+		}
+		ac.aeads[id] = aead
+	}
+	return ac, nil
+}
+
+// AEADCodec 对编码后的数据进行加密的编解码器
+//
+// 数据格式为：1 字节标记位 + 4 字节 key ID + nonce + 密文，
+// 通过 key ID 支持 key 轮换：使用新的 key 加密，旧的 key 依然可以解密已有的数据。
+//
+// 注意：Marshal 只认证数据头部，不会绑定缓存的 key，密文被复制到其他的 key(或者其他的缓存文件)后
+// 依然可以解密成功。若需要防止这种替换，应使用 MarshalWithAAD 和 UnmarshalWithAAD，
+// 并将缓存的 key 作为 aad 传入。
+// 每次加密的 nonce 都是随机的，编码结果不是确定的，不能用于编码缓存的 key
+type AEADCodec struct {
+	codec Codec
+	aeads map[uint32]cipher.AEAD
+
+	// AllowPlaintext 解码时是否允许未加密的数据，默认为 false
+	// 用于从未加密的缓存平滑迁移
+	AllowPlaintext bool
+
+	keyID uint32
+}
+
+// Marshal 编码并使用当前的 key 加密
+func (ac *AEADCodec) Marshal(obj any) ([]byte, error) {
+	return ac.MarshalWithAAD(obj, nil)
+}
+
+// MarshalWithAAD 编码并使用当前的 key 加密，aad 为额外认证的数据(如缓存的 key)，不会存储在结果中
+// 解码时需要使用 UnmarshalWithAAD 并传入相同的 aad
+func (ac *AEADCodec) MarshalWithAAD(obj any, aad []byte) ([]byte, error) {
+	bf, err := ac.codec.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return ac.seal(bf, aad)
+}
+
+// additionalData 认证的数据：数据头部 + aad
+func additionalData(header []byte, aad []byte) []byte {
+	if len(aad) == 0 {
+		return header
+	}
+	return append(append(make([]byte, 0, len(header)+len(aad)), header...), aad...)
+}
+
+func (ac *AEADCodec) seal(plaintext []byte, aad []byte) ([]byte, error) {
+	aead := ac.aeads[ac.keyID]
+	out := make([]byte, aeadHeaderLen+aead.NonceSize(), aeadHeaderLen+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out[0] = aeadMagic
+	binary.BigEndian.PutUint32(out[1:aeadHeaderLen], ac.keyID)
+	nonce := out[aeadHeaderLen:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, plaintext, additionalData(out[:aeadHeaderLen], aad)), nil
+}
+
+// Unmarshal 解密并解码
+func (ac *AEADCodec) Unmarshal(bf []byte, obj any) error {
+	return ac.UnmarshalWithAAD(bf, obj, nil)
+}
+
+// UnmarshalWithAAD 解密并解码使用 MarshalWithAAD 编码的数据，aad 需要和编码时的一致
+func (ac *AEADCodec) UnmarshalWithAAD(bf []byte, obj any, aad []byte) error {
+	plaintext, err := ac.open(bf, aad)
+	if err != nil {
+		return err
+	}
+	return ac.codec.Unmarshal(plaintext, obj)
+}
+
+func (ac *AEADCodec) open(bf []byte, aad []byte) ([]byte, error) {
+	if !isEncrypted(bf) {
+		if ac.AllowPlaintext {
+			return bf, nil
+		}
+		return nil, errors.New("data is not encrypted")
+	}
+	keyID := binary.BigEndian.Uint32(bf[1:aeadHeaderLen])
+	aead, ok := ac.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key id %d not found", keyID)
+	}
+	if len(bf) < aeadHeaderLen+aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted data, length=%d", len(bf))
+	}
+	nonce := bf[aeadHeaderLen : aeadHeaderLen+aead.NonceSize()]
+	return aead.Open(nil, nonce, bf[aeadHeaderLen+aead.NonceSize():], additionalData(bf[:aeadHeaderLen], aad))
+}
+
+func isEncrypted(bf []byte) bool {
+	return len(bf) >= aeadHeaderLen && bf[0] == aeadMagic
+}
+
+// Reencrypt 使用当前的 key 重新加密数据，可用于 key 轮换后更新已有的缓存数据
+// 若数据已经使用当前的 key 加密，会直接返回原数据；只支持没有 aad 的数据
+func (ac *AEADCodec) Reencrypt(bf []byte) ([]byte, error) {
+	plaintext, err := ac.open(bf, nil)
+	if err != nil {
+		return nil, err
+	}
+	if isEncrypted(bf) && binary.BigEndian.Uint32(bf[1:aeadHeaderLen]) == ac.keyID {
+		return bf, nil
+	}
+	return ac.seal(plaintext, nil)
+}
+
+var _ Codec = (*AEADCodec)(nil)
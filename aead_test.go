@@ -0,0 +1,93 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fsgo/fst"
+)
+
+func TestAEADCodec(t *testing.T) {
+	key1 := bytes.Repeat([]byte("k"), 32)
+	key2 := bytes.Repeat([]byte("n"), 16)
+
+	_, err := NewAEADCodec(nil, 3, map[uint32][]byte{1: key1})
+	fst.Error(t, err)
+	_, err = NewAEADCodec(nil, 1, map[uint32][]byte{1: []byte("short")})
+	fst.Error(t, err)
+
+	c1, err := NewAEADCodec(nil, 1, map[uint32][]byte{1: key1})
+	fst.NoError(t, err)
+
+	bf, err := c1.Marshal("secret token")
+	fst.NoError(t, err)
+	fst.False(t, bytes.Contains(bf, []byte("secret")))
+
+	var got string
+	fst.NoError(t, c1.Unmarshal(bf, &got))
+	fst.Equal(t, "secret token", got)
+
+	// 篡改数据后解密失败
+	bad := append([]byte(nil), bf...)
+	bad[len(bad)-1]++
+	fst.Error(t, c1.Unmarshal(bad, &got))
+
+	t.Run("rotation", func(t *testing.T) {
+		c2, err := NewAEADCodec(nil, 2, map[uint32][]byte{1: key1, 2: key2})
+		fst.NoError(t, err)
+
+		var got string
+		fst.NoError(t, c2.Unmarshal(bf, &got))
+		fst.Equal(t, "secret token", got)
+
+		bf2, err := c2.Reencrypt(bf)
+		fst.NoError(t, err)
+		fst.NotEqual(t, bf, bf2)
+
+		bf3, err := c2.Reencrypt(bf2)
+		fst.NoError(t, err)
+		fst.Equal(t, bf2, bf3)
+
+		// 使用旧的 key 无法解密
+		fst.Error(t, c1.Unmarshal(bf2, &got))
+
+		c3, err := NewAEADCodec(nil, 2, map[uint32][]byte{2: key2})
+		fst.NoError(t, err)
+		fst.NoError(t, c3.Unmarshal(bf2, &got))
+		fst.Equal(t, "secret token", got)
+	})
+
+	t.Run("aad", func(t *testing.T) {
+		bf, err := c1.MarshalWithAAD("v1", []byte("key1"))
+		fst.NoError(t, err)
+		var got string
+		fst.NoError(t, c1.UnmarshalWithAAD(bf, &got, []byte("key1")))
+		fst.Equal(t, "v1", got)
+
+		// 复制到其他的 key 后无法解密
+		fst.Error(t, c1.UnmarshalWithAAD(bf, &got, []byte("key2")))
+		fst.Error(t, c1.Unmarshal(bf, &got))
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		plain, err := DefaultCodec.Marshal("plain")
+		fst.NoError(t, err)
+		var got string
+		fst.Error(t, c1.Unmarshal(plain, &got))
+
+		c2, err := NewAEADCodec(nil, 1, map[uint32][]byte{1: key1})
+		fst.NoError(t, err)
+		c2.AllowPlaintext = true
+		fst.NoError(t, c2.Unmarshal(plain, &got))
+		fst.Equal(t, "plain", got)
+
+		encrypted, err := c2.Reencrypt(plain)
+		fst.NoError(t, err)
+		fst.NoError(t, c1.Unmarshal(encrypted, &got))
+		fst.Equal(t, "plain", got)
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// ErrNotInteger Incr 时，已有的缓存值不是整数
+var ErrNotInteger = errors.New("cache value is not an integer")
+
+// ErrOverflow Incr 时，计算结果超出了缓存值类型的范围
+var ErrOverflow = errors.New("cache value overflow")
+
+// Atomic 原子操作，可用于计数器、限流、分布式锁等场景
+// 如 lrucache、fsfreecache、filecache 实现了该接口
+type Atomic interface {
+	// Incr 将 key 的整数值加上 delta(可以为负数)，返回计算后的值
+	// key 不存在时以 0 为初始值，并使用 ttl 作为有效期；已存在时不会修改有效期
+	Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error)
+
+	// SetNX 当 key 不存在时写入，返回是否写入
+	SetNX(ctx context.Context, key any, value any, ttl time.Duration) (bool, error)
+
+	// CompareAndSwap 当 key 存在且其版本号和 cas 一致时写入，返回是否写入
+	// cas 为 Get 返回的 GetResult.CAS，每次写入后版本号都会变化，即使写入的值和原来的相同
+	CompareAndSwap(ctx context.Context, key any, cas uint64, value any, ttl time.Duration) (bool, error)
+}
+
+// AddInt 将缓存值 val 加上 delta，供 Atomic 的实现使用
+//
+// val 为整数类型时，返回的 newVal 和 val 的类型相同；
+// val 为 RawValue 时，使用 codec 解码为 int64 计算后，再编码为 RawValue
+func AddInt(val any, delta int64, codec Codec) (newVal any, num int64, err error) {
+	if raw, ok := val.(RawValue); ok {
+		var cur int64
+		if err = codec.Unmarshal(raw, &cur); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", ErrNotInteger, err)
+		}
+		if num, err = addInt64(cur, delta); err != nil {
+			return nil, 0, err
+		}
+		bf, err := codec.Marshal(num)
+		if err != nil {
+			return nil, 0, err
+		}
+		return RawValue(bf), num, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch {
+	case rv.CanInt():
+		if num, err = addInt64(rv.Int(), delta); err != nil {
+			return nil, 0, err
+		}
+		nv := reflect.New(rv.Type()).Elem()
+		if nv.OverflowInt(num) {
+			return nil, 0, ErrOverflow
+		}
+		nv.SetInt(num)
+		return nv.Interface(), num, nil
+	case rv.CanUint():
+		cur := rv.Uint()
+		if cur > math.MaxInt64 {
+			return nil, 0, ErrOverflow
+		}
+		if num, err = addInt64(int64(cur), delta); err != nil {
+			return nil, 0, err
+		}
+		nv := reflect.New(rv.Type()).Elem()
+		if num < 0 || nv.OverflowUint(uint64(num)) {
+			return nil, 0, ErrOverflow
+		}
+		nv.SetUint(uint64(num))
+		return nv.Interface(), num, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: %T", ErrNotInteger, val)
+	}
+}
+
+func addInt64(a int64, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
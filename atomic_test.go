@@ -0,0 +1,50 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fsgo/fst"
+)
+
+func TestAddInt(t *testing.T) {
+	t.Run("int types", func(t *testing.T) {
+		nv, num, err := AddInt(int32(5), -7, DefaultCodec)
+		fst.NoError(t, err)
+		fst.Equal[any](t, int32(-2), nv)
+		fst.Equal(t, int64(-2), num)
+
+		nv, num, err = AddInt(uint8(5), 3, DefaultCodec)
+		fst.NoError(t, err)
+		fst.Equal[any](t, uint8(8), nv)
+		fst.Equal(t, int64(8), num)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		_, _, err := AddInt(int8(127), 1, DefaultCodec)
+		fst.ErrorIs(t, err, ErrOverflow)
+		_, _, err = AddInt(uint(0), -1, DefaultCodec)
+		fst.ErrorIs(t, err, ErrOverflow)
+		_, _, err = AddInt(int64(math.MaxInt64), 1, DefaultCodec)
+		fst.ErrorIs(t, err, ErrOverflow)
+	})
+
+	t.Run("RawValue", func(t *testing.T) {
+		nv, num, err := AddInt(RawValue("41"), 1, DefaultCodec)
+		fst.NoError(t, err)
+		fst.Equal(t, int64(42), num)
+		fst.Equal[any](t, RawValue("42"), nv)
+
+		_, _, err = AddInt(RawValue(`"abc"`), 1, DefaultCodec)
+		fst.ErrorIs(t, err, ErrNotInteger)
+	})
+
+	t.Run("not integer", func(t *testing.T) {
+		_, _, err := AddInt(1.5, 1, DefaultCodec)
+		fst.ErrorIs(t, err, ErrNotInteger)
+	})
+}
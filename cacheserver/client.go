@@ -0,0 +1,323 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cacheserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/internal"
+)
+
+// ClientOption 客户端的配置
+type ClientOption struct {
+	// Codec 缓存值的编解码器，可选，默认为 fscache.DefaultCodec
+	Codec fscache.Codec
+
+	// HTTPClient 可选，默认会根据 Addr 创建
+	HTTPClient *http.Client
+
+	// Addr 服务端的地址，必填
+	// TCP 地址如 "127.0.0.1:8080"、"http://127.0.0.1:8080"，Unix Socket 地址如 "unix:/tmp/cache.sock"
+	Addr string
+
+	// Token 认证的 Token，可选，需要和服务端的一致
+	Token string
+
+	// Timeout 每个请求的超时时间，可选，为 0 时不设置
+	Timeout time.Duration
+}
+
+// Check 检查配置是否正确
+func (o *ClientOption) Check() error {
+	if o.Addr == "" {
+		return errors.New("option.Addr is empty")
+	}
+	return nil
+}
+
+// NewClient 创建一个访问 Server 的客户端
+// key 只能是 string、数字或者 bool 类型，其他类型的 key 会返回 ErrInvalidKey，不会发送给服务端；
+// 数字类型的 key 在服务端为 int64 或者 float64，大于 math.MaxInt64 的 uint64 依然为 uint64
+func NewClient(opt *ClientOption) (*Client, error) {
+	if err := opt.Check(); err != nil {
+		return nil, err
+	}
+	c := &Client{
+		opt:   opt,
+		codec: opt.Codec,
+		hc:    opt.HTTPClient,
+	}
+	if c.codec == nil {
+		c.codec = fscache.DefaultCodec
+	}
+	var dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	if path, ok := strings.CutPrefix(opt.Addr, "unix:"); ok {
+		// Unix Socket 时，URL 中的 host 没有实际意义
+		c.base = "http://unix"
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		}
+	} else {
+		c.base = strings.TrimSuffix(opt.Addr, "/")
+		if !strings.Contains(c.base, "://") {
+			c.base = "http://" + c.base
+		}
+	}
+	if c.hc == nil {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		if dial != nil {
+			tr.DialContext = dial
+		}
+		c.hc = &http.Client{Transport: tr}
+	}
+	return c, nil
+}
+
+// Client 访问 Server 的客户端，单个的接口也会使用批量接口执行
+type Client struct {
+	opt   *ClientOption
+	codec fscache.Codec
+	hc    *http.Client
+	base  string
+}
+
+func (c *Client) call(ctx context.Context, path string, req any, num int) ([]resultItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opt.Timeout)
+		defer cancel()
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	hr, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	hr.Header.Set("Content-Type", "application/json")
+	if c.opt.Token != "" {
+		hr.Header.Set("Authorization", "Bearer "+c.opt.Token)
+	}
+	resp, err := c.hc.Do(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var ret response
+	if err = json.Unmarshal(data, &ret); err != nil {
+		return nil, fmt.Errorf("status=%d, invalid response: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status=%d, %s", resp.StatusCode, ret.Error)
+	}
+	if len(ret.Items) != num {
+		return nil, fmt.Errorf("expect %d items, got %d", num, len(ret.Items))
+	}
+	return ret.Items, nil
+}
+
+func itemErr(item resultItem) error {
+	if item.Error == "" {
+		return nil
+	}
+	return errors.New(item.Error)
+}
+
+// Get 读取
+func (c *Client) Get(ctx context.Context, key any) fscache.GetResult {
+	if err := checkKey(key); err != nil {
+		return fscache.GetResult{Err: err}
+	}
+	return c.MGet(ctx, []any{key}).Get(key)
+}
+
+// Set 写入
+func (c *Client) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	if err := checkKey(key); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	return c.MSet(ctx, fscache.KVData{key: value}, ttl).Get(key)
+}
+
+// Has 判断是否存在
+func (c *Client) Has(ctx context.Context, key any) fscache.HasResult {
+	if err := checkKey(key); err != nil {
+		return fscache.HasResult{Err: err}
+	}
+	return c.MHas(ctx, []any{key}).Get(key)
+}
+
+// Delete 删除
+func (c *Client) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	if err := checkKey(key); err != nil {
+		return fscache.DeleteResult{Err: err}
+	}
+	return c.MDelete(ctx, []any{key}).Get(key)
+}
+
+// validKeys 返回类型正确的 key，不正确的 key 使用 onErr 设置结果
+// 不可比较的 key(如 slice)不能作为结果的 key，会被忽略
+func validKeys(keys []any, onErr func(key any, err error)) []any {
+	valid := make([]any, 0, len(keys))
+	for _, k := range keys {
+		if err := checkKey(k); err != nil {
+			if k != nil && reflect.TypeOf(k).Comparable() {
+				onErr(k, err)
+			}
+			continue
+		}
+		valid = append(valid, k)
+	}
+	return valid
+}
+
+// MGet 批量读取
+func (c *Client) MGet(ctx context.Context, keys []any) fscache.MGetResult {
+	result := make(fscache.MGetResult, len(keys))
+	keys = validKeys(keys, func(key any, err error) {
+		result[key] = fscache.GetResult{Err: err}
+	})
+	if len(keys) == 0 {
+		return result
+	}
+	items, err := c.call(ctx, pathGet, &keysRequest{Keys: keys}, len(keys))
+	for i, k := range keys {
+		switch {
+		case err != nil:
+			result[k] = fscache.GetResult{Err: err}
+		case items[i].NotExists:
+			result[k] = internal.GetRetNotExists
+		case items[i].Error != "":
+			result[k] = fscache.GetResult{Err: itemErr(items[i])}
+		default:
+			result[k] = fscache.GetResult{
+				Payload:       items[i].Payload,
+				UnmarshalFunc: c.codec.Unmarshal,
+			}
+		}
+	}
+	return result
+}
+
+// ttlMillis 将 ttl 转换为毫秒，不足 1 毫秒的部分向远离 0 的方向取整，
+// 避免很短的 ttl 被截断为 0 后变为永不过期
+func ttlMillis(ttl time.Duration) int64 {
+	ms := int64(ttl / time.Millisecond)
+	switch rem := ttl % time.Millisecond; {
+	case rem > 0:
+		ms++
+	case rem < 0:
+		ms--
+	}
+	return ms
+}
+
+// MSet 批量写入
+func (c *Client) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	result := make(fscache.MSetResult, len(kvs))
+	req := &setRequest{
+		TTL:   ttlMillis(ttl),
+		Items: make([]setItem, 0, len(kvs)),
+	}
+	for k, v := range kvs {
+		if err := checkKey(k); err != nil {
+			result[k] = fscache.SetResult{Err: err}
+			continue
+		}
+		payload, err := fscache.MarshalValue(c.codec.Marshal, v)
+		if err != nil {
+			result[k] = fscache.SetResult{Err: err}
+			continue
+		}
+		req.Items = append(req.Items, setItem{Key: k, Payload: payload})
+	}
+	if len(req.Items) == 0 {
+		return result
+	}
+	items, err := c.call(ctx, pathSet, req, len(req.Items))
+	for i, item := range req.Items {
+		if err != nil {
+			result[item.Key] = fscache.SetResult{Err: err}
+			continue
+		}
+		result[item.Key] = fscache.SetResult{Err: itemErr(items[i])}
+	}
+	return result
+}
+
+// MHas 批量判断是否存在
+func (c *Client) MHas(ctx context.Context, keys []any) fscache.MHasResult {
+	result := make(fscache.MHasResult, len(keys))
+	keys = validKeys(keys, func(key any, err error) {
+		result[key] = fscache.HasResult{Err: err}
+	})
+	if len(keys) == 0 {
+		return result
+	}
+	items, err := c.call(ctx, pathHas, &keysRequest{Keys: keys}, len(keys))
+	for i, k := range keys {
+		if err != nil {
+			result[k] = fscache.HasResult{Err: err}
+			continue
+		}
+		result[k] = fscache.HasResult{Has: items[i].Has, Err: itemErr(items[i])}
+	}
+	return result
+}
+
+// MDelete 批量删除
+func (c *Client) MDelete(ctx context.Context, keys []any) fscache.MDeleteResult {
+	result := make(fscache.MDeleteResult, len(keys))
+	keys = validKeys(keys, func(key any, err error) {
+		result[key] = fscache.DeleteResult{Err: err}
+	})
+	if len(keys) == 0 {
+		return result
+	}
+	items, err := c.call(ctx, pathDelete, &keysRequest{Keys: keys}, len(keys))
+	for i, k := range keys {
+		if err != nil {
+			result[k] = fscache.DeleteResult{Err: err}
+			continue
+		}
+		result[k] = fscache.DeleteResult{Deleted: items[i].Deleted, Err: itemErr(items[i])}
+	}
+	return result
+}
+
+// Reset 重置服务端的缓存，服务端的缓存需要实现 fscache.ReSetter
+func (c *Client) Reset(ctx context.Context) error {
+	_, err := c.call(ctx, pathReset, struct{}{}, 0)
+	return err
+}
+
+// Close 关闭空闲的连接
+func (c *Client) Close() error {
+	c.hc.CloseIdleConnections()
+	return nil
+}
+
+var _ fscache.Cache = (*Client)(nil)
+var _ fscache.ReSetter = (*Client)(nil)
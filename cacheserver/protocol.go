@@ -0,0 +1,112 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cacheserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 协议为 HTTP/JSON，所有的接口都是 POST 请求，批量执行，结果和请求的 key 的顺序一致
+// 缓存值为客户端使用 Codec 编码后的数据，服务端以 fscache.RawValue 存储
+const (
+	pathGet    = "/v1/get"
+	pathSet    = "/v1/set"
+	pathHas    = "/v1/has"
+	pathDelete = "/v1/delete"
+	pathReset  = "/v1/reset"
+)
+
+type keysRequest struct {
+	Keys []any `json:"keys"`
+}
+
+type setItem struct {
+	Key     any    `json:"key"`
+	Payload []byte `json:"payload"`
+}
+
+type setRequest struct {
+	Items []setItem `json:"items"`
+	TTL   int64     `json:"ttl_ms"`
+}
+
+type resultItem struct {
+	Error     string `json:"error,omitempty"`
+	Payload   []byte `json:"payload,omitempty"`
+	NotExists bool   `json:"not_exists,omitempty"`
+	Has       bool   `json:"has,omitempty"`
+	Deleted   int    `json:"deleted,omitempty"`
+}
+
+type response struct {
+	Error string       `json:"error,omitempty"`
+	Items []resultItem `json:"items,omitempty"`
+}
+
+// decodeJSON 解码请求，key 中的数字，为整数时转换为 int64，否则转换为 float64
+func decodeJSON(data []byte, obj any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(obj)
+}
+
+// ErrInvalidKey key 的类型不是 string、数字或者 bool
+var ErrInvalidKey = errors.New("invalid key, expect string, number or bool")
+
+// checkKey 检查 key 的类型，只支持 string、数字和 bool
+// 其他类型(如 slice、map、struct)不能在两端保持一致，部分还不能作为 map 的 key
+func checkKey(key any) error {
+	switch reflect.ValueOf(key).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	default:
+		return fmt.Errorf("%w: %T", ErrInvalidKey, key)
+	}
+}
+
+// normalizeKey 转换解码后的 key，整数转换为 int64，超出 int64 范围的正整数转换为 uint64，
+// 其他数字转换为 float64，不支持的类型和超出 uint64 范围的整数返回 ErrInvalidKey
+func normalizeKey(key any) (any, error) {
+	num, ok := key.(json.Number)
+	if !ok {
+		return key, checkKey(key)
+	}
+	if v, err := num.Int64(); err == nil {
+		return v, nil
+	}
+	str := num.String()
+	if !strings.ContainsAny(str, ".eE") {
+		v, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: integer %s overflows", ErrInvalidKey, str)
+		}
+		return v, nil
+	}
+	if v, err := num.Float64(); err == nil && !math.IsInf(v, 0) {
+		return v, nil
+	}
+	return str, nil
+}
+
+func normalizeKeys(keys []any) ([]any, error) {
+	for i, k := range keys {
+		nk, err := normalizeKey(k)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = nk
+	}
+	return keys, nil
+}
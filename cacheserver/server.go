@@ -0,0 +1,251 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cacheserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// Server 将任意的 fscache.Cache 通过 HTTP/JSON 协议提供给其他进程使用，实现了 http.Handler
+//
+// 可以使用 Serve 在 TCP 或者 Unix Socket 上提供服务，或者作为 http.Handler 挂载到已有的 http.Server 上
+type Server struct {
+	// Cache 提供服务的缓存，必填
+	Cache fscache.Cache
+
+	// Codec 缓存值不是编码后的数据(GetResult.Payload 为空)时，用于编码缓存值，可选，默认为 fscache.DefaultCodec
+	// 如直接写入 lrucache 的值，需要和客户端使用的 Codec 一致
+	Codec fscache.Codec
+
+	// Token 认证的 Token，可选，不为空时，请求需要携带 "Authorization: Bearer {Token}"
+	Token string
+
+	// MaxBodySize 请求的最大长度，可选，默认为 32MB
+	MaxBodySize int64
+
+	// ReadTimeout Serve 时读取请求的超时时间，可选，默认为 30s
+	ReadTimeout time.Duration
+
+	// WriteTimeout Serve 时写响应的超时时间，可选，默认为 30s
+	WriteTimeout time.Duration
+
+	// IdleTimeout Serve 时空闲连接的超时时间，可选，默认为 2min
+	IdleTimeout time.Duration
+}
+
+func (s *Server) getCodec() fscache.Codec {
+	if s.Codec == nil {
+		return fscache.DefaultCodec
+	}
+	return s.Codec
+}
+
+func (s *Server) getMaxBodySize() int64 {
+	if s.MaxBodySize > 0 {
+		return s.MaxBodySize
+	}
+	return 32 << 20
+}
+
+func durationOr(d time.Duration, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// Serve 在 l 上提供服务，直到 l 关闭
+func (s *Server) Serve(l net.Listener) error {
+	hs := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: durationOr(s.ReadTimeout, 30*time.Second),
+		ReadTimeout:       durationOr(s.ReadTimeout, 30*time.Second),
+		WriteTimeout:      durationOr(s.WriteTimeout, 30*time.Second),
+		IdleTimeout:       durationOr(s.IdleTimeout, 2*time.Minute),
+	}
+	return hs.Serve(l)
+}
+
+// ServeHTTP 处理请求
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, &response{Error: "method not allowed"})
+		return
+	}
+	if !s.auth(r) {
+		writeJSON(w, http.StatusUnauthorized, &response{Error: "unauthorized"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.getMaxBodySize()))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &response{Error: err.Error()})
+		return
+	}
+	var resp *response
+	switch r.URL.Path {
+	case pathGet:
+		resp, err = s.get(r, body)
+	case pathSet:
+		resp, err = s.set(r, body)
+	case pathHas:
+		resp, err = s.has(r, body)
+	case pathDelete:
+		resp, err = s.delete(r, body)
+	case pathReset:
+		resp, err = s.reset(r)
+	default:
+		writeJSON(w, http.StatusNotFound, &response{Error: "not found"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &response{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) auth(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, code int, resp *response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func (s *Server) get(r *http.Request, body []byte) (*response, error) {
+	var req keysRequest
+	if err := decodeJSON(body, &req); err != nil {
+		return nil, err
+	}
+	keys, err := normalizeKeys(req.Keys)
+	if err != nil {
+		return nil, err
+	}
+	ret := s.Cache.MGet(r.Context(), keys)
+	resp := &response{Items: make([]resultItem, len(keys))}
+	for i, k := range keys {
+		kr := ret.Get(k)
+		item := &resp.Items[i]
+		switch {
+		case kr.Err == nil:
+			item.Payload = kr.Payload
+			if kr.Payload == nil {
+				item.Payload, item.Error = s.encode(kr)
+			}
+		case errors.Is(kr.Err, fscache.ErrNotExists):
+			item.NotExists = true
+		default:
+			item.Error = kr.Err.Error()
+		}
+	}
+	return resp, nil
+}
+
+// encode 编码不是编码后数据的缓存值
+func (s *Server) encode(ret fscache.GetResult) ([]byte, string) {
+	var val any
+	has, err := ret.Value(&val)
+	if err != nil {
+		return nil, err.Error()
+	}
+	if !has {
+		return nil, "value not exists"
+	}
+	bf, err := s.getCodec().Marshal(val)
+	if err != nil {
+		return nil, err.Error()
+	}
+	return bf, ""
+}
+
+func (s *Server) set(r *http.Request, body []byte) (*response, error) {
+	var req setRequest
+	if err := decodeJSON(body, &req); err != nil {
+		return nil, err
+	}
+	kvs := make(fscache.KVData, len(req.Items))
+	for i, item := range req.Items {
+		key, err := normalizeKey(item.Key)
+		if err != nil {
+			return nil, err
+		}
+		req.Items[i].Key = key
+		kvs[key] = fscache.RawValue(item.Payload)
+	}
+	ret := s.Cache.MSet(r.Context(), kvs, time.Duration(req.TTL)*time.Millisecond)
+	resp := &response{Items: make([]resultItem, len(req.Items))}
+	for i, item := range req.Items {
+		resp.Items[i].Error = errString(ret.Get(item.Key).Err)
+	}
+	return resp, nil
+}
+
+func (s *Server) has(r *http.Request, body []byte) (*response, error) {
+	var req keysRequest
+	if err := decodeJSON(body, &req); err != nil {
+		return nil, err
+	}
+	keys, err := normalizeKeys(req.Keys)
+	if err != nil {
+		return nil, err
+	}
+	ret := s.Cache.MHas(r.Context(), keys)
+	resp := &response{Items: make([]resultItem, len(keys))}
+	for i, k := range keys {
+		kr := ret.Get(k)
+		resp.Items[i] = resultItem{Has: kr.Has, Error: errString(kr.Err)}
+	}
+	return resp, nil
+}
+
+func (s *Server) delete(r *http.Request, body []byte) (*response, error) {
+	var req keysRequest
+	if err := decodeJSON(body, &req); err != nil {
+		return nil, err
+	}
+	keys, err := normalizeKeys(req.Keys)
+	if err != nil {
+		return nil, err
+	}
+	ret := s.Cache.MDelete(r.Context(), keys)
+	resp := &response{Items: make([]resultItem, len(keys))}
+	for i, k := range keys {
+		kr := ret.Get(k)
+		resp.Items[i] = resultItem{Deleted: kr.Deleted, Error: errString(kr.Err)}
+	}
+	return resp, nil
+}
+
+func (s *Server) reset(r *http.Request) (*response, error) {
+	rs, ok := s.Cache.(fscache.ReSetter)
+	if !ok {
+		return nil, errors.New("not implemented ReSetter")
+	}
+	return &response{}, rs.Reset(r.Context())
+}
+
+var _ http.Handler = (*Server)(nil)
@@ -0,0 +1,124 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cacheserver
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache/cachetest"
+	"github.com/fsgo/fscache/filecache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	ts := httptest.NewServer(&Server{Cache: lc, Token: "secret"})
+	defer ts.Close()
+
+	c, err := NewClient(&ClientOption{Addr: ts.URL, Token: "secret"})
+	fst.NoError(t, err)
+	defer c.Close()
+	cachetest.CacheTest(t, c, "cacheServer")
+
+	t.Run("local value", func(t *testing.T) {
+		fst.NoError(t, lc.Set(ctx, "local", map[string]int{"a": 1}, time.Minute).Err)
+		var got map[string]int
+		has, err := c.Get(ctx, "local").Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, map[string]int{"a": 1}, got)
+	})
+
+	t.Run("number key", func(t *testing.T) {
+		fst.NoError(t, c.Set(ctx, 123, "v", time.Minute).Err)
+		fst.NoError(t, lc.Get(ctx, int64(123)).Err)
+		fst.NoError(t, c.Set(ctx, uint64(math.MaxUint64), "v", time.Minute).Err)
+		fst.NoError(t, lc.Get(ctx, uint64(math.MaxUint64)).Err)
+		fst.NoError(t, c.Set(ctx, 1.5, "v", time.Minute).Err)
+		fst.NoError(t, lc.Get(ctx, 1.5).Err)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		c2, err := NewClient(&ClientOption{Addr: ts.URL, Token: "wrong"})
+		fst.NoError(t, err)
+		err = c2.Set(ctx, "k1", "v1", time.Minute).Err
+		fst.Error(t, err)
+		fst.StringContains(t, err.Error(), "unauthorized")
+		c3, err := NewClient(&ClientOption{Addr: ts.URL})
+		fst.NoError(t, err)
+		err = c3.Get(ctx, "k1").Err
+		fst.Error(t, err)
+		fst.StringContains(t, err.Error(), "status=401")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+pathGet, strings.NewReader(`{"keys":[[1],{"a":1}]}`))
+		fst.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		fst.NoError(t, err)
+		_ = resp.Body.Close()
+		fst.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// 超出 uint64 范围的整数
+		req, err = http.NewRequest(http.MethodPost, ts.URL+pathGet, strings.NewReader(`{"keys":[18446744073709551616]}`))
+		fst.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err = http.DefaultClient.Do(req)
+		fst.NoError(t, err)
+		_ = resp.Body.Close()
+		fst.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		fst.ErrorIs(t, c.Get(ctx, struct{ A int }{A: 1}).Err, ErrInvalidKey)
+		fst.ErrorIs(t, c.Set(ctx, []int{1}, "v", time.Minute).Err, ErrInvalidKey)
+		fst.NoError(t, c.Set(ctx, "k1", "v1", time.Minute).Err)
+		ret := c.MHas(ctx, []any{"k1", struct{ A int }{A: 1}, []int{1}})
+		fst.True(t, ret.Get("k1").Has)
+		fst.ErrorIs(t, ret.Get(struct{ A int }{A: 1}).Err, ErrInvalidKey)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		fst.NoError(t, c.Set(ctx, "k1", "v1", time.Minute).Err)
+		fst.NoError(t, c.Reset(ctx))
+		fst.False(t, c.Has(ctx, "k1").Has)
+	})
+}
+
+func TestTTLMillis(t *testing.T) {
+	fst.Equal(t, int64(0), ttlMillis(0))
+	fst.Equal(t, int64(1), ttlMillis(time.Nanosecond))
+	fst.Equal(t, int64(1), ttlMillis(time.Millisecond))
+	fst.Equal(t, int64(2), ttlMillis(1500*time.Microsecond))
+	fst.Equal(t, int64(-1), ttlMillis(-time.Nanosecond))
+}
+
+func TestServer_Unix(t *testing.T) {
+	dir := t.TempDir()
+	fc, err := filecache.New(&filecache.Option{Dir: filepath.Join(dir, "data")})
+	fst.NoError(t, err)
+
+	sock := filepath.Join(dir, "cache.sock")
+	l, err := net.Listen("unix", sock)
+	fst.NoError(t, err)
+	defer l.Close()
+	go (&Server{Cache: fc}).Serve(l)
+
+	c, err := NewClient(&ClientOption{Addr: "unix:" + sock, Timeout: time.Second})
+	fst.NoError(t, err)
+	defer c.Close()
+	cachetest.CacheTest(t, c, "cacheServer_unix")
+}
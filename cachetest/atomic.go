@@ -0,0 +1,127 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachetest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+)
+
+// AtomicTest 测试 Atomic，c 需要实现 fscache.Atomic，写入的 key 都以 prefix 开头
+func AtomicTest(t *testing.T, c fscache.SCache, prefix string) {
+	ac, ok := fscache.As[fscache.Atomic](c)
+	fst.True(t, ok)
+	ctx := context.Background()
+
+	t.Run("Incr", func(t *testing.T) {
+		key := prefix + "_incr"
+		defer c.Delete(ctx, key)
+		num, err := ac.Incr(ctx, key, 2, 10*time.Second)
+		fst.NoError(t, err)
+		fst.Equal(t, int64(2), num)
+		num, err = ac.Incr(ctx, key, -5, 10*time.Second)
+		fst.NoError(t, err)
+		fst.Equal(t, int64(-3), num)
+
+		var got int64
+		has, err := c.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, int64(-3), got)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := ac.Incr(ctx, key, 1, 10*time.Second)
+				fst.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		num, err = ac.Incr(ctx, key, 0, 10*time.Second)
+		fst.NoError(t, err)
+		fst.Equal(t, int64(17), num)
+	})
+
+	t.Run("Incr not integer", func(t *testing.T) {
+		key := prefix + "_incr_str"
+		defer c.Delete(ctx, key)
+		fst.NoError(t, c.Set(ctx, key, "abc", 10*time.Second).Err)
+		_, err := ac.Incr(ctx, key, 1, 10*time.Second)
+		fst.ErrorIs(t, err, fscache.ErrNotInteger)
+	})
+
+	t.Run("SetNX", func(t *testing.T) {
+		key := prefix + "_setnx"
+		defer c.Delete(ctx, key)
+		ok, err := ac.SetNX(ctx, key, "v1", 10*time.Second)
+		fst.NoError(t, err)
+		fst.True(t, ok)
+		ok, err = ac.SetNX(ctx, key, "v2", 10*time.Second)
+		fst.NoError(t, err)
+		fst.False(t, ok)
+
+		var got string
+		_, err = c.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.Equal(t, "v1", got)
+
+		setExpired(t, c, key, "v3")
+		ok, err = ac.SetNX(ctx, key, "v4", 10*time.Second)
+		fst.NoError(t, err)
+		fst.True(t, ok)
+	})
+
+	t.Run("CompareAndSwap", func(t *testing.T) {
+		key := prefix + "_cas"
+		defer c.Delete(ctx, key)
+		ok, err := ac.CompareAndSwap(ctx, key, 1, "v0", 10*time.Second)
+		fst.NoError(t, err)
+		fst.False(t, ok)
+
+		fst.NoError(t, c.Set(ctx, key, "v1", 10*time.Second).Err)
+		ret := c.Get(ctx, key)
+		fst.NoError(t, ret.Err)
+		fst.NotEqual(t, uint64(0), ret.CAS)
+
+		ok, err = ac.CompareAndSwap(ctx, key, ret.CAS, "v2", 10*time.Second)
+		fst.NoError(t, err)
+		fst.True(t, ok)
+		ok, err = ac.CompareAndSwap(ctx, key, ret.CAS, "v3", 10*time.Second)
+		fst.NoError(t, err)
+		fst.False(t, ok)
+
+		var got string
+		_, err = c.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.Equal(t, "v2", got)
+
+		// 写入相同的值后，版本号也会变化
+		ret = c.Get(ctx, key)
+		fst.NoError(t, ret.Err)
+		fst.NoError(t, c.Set(ctx, key, "v2", 10*time.Second).Err)
+		ok, err = ac.CompareAndSwap(ctx, key, ret.CAS, "v4", 10*time.Second)
+		fst.NoError(t, err)
+		fst.False(t, ok)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := ac.Incr(canceled, prefix+"_incr_canceled", 1, 10*time.Second)
+		fst.ErrorIs(t, err, context.Canceled)
+		_, err = ac.SetNX(canceled, prefix+"_incr_canceled", 1, 10*time.Second)
+		fst.ErrorIs(t, err, context.Canceled)
+		_, err = ac.CompareAndSwap(canceled, prefix+"_incr_canceled", 1, 1, 10*time.Second)
+		fst.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+)
+
+// ContextTest 测试已取消、已超时的 ctx，所有操作都应返回对应的 ctx.Err()，且不会写入或删除缓存
+func ContextTest(t *testing.T, c fscache.Cache, prefix string) {
+	ctx := context.Background()
+	key := prefix + "_ctx"
+	fst.NoError(t, c.Set(ctx, key, 1, 10*time.Second).Err)
+	defer c.Delete(ctx, key)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	expired, cancel2 := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel2()
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{name: "canceled", ctx: canceled, want: context.Canceled},
+		{name: "deadline", ctx: expired, want: context.DeadlineExceeded},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			fst.ErrorIs(t, c.Get(tt.ctx, key).Err, tt.want)
+			fst.ErrorIs(t, c.Set(tt.ctx, key, 2, 10*time.Second).Err, tt.want)
+			fst.ErrorIs(t, c.Has(tt.ctx, key).Err, tt.want)
+			fst.ErrorIs(t, c.Delete(tt.ctx, key).Err, tt.want)
+
+			keys := []any{key, prefix + "_ctx_other"}
+			mg := c.MGet(tt.ctx, keys)
+			fst.Len(t, mg, len(keys))
+			for _, ret := range mg {
+				fst.ErrorIs(t, ret.Err, tt.want)
+			}
+			ms := c.MSet(tt.ctx, fscache.KVData{key: 2}, 10*time.Second)
+			fst.Len(t, ms, 1)
+			for _, ret := range ms {
+				fst.ErrorIs(t, ret.Err, tt.want)
+			}
+			mh := c.MHas(tt.ctx, keys)
+			fst.Len(t, mh, len(keys))
+			for _, ret := range mh {
+				fst.ErrorIs(t, ret.Err, tt.want)
+			}
+			md := c.MDelete(tt.ctx, keys)
+			fst.Len(t, md, len(keys))
+			for _, ret := range md {
+				fst.ErrorIs(t, ret.Err, tt.want)
+			}
+
+			var got int
+			has, err := c.Get(ctx, key).Value(&got)
+			fst.NoError(t, err)
+			fst.True(t, has)
+			fst.Equal(t, 1, got)
+		})
+	}
+}
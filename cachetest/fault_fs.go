@@ -0,0 +1,315 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/filecache/cachefs"
+)
+
+// ErrInjected FaultFS 注入的错误
+var ErrInjected = errors.New("injected fault")
+
+// 文件系统操作的名称，用于 FaultFS 注入错误和统计调用次数
+const (
+	OpOpen       = "Open"
+	OpRead       = "Read"
+	OpStat       = "Stat"
+	OpReadDir    = "ReadDir"
+	OpMkdirAll   = "MkdirAll"
+	OpCreateTemp = "CreateTemp"
+	OpWrite      = "Write"
+	OpSync       = "Sync"
+	OpRename     = "Rename"
+	OpRemove     = "Remove"
+	OpSyncDir    = "SyncDir"
+)
+
+// FaultFSTest 测试基于文件系统的缓存在读写文件失败时的表现
+// c 的缓存文件需要存储在 fsys 中：写入失败时 Set 应返回错误，且不影响已有的缓存；
+// 读取失败时 Get 应返回错误
+func FaultFSTest(t *testing.T, c fscache.SCache, fsys *FaultFS) {
+	ctx := context.Background()
+	key := "fault_fs_key"
+	checkValue := func(t *testing.T, want string) {
+		var got string
+		has, err := c.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, want, got)
+	}
+	defer fsys.FailOn()
+	defer fsys.SetWriteLimit(0)
+
+	fst.NoError(t, c.Set(ctx, key, "old", time.Minute).Err)
+	checkValue(t, "old")
+
+	for _, op := range []string{OpMkdirAll, OpCreateTemp, OpWrite, OpSync, OpRename, OpSyncDir} {
+		t.Run("Set fail on "+op, func(t *testing.T) {
+			fsys.ResetCalls()
+			fsys.FailOn(op)
+			ret := c.Set(ctx, key, "new", time.Minute)
+			fsys.FailOn()
+			if fsys.Calls(op, "") == 0 {
+				// 没有调用该操作，如未开启 fsync，或者目录已存在
+				fst.NoError(t, ret.Err)
+				checkValue(t, "new")
+				fst.NoError(t, c.Set(ctx, key, "old", time.Minute).Err)
+				return
+			}
+			fst.True(t, errors.Is(ret.Err, ErrInjected))
+			if op != OpSyncDir {
+				// SyncDir 失败时，缓存文件已经 rename 成功了
+				checkValue(t, "old")
+			}
+			fst.NoError(t, c.Set(ctx, key, "old", time.Minute).Err)
+		})
+	}
+
+	t.Run("Set partial write", func(t *testing.T) {
+		fsys.SetWriteLimit(10)
+		ret := c.Set(ctx, key, strings.Repeat("new", 10000), time.Minute)
+		fsys.SetWriteLimit(0)
+		fst.True(t, errors.Is(ret.Err, ErrInjected))
+		checkValue(t, "old")
+	})
+
+	for _, op := range []string{OpOpen, OpRead} {
+		t.Run("Get fail on "+op, func(t *testing.T) {
+			fsys.FailOn(op)
+			ret := c.Get(ctx, key)
+			fsys.FailOn()
+			fst.True(t, errors.Is(ret.Err, ErrInjected))
+			checkValue(t, "old")
+		})
+	}
+}
+
+// NewFaultFS 创建可以注入错误的文件系统
+func NewFaultFS(fsys cachefs.FS) *FaultFS {
+	return &FaultFS{
+		fsys:  fsys,
+		calls: map[string]map[string]int{},
+	}
+}
+
+// FaultFS 可以注入错误的文件系统，用于测试读写文件失败的场景
+type FaultFS struct {
+	fsys cachefs.FS
+
+	fault      func(op string, name string) error
+	calls      map[string]map[string]int
+	writeLimit int
+
+	mux sync.Mutex
+}
+
+// SetFault 设置注入错误的函数，每次操作前都会调用，返回非 nil 时，该操作直接返回此错误
+// op 为操作名称，如 OpOpen、OpWrite，name 为操作的文件；fn 为 nil 时不注入错误
+func (f *FaultFS) SetFault(fn func(op string, name string) error) {
+	f.mux.Lock()
+	f.fault = fn
+	f.mux.Unlock()
+}
+
+// FailOn 指定的操作都返回 ErrInjected，不传参数时取消注入错误
+func (f *FaultFS) FailOn(ops ...string) {
+	if len(ops) == 0 {
+		f.SetFault(nil)
+		return
+	}
+	f.SetFault(func(op string, _ string) error {
+		for _, o := range ops {
+			if o == op {
+				return ErrInjected
+			}
+		}
+		return nil
+	})
+}
+
+// SetWriteLimit 设置每个文件最多可以写入的字节数，超过后只写入部分数据并返回 ErrInjected
+// 为 0 时不限制
+func (f *FaultFS) SetWriteLimit(n int) {
+	f.mux.Lock()
+	f.writeLimit = n
+	f.mux.Unlock()
+}
+
+// Calls 获取操作的调用次数，name 为空时返回该操作所有文件的调用次数之和
+func (f *FaultFS) Calls(op string, name string) int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if name != "" {
+		return f.calls[op][name]
+	}
+	var num int
+	for _, n := range f.calls[op] {
+		num += n
+	}
+	return num
+}
+
+// ResetCalls 清空调用次数的统计
+func (f *FaultFS) ResetCalls() {
+	f.mux.Lock()
+	f.calls = map[string]map[string]int{}
+	f.mux.Unlock()
+}
+
+// before 统计调用次数并判断是否需要注入错误
+func (f *FaultFS) before(op string, name string) error {
+	f.mux.Lock()
+	if f.calls[op] == nil {
+		f.calls[op] = map[string]int{}
+	}
+	f.calls[op][name]++
+	fn := f.fault
+	f.mux.Unlock()
+	if fn == nil {
+		return nil
+	}
+	if err := fn(op, name); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+// Open 打开文件
+func (f *FaultFS) Open(name string) (fs.File, error) {
+	if err := f.before(OpOpen, name); err != nil {
+		return nil, err
+	}
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &faultReader{File: file, fs: f, name: name}, nil
+}
+
+// Stat 获取文件信息
+func (f *FaultFS) Stat(name string) (fs.FileInfo, error) {
+	if err := f.before(OpStat, name); err != nil {
+		return nil, err
+	}
+	return f.fsys.Stat(name)
+}
+
+// ReadDir 读取目录
+func (f *FaultFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := f.before(OpReadDir, name); err != nil {
+		return nil, err
+	}
+	return f.fsys.ReadDir(name)
+}
+
+// MkdirAll 创建目录
+func (f *FaultFS) MkdirAll(name string, perm fs.FileMode) error {
+	if err := f.before(OpMkdirAll, name); err != nil {
+		return err
+	}
+	return f.fsys.MkdirAll(name, perm)
+}
+
+// CreateTemp 创建临时文件
+func (f *FaultFS) CreateTemp(dir string, pattern string) (cachefs.File, error) {
+	if err := f.before(OpCreateTemp, dir); err != nil {
+		return nil, err
+	}
+	file, err := f.fsys.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &faultFile{File: file, fs: f}, nil
+}
+
+// Rename 重命名文件
+func (f *FaultFS) Rename(oldName string, newName string) error {
+	if err := f.before(OpRename, oldName); err != nil {
+		return err
+	}
+	return f.fsys.Rename(oldName, newName)
+}
+
+// Remove 删除文件
+func (f *FaultFS) Remove(name string) error {
+	if err := f.before(OpRemove, name); err != nil {
+		return err
+	}
+	return f.fsys.Remove(name)
+}
+
+// SyncDir 持久化目录
+func (f *FaultFS) SyncDir(name string) error {
+	if err := f.before(OpSyncDir, name); err != nil {
+		return err
+	}
+	return f.fsys.SyncDir(name)
+}
+
+type faultReader struct {
+	fs.File
+	fs   *FaultFS
+	name string
+}
+
+func (r *faultReader) Read(p []byte) (int, error) {
+	if err := r.fs.before(OpRead, r.name); err != nil {
+		return 0, err
+	}
+	return r.File.Read(p)
+}
+
+func (r *faultReader) ReadDir(n int) ([]fs.DirEntry, error) {
+	if err := r.fs.before(OpReadDir, r.name); err != nil {
+		return nil, err
+	}
+	if rd, ok := r.File.(fs.ReadDirFile); ok {
+		return rd.ReadDir(n)
+	}
+	return nil, &fs.PathError{Op: "readdir", Path: r.name, Err: errors.New("not implemented")}
+}
+
+type faultFile struct {
+	cachefs.File
+	fs      *FaultFS
+	written int
+}
+
+func (f *faultFile) Write(p []byte) (int, error) {
+	if err := f.fs.before(OpWrite, f.Name()); err != nil {
+		return 0, err
+	}
+	f.fs.mux.Lock()
+	limit := f.fs.writeLimit
+	f.fs.mux.Unlock()
+	if limit > 0 && f.written+len(p) > limit {
+		n, _ := f.File.Write(p[:max(limit-f.written, 0)])
+		f.written += n
+		return n, ErrInjected
+	}
+	n, err := f.File.Write(p)
+	f.written += n
+	return n, err
+}
+
+func (f *faultFile) Sync() error {
+	if err := f.fs.before(OpSync, f.Name()); err != nil {
+		return err
+	}
+	return f.File.Sync()
+}
+
+var _ cachefs.FS = (*FaultFS)(nil)
@@ -0,0 +1,64 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+)
+
+// ScanTest 测试 Scanner，c 需要实现 fscache.Scanner，写入的 key 都以 prefix 开头
+func ScanTest(t *testing.T, c fscache.SCache, prefix string) {
+	sc, ok := fscache.As[fscache.Scanner](c)
+	fst.True(t, ok)
+	ctx := context.Background()
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("%s_scan_%d", prefix, i)
+		fst.NoError(t, c.Set(ctx, key, i, 10*time.Second).Err)
+		want = append(want, key)
+	}
+	fst.NoError(t, c.Set(ctx, prefix+"_other", 1, 10*time.Second).Err)
+	setExpired(t, c, prefix+"_scan_expired", 1)
+
+	scan := func(t *testing.T, ctx context.Context, limit int) ([]string, error) {
+		var keys []string
+		err := sc.Scan(ctx, prefix+"_scan_", func(key any, meta fscache.ScanMeta) bool {
+			keys = append(keys, fmt.Sprint(key))
+			fst.True(t, meta.ExpireAt.IsZero() || meta.ExpireAt.After(time.Now()))
+			return limit <= 0 || len(keys) < limit
+		})
+		sort.Strings(keys)
+		return keys, err
+	}
+
+	t.Run("Scan all", func(t *testing.T) {
+		keys, err := scan(t, ctx, 0)
+		fst.NoError(t, err)
+		fst.Equal(t, want, keys)
+	})
+
+	t.Run("Scan stop", func(t *testing.T) {
+		keys, err := scan(t, ctx, 2)
+		fst.NoError(t, err)
+		fst.Equal(t, 2, len(keys))
+	})
+
+	t.Run("Scan canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		keys, err := scan(t, ctx, 0)
+		fst.Error(t, err)
+		fst.Empty(t, keys)
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+)
+
+// SnapshotTest 测试将 src 导出的快照恢复到 dst 中，src 需要实现 fscache.Dumper，dst 需要实现 fscache.Loader
+// src 和 dst 可以是不同类型的缓存，但需要使用相同的 Codec
+func SnapshotTest(t *testing.T, src fscache.SCache, dst fscache.SCache, prefix string) {
+	ctx := context.Background()
+	kv := map[string]int{}
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("%s_snapshot_%d", prefix, i)
+		kv[key] = i
+		fst.NoError(t, src.Set(ctx, key, i, time.Minute).Err)
+	}
+	setExpired(t, src, prefix+"_snapshot_expired", 1)
+
+	dumper, ok := fscache.As[fscache.Dumper](src)
+	fst.True(t, ok)
+	loader, ok := fscache.As[fscache.Loader](dst)
+	fst.True(t, ok)
+	buf := &bytes.Buffer{}
+	fst.NoError(t, dumper.Dump(ctx, buf))
+	fst.NoError(t, loader.Load(ctx, buf))
+
+	for key, want := range kv {
+		var got int
+		has, err := dst.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, want, got)
+	}
+	fst.False(t, dst.Has(ctx, prefix+"_snapshot_expired").Has)
+
+	if sc, ok := fscache.As[fscache.Scanner](dst); ok {
+		err := sc.Scan(ctx, prefix+"_snapshot_", func(key any, meta fscache.ScanMeta) bool {
+			// 恢复的缓存保留剩余的有效期
+			if !meta.ExpireAt.IsZero() {
+				fst.Less(t, time.Until(meta.ExpireAt), time.Minute+time.Second)
+			}
+			return true
+		})
+		fst.NoError(t, err)
+	}
+
+	t.Run("invalid snapshot", func(t *testing.T) {
+		fst.Error(t, loader.Load(ctx, bytes.NewReader([]byte("invalid"))))
+	})
+}
@@ -19,6 +19,7 @@ import (
 func CacheTest(t *testing.T, c fscache.Cache, prefix string) {
 	SCacheTest(t, c, prefix+"_sCache")
 	MCacheTest(t, c, prefix+"_mCache")
+	ContextTest(t, c, prefix+"_context")
 }
 
 // SCacheTest 测试SCache
@@ -168,3 +169,13 @@ func MCacheTest(t *testing.T, c fscache.MCache, prefix string) {
 		}
 	})
 }
+
+// setExpired 写入一个已过期的缓存
+// 对于 ttl <= 0 表示永不过期的缓存(如 fsfreecache)，写入后会将其删除
+func setExpired(t *testing.T, c fscache.SCache, key any, value any) {
+	ctx := context.Background()
+	fst.NoError(t, c.Set(ctx, key, value, -time.Second).Err)
+	if c.Has(ctx, key).Has {
+		fst.NoError(t, c.Delete(ctx, key).Err)
+	}
+}
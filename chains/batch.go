@@ -0,0 +1,380 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package chains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+func (c *Cache) batch() fscache.MCache {
+	// 若 Cache 实现了批量接口，会直接使用
+	return fscache.NewMCacheBySCache(c.Cache, false)
+}
+
+// mGet 批量读取，已熔断时所有 key 的结果都为缓存不存在
+func (c *Cache) mGet(ctx context.Context, keys []any) fscache.MGetResult {
+	if !c.allow() {
+		ret := make(fscache.MGetResult, len(keys))
+		for _, k := range keys {
+			ret[k] = fscache.GetResult{Err: errSkipped}
+		}
+		return ret
+	}
+	start := timeNow()
+	ret := c.batch().MGet(ctx, keys)
+	var failure error
+	for _, r := range ret {
+		if isFailure(r.Err) {
+			failure = r.Err
+			break
+		}
+	}
+	c.done(start, failure)
+	return ret
+}
+
+// mSet 批量写入，ttl 为已经过 SetTTLFn 处理后的值
+func (c *Cache) mSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	if !c.allow() {
+		ret := make(fscache.MSetResult, len(kvs))
+		for k := range kvs {
+			ret[k] = fscache.SetResult{Err: ErrBreakerOpen}
+		}
+		return ret
+	}
+	start := timeNow()
+	ret := c.batch().MSet(ctx, kvs, ttl)
+	c.done(start, ret.Err())
+	return ret
+}
+
+func (c *Cache) mDelete(ctx context.Context, keys []any) fscache.MDeleteResult {
+	if !c.allow() {
+		return newMDeleteResult(keys, ErrBreakerOpen)
+	}
+	start := timeNow()
+	ret := c.batch().MDelete(ctx, keys)
+	c.done(start, ret.Err())
+	return ret
+}
+
+// mHas 批量判断是否存在，已熔断时返回空的结果，即所有的 key 都不存在
+func (c *Cache) mHas(ctx context.Context, keys []any) fscache.MHasResult {
+	if !c.allow() {
+		return fscache.MHasResult{}
+	}
+	start := timeNow()
+	ret := c.batch().MHas(ctx, keys)
+	c.done(start, ret.Err())
+	return ret
+}
+
+// lookup 批量判断是否存在，配置了 AbsentTTL 时使用 MGet 判断，
+// 返回的 absent 为值是 fscache.AbsentValue 的 key
+func (c *Cache) lookup(ctx context.Context, keys []any) (fscache.MHasResult, map[any]bool) {
+	if c.AbsentTTL <= 0 {
+		return c.mHas(ctx, keys), nil
+	}
+	ret := c.mGet(ctx, keys)
+	result := make(fscache.MHasResult, len(keys))
+	absent := make(map[any]bool)
+	for _, k := range keys {
+		kr := ret.Get(k)
+		switch {
+		case kr.Err == nil:
+			if fscache.IsAbsent(kr) {
+				absent[k] = true
+			}
+			result[k] = fscache.HasResult{Has: !absent[k]}
+		case errors.Is(kr.Err, fscache.ErrNotExists):
+			result[k] = fscache.HasResult{}
+		default:
+			result[k] = fscache.HasResult{Err: kr.Err}
+		}
+	}
+	return result, absent
+}
+
+// promoteTTL 回填缓存时的有效期，小于等于 0 时不回填
+func (c *Cache) promoteTTL() time.Duration {
+	if c.PromoteTTL != 0 {
+		return c.PromoteTTL
+	}
+	if ttl := c.getTTL(0); ttl > 0 {
+		return ttl
+	}
+	return DefaultPromoteTTL
+}
+
+// promoteValue 回填的值
+// GetResult 中包含编码后的 Payload 时，回填的值为 fscache.RawValue，所以各级缓存需要使用相同的 Codec；
+// 没有 Payload 时(如 lrucache)，回填使用 UnmarshalFunc 读取到的原始值，由前面的缓存自己编码
+func promoteValue(ret fscache.GetResult) (any, bool) {
+	if ret.Payload != nil {
+		return fscache.RawValue(ret.Payload), true
+	}
+	if ret.UnmarshalFunc == nil {
+		return nil, false
+	}
+	var value any
+	if err := ret.UnmarshalFunc(nil, &value); err != nil || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// promote 将从第 tier 级缓存读取到的值回填到前面各级的缓存中
+func (c *SChains) promote(ctx context.Context, tier int, key any, ret fscache.GetResult) {
+	if tier == 0 {
+		return
+	}
+	value, ok := promoteValue(ret)
+	if !ok {
+		return
+	}
+	for i := 0; i < tier; i++ {
+		if ttl := c.caches[i].promoteTTL(); ttl > 0 {
+			c.caches[i].set(ctx, key, value, ttl)
+		}
+	}
+}
+
+// MGet 批量读取，先从第一级缓存批量读取所有的 key，未读取到的 key 再批量从下一级缓存读取，
+// 最后将读取到的值批量回填到前面各级的缓存中，负缓存的处理和 Get 一致
+func (c *SChains) MGet(ctx context.Context, keys []any) fscache.MGetResult {
+	result := make(fscache.MGetResult, len(keys))
+	// promotes[i] 需要回填到第 i 级缓存的值
+	promotes := make([]fscache.KVData, len(c.caches))
+	// absents[i] 需要在第 i 级缓存写入 AbsentValue 的 key
+	absents := make([][]any, len(c.caches))
+	addAbsent := func(tier int, key any) {
+		for j := 0; j < tier; j++ {
+			if c.caches[j].AbsentTTL > 0 {
+				absents[j] = append(absents[j], key)
+			}
+		}
+	}
+	// 有任意一级缓存不能确定不存在的 key
+	notMiss := make(map[any]bool)
+	missing := keys
+	for i := 0; i < len(c.caches) && len(missing) > 0; i++ {
+		if err := ctx.Err(); err != nil {
+			for _, k := range missing {
+				result[k] = fscache.GetResult{Err: err}
+			}
+			return result
+		}
+		ret := c.caches[i].mGet(ctx, missing)
+		next := make([]any, 0, len(missing))
+		for _, k := range missing {
+			kr := ret.Get(k)
+			result[k] = kr
+			if kr.Err != nil {
+				if !isMiss(kr.Err) {
+					notMiss[k] = true
+				}
+				next = append(next, k)
+				continue
+			}
+			if fscache.IsAbsent(kr) {
+				result[k] = fscache.GetResult{Err: fscache.ErrAbsent}
+				addAbsent(i, k)
+				continue
+			}
+			if i == 0 {
+				continue
+			}
+			value, ok := promoteValue(kr)
+			if !ok {
+				continue
+			}
+			for j := 0; j < i; j++ {
+				if promotes[j] == nil {
+					promotes[j] = make(fscache.KVData)
+				}
+				promotes[j][k] = value
+			}
+		}
+		missing = next
+	}
+	for _, k := range missing {
+		if !notMiss[k] {
+			addAbsent(len(c.caches), k)
+		}
+	}
+	if ctx.Err() != nil {
+		return result
+	}
+	for i, kvs := range promotes {
+		if ttl := c.caches[i].promoteTTL(); len(kvs) > 0 && ttl > 0 {
+			c.caches[i].mSet(ctx, kvs, ttl)
+		}
+	}
+	for i, ks := range absents {
+		if len(ks) == 0 {
+			continue
+		}
+		kvs := make(fscache.KVData, len(ks))
+		for _, k := range ks {
+			kvs[k] = fscache.AbsentValue
+		}
+		c.caches[i].mSet(ctx, kvs, c.caches[i].AbsentTTL)
+	}
+	return result
+}
+
+// MSet 批量写入，每一级缓存都是批量写入，写策略和 Set 一致
+// 某个 key 在任意一级缓存写入失败都会返回错误
+func (c *SChains) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	errs := make(map[any][]error, len(kvs))
+	addErr := func(tier int, key any, err error) {
+		if err != nil {
+			errs[key] = append(errs[key], fmt.Errorf("tier %d: %w", tier, err))
+		}
+	}
+	keys := make([]any, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	last := len(c.caches) - 1
+	// 异步写入时，调用方可能已经修改了 kvs 或者复用了值的底层数组
+	queued := kvs
+	if c.queue != nil {
+		queued = make(fscache.KVData, len(kvs))
+		for k, v := range kvs {
+			queued[k] = cloneValue(v)
+		}
+	}
+	for i, subCache := range c.caches {
+		if err := ctx.Err(); err != nil {
+			for _, k := range keys {
+				addErr(i, k, err)
+			}
+			continue
+		}
+		switch {
+		case c.opt.WritePolicy == WriteAround && i < last:
+			continue
+		case c.opt.WritePolicy == WriteBehind && i > 0:
+			sub, subTTL := subCache, subCache.getTTL(ttl)
+			err := c.enqueue(ctx, func() error {
+				return sub.mSet(context.WithoutCancel(ctx), queued, subTTL).Err()
+			})
+			for _, k := range keys {
+				addErr(i, k, err)
+			}
+		default:
+			ret := subCache.mSet(ctx, kvs, subCache.getTTL(ttl))
+			for _, k := range keys {
+				addErr(i, k, ret.Get(k).Err)
+			}
+		}
+	}
+	if c.opt.WritePolicy == WriteAround {
+		for i := 0; i < last; i++ {
+			if err := ctx.Err(); err != nil {
+				for _, k := range keys {
+					addErr(i, k, err)
+				}
+				continue
+			}
+			ret := c.caches[i].mDelete(ctx, keys)
+			for _, k := range keys {
+				addErr(i, k, ret.Get(k).Err)
+			}
+		}
+	}
+	result := make(fscache.MSetResult, len(kvs))
+	for _, k := range keys {
+		result[k] = fscache.SetResult{Err: errors.Join(errs[k]...)}
+	}
+	return result
+}
+
+// MDelete 批量删除所有级的缓存，每个 key 的结果和 Delete 一致
+func (c *SChains) MDelete(ctx context.Context, keys []any) fscache.MDeleteResult {
+	result := make(fscache.MDeleteResult, len(keys))
+	errs := make(map[any][]error, len(keys))
+	addErr := func(tier int, key any, err error) {
+		if err != nil {
+			errs[key] = append(errs[key], fmt.Errorf("tier %d: %w", tier, err))
+		}
+	}
+	// 异步删除时，调用方可能已经修改了 keys
+	queued := keys
+	if c.queue != nil {
+		queued = slices.Clone(keys)
+	}
+	for i, subCache := range c.caches {
+		if err := ctx.Err(); err != nil {
+			for _, k := range keys {
+				addErr(i, k, err)
+			}
+			break
+		}
+		if c.opt.WritePolicy == WriteBehind && i > 0 {
+			sub := subCache
+			err := c.enqueue(ctx, func() error {
+				return sub.mDelete(context.WithoutCancel(ctx), queued).Err()
+			})
+			for _, k := range keys {
+				addErr(i, k, err)
+			}
+			continue
+		}
+		ret := subCache.mDelete(ctx, keys)
+		for _, k := range keys {
+			r := ret.Get(k)
+			addErr(i, k, r.Err)
+			result[k] = fscache.DeleteResult{Deleted: max(result[k].Deleted, r.Deleted)}
+		}
+	}
+	for _, k := range keys {
+		result[k] = fscache.DeleteResult{Deleted: result[k].Deleted, Err: errors.Join(errs[k]...)}
+	}
+	return result
+}
+
+func newMDeleteResult(keys []any, err error) fscache.MDeleteResult {
+	result := make(fscache.MDeleteResult, len(keys))
+	for _, k := range keys {
+		result[k] = fscache.DeleteResult{Err: err}
+	}
+	return result
+}
+
+// MHas 批量判断是否存在，先从第一级缓存批量查询所有的 key，不存在的 key 再批量从下一级缓存查询
+// 负缓存的处理和 Has 一致
+func (c *SChains) MHas(ctx context.Context, keys []any) fscache.MHasResult {
+	result := make(fscache.MHasResult, len(keys))
+	missing := keys
+	for i := 0; i < len(c.caches) && len(missing) > 0; i++ {
+		if err := ctx.Err(); err != nil {
+			for _, k := range missing {
+				result[k] = fscache.HasResult{Err: err}
+			}
+			return result
+		}
+		ret, absent := c.caches[i].lookup(ctx, missing)
+		next := make([]any, 0, len(missing))
+		for _, k := range missing {
+			kr := ret.Get(k)
+			result[k] = kr
+			if !kr.Has && !absent[k] {
+				next = append(next, k)
+			}
+		}
+		missing = next
+	}
+	return result
+}
+
+var _ fscache.MCache = (*SChains)(nil)
@@ -0,0 +1,124 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package chains
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+type countCache struct {
+	fscache.Cache
+	mGetKeys [][]any
+}
+
+func (c *countCache) MGet(ctx context.Context, keys []any) fscache.MGetResult {
+	c.mGetKeys = append(c.mGetKeys, keys)
+	return c.Cache.MGet(ctx, keys)
+}
+
+func TestSChainsBatch(t *testing.T) {
+	ctx := context.Background()
+	newTier := func() *countCache {
+		lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+		fst.NoError(t, err)
+		return &countCache{Cache: lc}
+	}
+	minute := func(time.Duration) time.Duration {
+		return time.Minute
+	}
+	getValue := func(t *testing.T, ret fscache.GetResult) string {
+		var v string
+		has, err := ret.Value(&v)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		return v
+	}
+
+	t.Run("MGet", func(t *testing.T) {
+		t0, t1, t2 := newTier(), newTier(), newTier()
+		sc, err := NewSChains(nil, &Cache{Cache: t0, SetTTLFn: minute}, &Cache{Cache: t1, PromoteTTL: -1}, &Cache{Cache: t2})
+		fst.NoError(t, err)
+		fst.NoError(t, t0.Set(ctx, "k0", fscache.RawValue(`"v0"`), time.Minute).Err)
+		fst.NoError(t, t1.Set(ctx, "k1", fscache.RawValue(`"v1"`), time.Minute).Err)
+		fst.NoError(t, t2.Set(ctx, "k2", fscache.RawValue(`"v2"`), time.Minute).Err)
+
+		ret := sc.MGet(ctx, []any{"k0", "k1", "k2", "k3"})
+		fst.Len(t, ret, 4)
+		fst.Equal(t, "v0", getValue(t, ret.Get("k0")))
+		fst.Equal(t, "v1", getValue(t, ret.Get("k1")))
+		fst.Equal(t, "v2", getValue(t, ret.Get("k2")))
+		fst.ErrorIs(t, ret.Get("k3").Err, fscache.ErrNotExists)
+
+		fst.Equal(t, [][]any{{"k0", "k1", "k2", "k3"}}, t0.mGetKeys)
+		fst.Equal(t, [][]any{{"k1", "k2", "k3"}}, t1.mGetKeys)
+		fst.Equal(t, [][]any{{"k2", "k3"}}, t2.mGetKeys)
+
+		// PromoteTTL < 0 的缓存不回填
+		fst.Equal(t, "v1", getValue(t, t0.Get(ctx, "k1")))
+		fst.Equal(t, "v2", getValue(t, t0.Get(ctx, "k2")))
+		fst.ErrorIs(t, t1.Get(ctx, "k2").Err, fscache.ErrNotExists)
+	})
+
+	t.Run("Get promote", func(t *testing.T) {
+		t0, t1 := newTier(), newTier()
+		sc, err := NewSChains(nil, &Cache{Cache: t0, SetTTLFn: minute}, &Cache{Cache: t1})
+		fst.NoError(t, err)
+		fst.NoError(t, t1.Set(ctx, "k1", fscache.RawValue(`"v1"`), time.Minute).Err)
+		fst.Equal(t, "v1", getValue(t, sc.Get(ctx, "k1")))
+		fst.Equal(t, "v1", getValue(t, t0.Get(ctx, "k1")))
+
+		// 没有 Payload 的值，回填原始的值
+		fst.NoError(t, t1.Set(ctx, "k2", "v2", time.Minute).Err)
+		fst.Equal(t, "v2", getValue(t, sc.Get(ctx, "k2")))
+		fst.Equal(t, "v2", getValue(t, t0.Get(ctx, "k2")))
+	})
+
+	t.Run("default PromoteTTL", func(t *testing.T) {
+		t0, t1 := newTier(), newTier()
+		sc, err := NewSChains(nil, &Cache{Cache: t0}, &Cache{Cache: t1})
+		fst.NoError(t, err)
+		fst.NoError(t, t1.Set(ctx, "k1", "v1", time.Minute).Err)
+		fst.NoError(t, t1.Set(ctx, "k2", fscache.RawValue(`"v2"`), time.Minute).Err)
+
+		ret := sc.MGet(ctx, []any{"k1", "k2"})
+		fst.Equal(t, "v1", getValue(t, ret.Get("k1")))
+		fst.Equal(t, "v2", getValue(t, ret.Get("k2")))
+		fst.Equal(t, "v1", getValue(t, t0.Get(ctx, "k1")))
+		fst.Equal(t, "v2", getValue(t, t0.Get(ctx, "k2")))
+
+		fst.NoError(t, t1.Set(ctx, "k3", "v3", time.Minute).Err)
+		fst.Equal(t, "v3", getValue(t, sc.Get(ctx, "k3")))
+		fst.Equal(t, "v3", getValue(t, t0.Get(ctx, "k3")))
+	})
+
+	t.Run("MSet MHas MDelete", func(t *testing.T) {
+		t0, t1 := newTier(), newTier()
+		sc, err := NewSChains(&Option{WritePolicy: WriteAround}, &Cache{Cache: t0}, &Cache{Cache: t1})
+		fst.NoError(t, err)
+		fst.NoError(t, t0.Set(ctx, "k1", "old", time.Minute).Err)
+		fst.NoError(t, sc.MSet(ctx, fscache.KVData{"k1": "v1", "k2": "v2"}, time.Minute).Err())
+		fst.ErrorIs(t, t0.Get(ctx, "k1").Err, fscache.ErrNotExists)
+		fst.Equal(t, "v1", getValue(t, t1.Get(ctx, "k1")))
+
+		mh := sc.MHas(ctx, []any{"k1", "k2", "k3"})
+		fst.True(t, mh.Get("k1").Has)
+		fst.True(t, mh.Get("k2").Has)
+		fst.False(t, mh.Get("k3").Has)
+
+		// lrucache 的 Has 命中后会删除，需要重新写入
+		fst.NoError(t, sc.MSet(ctx, fscache.KVData{"k1": "v1", "k2": "v2"}, time.Minute).Err())
+		md := sc.MDelete(ctx, []any{"k1", "k2", "k3"})
+		fst.NoError(t, md.Err())
+		fst.Equal(t, 2, md.Deleted())
+		fst.ErrorIs(t, t1.Get(ctx, "k1").Err, fscache.ErrNotExists)
+	})
+}
@@ -0,0 +1,311 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package chains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// ErrBreakerOpen 该级缓存已熔断，写入和删除时会跳过该级缓存，并返回该错误
+var ErrBreakerOpen = errors.New("chains: circuit breaker is open")
+
+// errSkipped 读取时跳过已熔断的缓存，当做缓存不存在
+var errSkipped = fmt.Errorf("%w: %w", fscache.ErrNotExists, ErrBreakerOpen)
+
+var timeNow = time.Now
+
+// BreakerState 熔断器的状态
+type BreakerState uint8
+
+const (
+	// BreakerClosed 正常状态，所有请求都会执行
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen 熔断状态，所有请求都会跳过，读取时当做缓存不存在
+	BreakerOpen
+
+	// BreakerHalfOpen 半开状态，只允许少量的探测请求执行，全部成功后恢复为 BreakerClosed
+	BreakerHalfOpen
+)
+
+// String 状态的名称
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", s)
+	}
+}
+
+// BreakerStats 熔断器的统计信息
+type BreakerStats struct {
+	// OpenedAt 最近一次熔断的时间
+	OpenedAt time.Time
+
+	// Requests 当前统计窗口内的请求数
+	Requests int
+
+	// Failures 当前统计窗口内失败的请求数
+	Failures int
+
+	// Slow 当前统计窗口内的慢请求数
+	Slow int
+
+	// State 当前的状态
+	State BreakerState
+}
+
+// Breaker 熔断器，配置在 Cache.Breaker 上，每一级缓存需要使用单独的 Breaker，使用后不能复制
+//
+// 缓存不存在(fscache.ErrNotExists)和 ctx 取消不算做失败；
+// 统计窗口内请求数达到 MinRequests，且错误率达到 ErrorRate 或者慢请求比例达到 SlowRate 时熔断，
+// 熔断 OpenTimeout 后进入半开状态，允许 HalfOpenRequests 个探测请求，全部成功后恢复，否则继续熔断
+type Breaker struct {
+	// OnStateChange 状态变化时的回调，可选
+	// 在释放锁之后调用，回调中可以调用 State、Stats 等方法
+	OnStateChange func(from BreakerState, to BreakerState)
+
+	// Window 统计窗口，可选，默认为 10s
+	Window time.Duration
+
+	// OpenTimeout 熔断的时长，可选，默认为 5s
+	OpenTimeout time.Duration
+
+	// SlowThreshold 慢请求的耗时阈值，可选，为 0 时不统计慢请求
+	SlowThreshold time.Duration
+
+	// MinRequests 统计窗口内的最少请求数，可选，默认为 10
+	MinRequests int
+
+	// HalfOpenRequests 半开状态时允许的探测请求数，可选，默认为 1
+	HalfOpenRequests int
+
+	// ErrorRate 熔断的错误率阈值，可选，默认为 0.5
+	ErrorRate float64
+
+	// SlowRate 熔断的慢请求比例阈值，可选，为 0 时不按照慢请求熔断
+	SlowRate float64
+
+	windowStart time.Time
+	openedAt    time.Time
+	requests    int
+	failures    int
+	slow        int
+	probes      int
+	successes   int
+	changes     []stateChange
+	mu          sync.Mutex
+	state       BreakerState
+}
+
+// stateChange 一次状态变化
+type stateChange struct {
+	from BreakerState
+	to   BreakerState
+}
+
+func (b *Breaker) getWindow() time.Duration {
+	if b.Window > 0 {
+		return b.Window
+	}
+	return 10 * time.Second
+}
+
+func (b *Breaker) getOpenTimeout() time.Duration {
+	if b.OpenTimeout > 0 {
+		return b.OpenTimeout
+	}
+	return 5 * time.Second
+}
+
+func (b *Breaker) getMinRequests() int {
+	if b.MinRequests > 0 {
+		return b.MinRequests
+	}
+	return 10
+}
+
+func (b *Breaker) getHalfOpenRequests() int {
+	if b.HalfOpenRequests > 0 {
+		return b.HalfOpenRequests
+	}
+	return 1
+}
+
+func (b *Breaker) getErrorRate() float64 {
+	if b.ErrorRate > 0 {
+		return b.ErrorRate
+	}
+	return 0.5
+}
+
+// State 当前的状态
+func (b *Breaker) State() BreakerState {
+	return b.Stats().State
+}
+
+// Stats 当前的统计信息
+func (b *Breaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh(timeNow())
+	return BreakerStats{
+		State:    b.state,
+		OpenedAt: b.openedAt,
+		Requests: b.requests,
+		Failures: b.failures,
+		Slow:     b.slow,
+	}
+}
+
+// Allow 是否允许执行请求，允许时，请求完成后需要调用 Done
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh(timeNow())
+	switch b.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.probes >= b.getHalfOpenRequests() {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// Done 记录请求的结果，cost 为请求的耗时
+func (b *Breaker) Done(cost time.Duration, err error) {
+	failed := isFailure(err)
+	slow := b.SlowThreshold > 0 && cost >= b.SlowThreshold
+
+	b.mu.Lock()
+	defer b.unlock()
+	now := timeNow()
+	b.refresh(now)
+	switch b.state {
+	case BreakerClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if slow {
+			b.slow++
+		}
+		if b.requests < b.getMinRequests() {
+			return
+		}
+		total := float64(b.requests)
+		if float64(b.failures)/total >= b.getErrorRate() ||
+			(b.SlowRate > 0 && float64(b.slow)/total >= b.SlowRate) {
+			b.setState(BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		if failed || slow {
+			b.setState(BreakerOpen, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.getHalfOpenRequests() {
+			b.setState(BreakerClosed, now)
+		}
+	}
+}
+
+// refresh 根据时间更新状态：熔断超时后进入半开状态，统计窗口过期后重新统计
+func (b *Breaker) refresh(now time.Time) {
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) >= b.getOpenTimeout() {
+			b.setState(BreakerHalfOpen, now)
+		}
+	case BreakerClosed:
+		if now.Sub(b.windowStart) >= b.getWindow() {
+			b.resetWindow(now)
+		}
+	}
+}
+
+func (b *Breaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+	b.slow = 0
+}
+
+func (b *Breaker) setState(to BreakerState, now time.Time) {
+	from := b.state
+	b.state = to
+	b.probes = 0
+	b.successes = 0
+	switch to {
+	case BreakerOpen:
+		b.openedAt = now
+	case BreakerClosed:
+		b.resetWindow(now)
+	}
+	if b.OnStateChange != nil && from != to {
+		b.changes = append(b.changes, stateChange{from: from, to: to})
+	}
+}
+
+// unlock 释放锁，并调用 OnStateChange 通知加锁期间的状态变化
+func (b *Breaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+	for _, c := range changes {
+		b.OnStateChange(c.from, c.to)
+	}
+}
+
+// isFailure 是否是需要熔断器统计的失败
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, fscache.ErrNotExists) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// allow 该级缓存是否可用，未配置 Breaker 时总是可用
+func (c *Cache) allow() bool {
+	return c.Breaker == nil || c.Breaker.Allow()
+}
+
+// done 记录请求的结果
+func (c *Cache) done(start time.Time, err error) {
+	if c.Breaker != nil {
+		c.Breaker.Done(timeNow().Sub(start), err)
+	}
+}
+
+// State 该级缓存熔断器的状态，未配置 Breaker 时为 BreakerClosed
+func (c *Cache) State() BreakerState {
+	if c.Breaker == nil {
+		return BreakerClosed
+	}
+	return c.Breaker.State()
+}
+
+// TierStates 每一级缓存熔断器的状态，和 caches 的顺序一致
+func (c *SChains) TierStates() []BreakerState {
+	states := make([]BreakerState, len(c.caches))
+	for i, sub := range c.caches {
+		states[i] = sub.State()
+	}
+	return states
+}
@@ -0,0 +1,157 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package chains
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+// flakySCache err 不为空时，所有操作都返回 err，每次操作耗时 cost
+type flakySCache struct {
+	fscache.SCache
+	err   error
+	now   *time.Time
+	cost  time.Duration
+	calls int
+}
+
+func (f *flakySCache) call() error {
+	f.calls++
+	*f.now = f.now.Add(f.cost)
+	return f.err
+}
+
+func (f *flakySCache) Get(ctx context.Context, key any) fscache.GetResult {
+	if err := f.call(); err != nil {
+		return fscache.GetResult{Err: err}
+	}
+	return f.SCache.Get(ctx, key)
+}
+
+func (f *flakySCache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	if err := f.call(); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	return f.SCache.Set(ctx, key, value, ttl)
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time {
+		return now
+	}
+	defer func() {
+		timeNow = time.Now
+	}()
+	ctx := context.Background()
+	newLRU := func() fscache.SCache {
+		lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+		fst.NoError(t, err)
+		return lc
+	}
+	errFail := errors.New("tier failed")
+
+	t.Run("error rate", func(t *testing.T) {
+		var changes []BreakerState
+		br := &Breaker{
+			MinRequests: 2,
+			ErrorRate:   0.6,
+			OpenTimeout: time.Second,
+			OnStateChange: func(from BreakerState, to BreakerState) {
+				changes = append(changes, to)
+			},
+		}
+		tier0 := &flakySCache{SCache: newLRU(), now: &now}
+		tier1 := newLRU()
+		sc, err := NewSChains(nil, &Cache{Cache: tier0, Breaker: br}, &Cache{Cache: tier1})
+		fst.NoError(t, err)
+		fst.NoError(t, tier1.Set(ctx, "k1", "v1", time.Hour).Err)
+
+		// ErrNotExists 不算做失败
+		fst.ErrorIs(t, sc.Get(ctx, "k2").Err, fscache.ErrNotExists)
+		fst.Equal(t, BreakerClosed, br.State())
+
+		tier0.err = errFail
+		for i := 0; i < 2; i++ {
+			fst.NoError(t, sc.Get(ctx, "k1").Err)
+		}
+		fst.Equal(t, 3, tier0.calls)
+		fst.Equal(t, []BreakerState{BreakerOpen, BreakerClosed}, sc.TierStates())
+		fst.Equal(t, 3, br.Stats().Requests)
+		fst.Equal(t, 2, br.Stats().Failures)
+
+		// 熔断后跳过第一级缓存
+		fst.NoError(t, sc.Get(ctx, "k1").Err)
+		fst.False(t, sc.MHas(ctx, []any{"k3"}).Get("k3").Has)
+		ret := sc.SetTiers(ctx, "k3", "v3", time.Hour)
+		fst.ErrorIs(t, ret[0].Err, ErrBreakerOpen)
+		fst.NoError(t, ret[1].Err)
+		fst.Equal(t, 3, tier0.calls)
+
+		// 半开状态探测失败，继续熔断
+		now = now.Add(time.Second)
+		fst.Equal(t, BreakerHalfOpen, br.State())
+		fst.NoError(t, sc.Get(ctx, "k1").Err)
+		fst.Equal(t, 4, tier0.calls)
+		fst.Equal(t, BreakerOpen, br.State())
+
+		// 半开状态探测成功，恢复
+		now = now.Add(time.Second)
+		tier0.err = nil
+		fst.NoError(t, sc.Set(ctx, "k1", "v1", time.Hour).Err)
+		fst.Equal(t, BreakerClosed, br.State())
+		fst.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}, changes)
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		br := &Breaker{
+			MinRequests:   2,
+			SlowThreshold: 100 * time.Millisecond,
+			SlowRate:      1,
+		}
+		tier0 := &flakySCache{SCache: newLRU(), now: &now, cost: 200 * time.Millisecond}
+		sc, err := NewSChains(nil, &Cache{Cache: tier0, Breaker: br})
+		fst.NoError(t, err)
+		fst.NoError(t, sc.Set(ctx, "k1", "v1", time.Hour).Err)
+		fst.Equal(t, BreakerClosed, br.State())
+		fst.NoError(t, sc.Get(ctx, "k1").Err)
+		fst.Equal(t, BreakerOpen, br.State())
+		fst.ErrorIs(t, sc.Get(ctx, "k1").Err, fscache.ErrNotExists)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		br := &Breaker{MinRequests: 2, Window: time.Second}
+		br.Done(0, errFail)
+		fst.Equal(t, 1, br.Stats().Failures)
+		now = now.Add(time.Second)
+		br.Done(0, context.Canceled)
+		br.Done(0, nil)
+		fst.Equal(t, BreakerClosed, br.State())
+		fst.Equal(t, 0, br.Stats().Failures)
+	})
+
+	t.Run("OnStateChange", func(t *testing.T) {
+		var states []BreakerState
+		br := &Breaker{MinRequests: 1, OpenTimeout: time.Second}
+		// 回调中可以调用 State 和 Stats
+		br.OnStateChange = func(from BreakerState, to BreakerState) {
+			states = append(states, br.State())
+			fst.Equal(t, to, br.Stats().State)
+		}
+		br.Done(0, errFail)
+		now = now.Add(time.Second)
+		fst.True(t, br.Allow())
+		br.Done(0, nil)
+		fst.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}, states)
+	})
+}
@@ -5,7 +5,12 @@
 package chains
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/fsgo/fscache"
@@ -14,27 +19,145 @@ import (
 // SetTTLFn 设置缓存的 ttl,参数 ttl 可能为空
 type SetTTLFn func(ttl time.Duration) time.Duration
 
-// New 创建一个链式缓存
+// ErrClosed 链式缓存已经 Close，不能再异步写入
+var ErrClosed = errors.New("chains: closed")
+
+// New 创建一个链式缓存，使用 WriteThrough 写策略
 func New(caches ...*Cache) fscache.Cache {
 	if len(caches) == 0 {
 		panic("no caches")
 	}
-	sc := &sChains{
-		caches: caches,
+	sc, err := NewSChains(nil, caches...)
+	if err != nil {
+		panic(err)
 	}
 	return fscache.NewTemplate(sc, true)
 }
 
-type sChains struct {
-	caches []*Cache
+// NewWithOption 创建一个链式缓存，opt 可以为 nil
+// 当写策略为 WriteBehind 时，使用完后需要调用 Close 以写入队列中剩余的数据
+func NewWithOption(opt *Option, caches ...*Cache) (fscache.Cache, error) {
+	sc, err := NewSChains(opt, caches...)
+	if err != nil {
+		return nil, err
+	}
+	return fscache.NewTemplate(sc, true), nil
+}
+
+// NewSChains 创建一个链式缓存，opt 可以为 nil
+func NewSChains(opt *Option, caches ...*Cache) (*SChains, error) {
+	if len(caches) == 0 {
+		return nil, errors.New("no caches")
+	}
+	if opt == nil {
+		opt = &Option{}
+	}
+	if err := opt.Check(); err != nil {
+		return nil, err
+	}
+	c := &SChains{
+		caches: caches,
+		opt:    opt,
+	}
+	if opt.WritePolicy == WriteBehind && len(caches) > 1 {
+		c.queue = make(chan func() error, opt.getQueueSize())
+		c.done = make(chan struct{})
+		go c.loop()
+	}
+	return c, nil
+}
+
+// WritePolicy 写策略
+type WritePolicy uint8
+
+const (
+	// WriteThrough 按顺序同步写入所有级的缓存，默认的写策略
+	WriteThrough WritePolicy = iota
+
+	// WriteAround 只写入最后一级缓存(权威缓存)，并删除前面各级的缓存
+	WriteAround
+
+	// WriteBehind 同步写入第一级缓存，其他级的缓存放入队列异步写入
+	// 放入队列时会复制 []byte 和 fscache.RawValue 类型的值，其他类型的值在写入后不应再修改
+	WriteBehind
+)
+
+// String 写策略的名称
+func (p WritePolicy) String() string {
+	switch p {
+	case WriteThrough:
+		return "WriteThrough"
+	case WriteAround:
+		return "WriteAround"
+	case WriteBehind:
+		return "WriteBehind"
+	default:
+		return fmt.Sprintf("WritePolicy(%d)", p)
+	}
+}
+
+// Option NewWithOption 的参数
+type Option struct {
+	// OnError WriteBehind 时，异步写入失败的回调，可选
+	// 默认会打印日志
+	OnError func(err error)
+
+	// QueueSize WriteBehind 时，异步写入队列的长度，可选，默认为 1024
+	// 队列满时，Set 和 Delete 会等待，直到队列有空位或者 ctx 被取消
+	QueueSize int
+
+	// WritePolicy 写策略，默认为 WriteThrough
+	WritePolicy WritePolicy
+}
+
+// Check 检查参数是否正确
+func (o *Option) Check() error {
+	if o.WritePolicy > WriteBehind {
+		return fmt.Errorf("invalid WritePolicy %s", o.WritePolicy)
+	}
+	if o.QueueSize < 0 {
+		return fmt.Errorf("QueueSize=%d, expect >= 0", o.QueueSize)
+	}
+	return nil
+}
+
+func (o *Option) getQueueSize() int {
+	if o.QueueSize > 0 {
+		return o.QueueSize
+	}
+	return 1024
+}
+
+func (o *Option) onError(err error) {
+	if o.OnError != nil {
+		o.OnError(err)
+		return
+	}
+	log.Println("[chains][warn] write behind with error:", err)
 }
 
 // Cache New 的参数
 type Cache struct {
 	Cache    fscache.SCache
 	SetTTLFn SetTTLFn
+
+	// Breaker 熔断器，可选，熔断后读取时会跳过该级缓存，当做缓存不存在
+	Breaker *Breaker
+
+	// AbsentTTL 负缓存的有效期，可选，为 0 时不启用
+	// 大于 0 时，若所有级的缓存都不存在，会在该级缓存写入 fscache.AbsentValue，
+	// 之后读取到该标记时不再查询后面的缓存，返回 fscache.ErrAbsent；
+	// 通过链式缓存 Set 时，该标记会被覆盖
+	AbsentTTL time.Duration
+
+	// PromoteTTL 从后面的缓存读取到值时，回填到该级缓存的有效期，可选
+	// 为 0 时使用 SetTTLFn(0)，其值小于等于 0 时使用 DefaultPromoteTTL；小于 0 时不回填
+	PromoteTTL time.Duration
 }
 
+// DefaultPromoteTTL 回填缓存时默认的有效期
+const DefaultPromoteTTL = 10 * time.Minute
+
 func (c *Cache) getTTL(ttl time.Duration) time.Duration {
 	if c.SetTTLFn == nil {
 		return ttl
@@ -42,45 +165,234 @@ func (c *Cache) getTTL(ttl time.Duration) time.Duration {
 	return c.SetTTLFn(ttl)
 }
 
-func (c *sChains) Get(ctx context.Context, key any) (result fscache.GetResult) {
+func (c *Cache) get(ctx context.Context, key any) fscache.GetResult {
+	if !c.allow() {
+		return fscache.GetResult{Err: errSkipped}
+	}
+	start := timeNow()
+	ret := c.Cache.Get(ctx, key)
+	c.done(start, ret.Err)
+	return ret
+}
+
+// set 写入，ttl 为已经过 SetTTLFn 处理后的值
+func (c *Cache) set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	if !c.allow() {
+		return fscache.SetResult{Err: ErrBreakerOpen}
+	}
+	start := timeNow()
+	ret := c.Cache.Set(ctx, key, value, ttl)
+	c.done(start, ret.Err)
+	return ret
+}
+
+func (c *Cache) has(ctx context.Context, key any) fscache.HasResult {
+	if !c.allow() {
+		return fscache.HasResult{}
+	}
+	start := timeNow()
+	ret := c.Cache.Has(ctx, key)
+	c.done(start, ret.Err)
+	return ret
+}
+
+func (c *Cache) delete(ctx context.Context, key any) fscache.DeleteResult {
+	if !c.allow() {
+		return fscache.DeleteResult{Err: ErrBreakerOpen}
+	}
+	start := timeNow()
+	ret := c.Cache.Delete(ctx, key)
+	c.done(start, ret.Err)
+	return ret
+}
+
+// TierResults 每一级缓存的写入结果，和 caches 的顺序一致
+// WriteAround 时，前面各级缓存的结果为删除的结果；
+// WriteBehind 时，后面各级缓存的结果为放入队列的结果
+type TierResults []fscache.SetResult
+
+// Err 汇总所有级的错误，没有错误时返回 nil
+func (tr TierResults) Err() error {
+	var errs []error
+	for i, r := range tr {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("tier %d: %w", i, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SChains 链式缓存，读取时按顺序查找，写入时按照 Option.WritePolicy 写入
+// 已熔断的缓存，读取时会跳过，写入和删除的结果为 ErrBreakerOpen
+type SChains struct {
+	opt    *Option
+	queue  chan func() error
+	done   chan struct{}
+	caches []*Cache
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Get 按顺序从各级缓存中读取，直到读取成功，并将读取到的值回填到前面各级的缓存中
+// 读取到 fscache.AbsentValue 时，返回 fscache.ErrAbsent
+func (c *SChains) Get(ctx context.Context, key any) (result fscache.GetResult) {
+	// 所有级的缓存是否都确定不存在
+	absent := true
 	for i := 0; i < len(c.caches); i++ {
+		if err := ctx.Err(); err != nil {
+			return fscache.GetResult{Err: err}
+		}
 		subCache := c.caches[i]
-		if result = subCache.Cache.Get(ctx, key); result.Err == nil {
-			break
+		result = subCache.get(ctx, key)
+		if result.Err == nil {
+			if fscache.IsAbsent(result) {
+				c.setAbsent(ctx, i, key)
+				return fscache.GetResult{Err: fscache.ErrAbsent}
+			}
+			c.promote(ctx, i, key, result)
+			return result
 		}
+		absent = absent && isMiss(result.Err)
+	}
+	if absent {
+		c.setAbsent(ctx, len(c.caches), key)
 	}
-	// todo 将从后面cache 查询的结果赋值到前面的 cache 中
 	return result
 }
 
-func (c *sChains) Set(ctx context.Context, key any, value any, ttl time.Duration) (result fscache.SetResult) {
+// isMiss 是否是确定的缓存不存在，已熔断跳过的不算
+func isMiss(err error) bool {
+	return errors.Is(err, fscache.ErrNotExists) && !errors.Is(err, ErrBreakerOpen)
+}
+
+// setAbsent 在前 tier 级配置了 AbsentTTL 的缓存中写入 fscache.AbsentValue
+func (c *SChains) setAbsent(ctx context.Context, tier int, key any) {
+	for i := 0; i < tier; i++ {
+		if ttl := c.caches[i].AbsentTTL; ttl > 0 {
+			c.caches[i].set(ctx, key, fscache.AbsentValue, ttl)
+		}
+	}
+}
+
+// Set 写入，任意一级缓存写入失败都会返回错误
+func (c *SChains) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	return fscache.SetResult{Err: c.SetTiers(ctx, key, value, ttl).Err()}
+}
+
+// SetTiers 写入，并返回每一级缓存的写入结果
+func (c *SChains) SetTiers(ctx context.Context, key any, value any, ttl time.Duration) TierResults {
+	results := make(TierResults, len(c.caches))
+	last := len(c.caches) - 1
+	// 异步写入时，调用方可能已经修改或者复用了 value 的底层数组
+	queued := value
+	if c.queue != nil {
+		queued = cloneValue(value)
+	}
+	for i, subCache := range c.caches {
+		if err := ctx.Err(); err != nil {
+			results[i] = fscache.SetResult{Err: err}
+			continue
+		}
+		switch {
+		case c.opt.WritePolicy == WriteAround && i < last:
+			// 需要先写入权威缓存，再删除前面的缓存，以免前面的缓存被回填旧的值
+			continue
+		case c.opt.WritePolicy == WriteBehind && i > 0:
+			sub, subTTL := subCache, subCache.getTTL(ttl)
+			results[i].Err = c.enqueue(ctx, func() error {
+				return sub.set(context.WithoutCancel(ctx), key, queued, subTTL).Err
+			})
+		default:
+			results[i] = subCache.set(ctx, key, value, subCache.getTTL(ttl))
+		}
+	}
+	if c.opt.WritePolicy == WriteAround {
+		for i := 0; i < last; i++ {
+			if err := ctx.Err(); err != nil {
+				results[i] = fscache.SetResult{Err: err}
+				continue
+			}
+			results[i].Err = c.caches[i].delete(ctx, key).Err
+		}
+	}
+	return results
+}
+
+// Has 按顺序判断各级缓存是否存在
+// 配置了 AbsentTTL 的缓存会使用 Get 判断，读取到 fscache.AbsentValue 时，返回不存在
+func (c *SChains) Has(ctx context.Context, key any) (result fscache.HasResult) {
 	for i := 0; i < len(c.caches); i++ {
+		if err := ctx.Err(); err != nil {
+			return fscache.HasResult{Err: err}
+		}
 		subCache := c.caches[i]
-		result = subCache.Cache.Set(ctx, key, value, subCache.getTTL(ttl))
+		if subCache.AbsentTTL <= 0 {
+			if result = subCache.has(ctx, key); result.Has {
+				return result
+			}
+			continue
+		}
+		ret := subCache.get(ctx, key)
+		switch {
+		case ret.Err == nil:
+			return fscache.HasResult{Has: !fscache.IsAbsent(ret)}
+		case errors.Is(ret.Err, fscache.ErrNotExists):
+			result = fscache.HasResult{}
+		default:
+			result = fscache.HasResult{Err: ret.Err}
+		}
 	}
 	return result
 }
 
-func (c *sChains) Has(ctx context.Context, key any) (result fscache.HasResult) {
-	for i := 0; i < len(c.caches); i++ {
-		result = c.caches[i].Cache.Has(ctx, key)
-		if result.Has {
-			return result
+// Delete 删除所有级的缓存，返回的 Deleted 为各级缓存中删除数量的最大值，
+// 任意一级缓存删除失败都会返回错误，错误中包含所有失败的缓存
+// WriteBehind 时，只同步删除第一级缓存，其他级的缓存放入队列异步删除，以保证和 Set 的顺序一致
+func (c *SChains) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	var result fscache.DeleteResult
+	var errs []error
+	for i, subCache := range c.caches {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("tier %d: %w", i, err))
+			break
+		}
+		if c.opt.WritePolicy == WriteBehind && i > 0 {
+			sub := subCache
+			if err := c.enqueue(ctx, func() error {
+				return sub.delete(context.WithoutCancel(ctx), key).Err
+			}); err != nil {
+				errs = append(errs, fmt.Errorf("tier %d: %w", i, err))
+			}
+			continue
+		}
+		ret := subCache.delete(ctx, key)
+		if ret.Err != nil {
+			errs = append(errs, fmt.Errorf("tier %d: %w", i, ret.Err))
 		}
+		result.Deleted = max(result.Deleted, ret.Deleted)
 	}
+	result.Err = errors.Join(errs...)
 	return result
 }
 
-func (c *sChains) Delete(ctx context.Context, key any) (result fscache.DeleteResult) {
-	for i := 0; i < len(c.caches); i++ {
-		subCache := c.caches[i]
-		result = subCache.Cache.Delete(ctx, key)
+// cloneValue 复制 []byte 和 fscache.RawValue 类型的值，其他类型的值原样返回
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		return bytes.Clone(v)
+	case fscache.RawValue:
+		return fscache.RawValue(bytes.Clone(v))
+	default:
+		return value
 	}
-	return
 }
 
-func (c *sChains) Reset(ctx context.Context) (err error) {
+// Reset 重置所有级的缓存
+func (c *SChains) Reset(ctx context.Context) (err error) {
 	for i := 0; i < len(c.caches); i++ {
+		if e := ctx.Err(); e != nil {
+			return e
+		}
 		subCache := c.caches[i]
 		if sc, ok := subCache.Cache.(fscache.ReSetter); ok {
 			if e := sc.Reset(ctx); e != nil {
@@ -91,5 +403,55 @@ func (c *sChains) Reset(ctx context.Context) (err error) {
 	return err
 }
 
-var _ fscache.SCache = (*sChains)(nil)
-var _ fscache.ReSetter = (*sChains)(nil)
+// Close 关闭，WriteBehind 时会等待队列中的数据全部写入完成
+// 不会关闭各级缓存
+func (c *SChains) Close() error {
+	if c.queue == nil {
+		return nil
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+	<-c.done
+	return nil
+}
+
+func (c *SChains) enqueue(ctx context.Context, fn func() error) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+	select {
+	case c.queue <- fn:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *SChains) loop() {
+	defer close(c.done)
+	for fn := range c.queue {
+		if err := c.run(fn); err != nil {
+			c.opt.onError(err)
+		}
+	}
+}
+
+func (c *SChains) run(fn func() error) (err error) {
+	defer func() {
+		if re := recover(); re != nil {
+			err = fmt.Errorf("panic: %v", re)
+		}
+	}()
+	return fn()
+}
+
+var _ fscache.SCache = (*SChains)(nil)
+var _ fscache.ReSetter = (*SChains)(nil)
@@ -6,12 +6,16 @@ package chains
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/fsgo/fst"
 
 	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/cachetest"
 	"github.com/fsgo/fscache/lrucache"
 )
 
@@ -65,3 +69,185 @@ func Test_sChains(t *testing.T) {
 	checkHas(t, lc1.Get(ctx, key2), key2)
 	checkHas(t, lc2.Get(ctx, key2), key2)
 }
+
+func Test_sChainsContext(t *testing.T) {
+	lc1, _ := lrucache.New(&lrucache.Option{Capacity: 100})
+	lc2, _ := lrucache.New(&lrucache.Option{Capacity: 100})
+	cc := New(&Cache{Cache: lc1}, &Cache{Cache: lc2})
+	cachetest.ContextTest(t, cc, "chains")
+}
+
+type errSCache struct {
+	fscache.SCache
+	err error
+}
+
+func (e *errSCache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	return fscache.SetResult{Err: e.err}
+}
+
+func (e *errSCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	return fscache.DeleteResult{Err: e.err}
+}
+
+func TestWritePolicy(t *testing.T) {
+	ctx := context.Background()
+	newLRU := func() fscache.SCache {
+		lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+		fst.NoError(t, err)
+		return lc
+	}
+	getValue := func(t *testing.T, c fscache.SCache, key string) string {
+		var v string
+		has, err := c.Get(ctx, key).Value(&v)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		return v
+	}
+
+	t.Run("WriteThrough", func(t *testing.T) {
+		errTier := errors.New("tier0 failed")
+		lc := newLRU()
+		sc, err := NewSChains(nil, &Cache{Cache: &errSCache{SCache: newLRU(), err: errTier}}, &Cache{Cache: lc})
+		fst.NoError(t, err)
+		ret := sc.SetTiers(ctx, "k1", "v1", time.Minute)
+		fst.Len(t, ret, 2)
+		fst.ErrorIs(t, ret[0].Err, errTier)
+		fst.NoError(t, ret[1].Err)
+		fst.ErrorIs(t, sc.Set(ctx, "k1", "v1", time.Minute).Err, errTier)
+		fst.Equal(t, "v1", getValue(t, lc, "k1"))
+
+		// 任意一级删除失败都会返回错误，其他级依然会删除
+		dr := sc.Delete(ctx, "k1")
+		fst.ErrorIs(t, dr.Err, errTier)
+		fst.Equal(t, 1, dr.Deleted)
+		fst.ErrorIs(t, lc.Get(ctx, "k1").Err, fscache.ErrNotExists)
+
+		fst.NoError(t, lc.Set(ctx, "k2", "v2", time.Minute).Err)
+		md := sc.MDelete(ctx, []any{"k2", "k3"})
+		fst.ErrorIs(t, md.Get("k2").Err, errTier)
+		fst.ErrorIs(t, md.Get("k3").Err, errTier)
+		fst.Equal(t, 1, md.Get("k2").Deleted)
+		fst.Equal(t, 0, md.Get("k3").Deleted)
+	})
+
+	t.Run("WriteAround", func(t *testing.T) {
+		lc1, lc2 := newLRU(), newLRU()
+		sc, err := NewSChains(&Option{WritePolicy: WriteAround}, &Cache{Cache: lc1}, &Cache{Cache: lc2})
+		fst.NoError(t, err)
+		fst.NoError(t, lc1.Set(ctx, "k1", "old", time.Minute).Err)
+		ret := sc.SetTiers(ctx, "k1", "v1", time.Minute)
+		fst.NoError(t, ret.Err())
+		fst.ErrorIs(t, lc1.Get(ctx, "k1").Err, fscache.ErrNotExists)
+		fst.Equal(t, "v1", getValue(t, lc2, "k1"))
+		fst.Equal(t, "v1", getValue(t, sc, "k1"))
+	})
+
+	t.Run("WriteBehind", func(t *testing.T) {
+		lc1, lc2 := newLRU(), newLRU()
+		var errs []error
+		opt := &Option{
+			WritePolicy: WriteBehind,
+			QueueSize:   1,
+			OnError: func(err error) {
+				errs = append(errs, err)
+			},
+		}
+		c, err := NewWithOption(opt, &Cache{Cache: lc1}, &Cache{Cache: lc2})
+		fst.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			fst.NoError(t, c.Set(ctx, "k1", fmt.Sprintf("v%d", i), time.Minute).Err)
+		}
+		fst.NoError(t, c.Set(ctx, "k2", "v2", time.Minute).Err)
+		fst.Equal(t, 1, c.Delete(ctx, "k2").Deleted)
+		fst.Equal(t, "v9", getValue(t, lc1, "k1"))
+
+		fst.NoError(t, c.(io.Closer).Close())
+		fst.Equal(t, "v9", getValue(t, lc2, "k1"))
+		fst.ErrorIs(t, lc2.Get(ctx, "k2").Err, fscache.ErrNotExists)
+		fst.Len(t, errs, 0)
+
+		fst.ErrorIs(t, c.Set(ctx, "k3", "v3", time.Minute).Err, ErrClosed)
+		fst.Equal(t, "v3", getValue(t, lc1, "k3"))
+		fst.NoError(t, c.(io.Closer).Close())
+	})
+
+	t.Run("WriteBehind copy", func(t *testing.T) {
+		lc1, lc2 := newLRU(), newLRU()
+		sc, err := NewSChains(&Option{WritePolicy: WriteBehind}, &Cache{Cache: lc1}, &Cache{Cache: lc2})
+		fst.NoError(t, err)
+		// 写入后，调用方修改或复用 value 和 kvs，不会影响异步写入的值
+		bf := []byte("v1")
+		fst.NoError(t, sc.Set(ctx, "k1", bf, time.Minute).Err)
+		copy(bf, "xx")
+		kvs := fscache.KVData{"k2": fscache.RawValue(`"v2"`)}
+		fst.NoError(t, sc.MSet(ctx, kvs, time.Minute).Err())
+		copy(kvs["k2"].(fscache.RawValue), `"xx"`)
+		kvs["k3"] = "v3"
+		fst.NoError(t, sc.Close())
+
+		var got []byte
+		_, err = lc2.Get(ctx, "k1").Value(&got)
+		fst.NoError(t, err)
+		fst.Equal(t, "v1", string(got))
+		fst.Equal(t, "v2", getValue(t, lc2, "k2"))
+		fst.ErrorIs(t, lc2.Get(ctx, "k3").Err, fscache.ErrNotExists)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewSChains(&Option{WritePolicy: 10}, &Cache{Cache: newLRU()})
+		fst.Error(t, err)
+		_, err = NewSChains(nil)
+		fst.Error(t, err)
+	})
+}
+
+func TestAbsent(t *testing.T) {
+	ctx := context.Background()
+	newLRU := func() fscache.SCache {
+		lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+		fst.NoError(t, err)
+		return lc
+	}
+	t0, t1 := newLRU(), &flakySCache{SCache: newLRU(), now: new(time.Time)}
+	sc, err := NewSChains(nil, &Cache{Cache: t0, AbsentTTL: time.Minute}, &Cache{Cache: t1})
+	fst.NoError(t, err)
+
+	// 第一次所有级都不存在，写入负缓存
+	ret := sc.Get(ctx, "k1")
+	fst.ErrorIs(t, ret.Err, fscache.ErrNotExists)
+	fst.False(t, fscache.IsAbsent(ret))
+	fst.Equal(t, 1, t1.calls)
+	fst.True(t, fscache.IsAbsent(t0.Get(ctx, "k1")))
+
+	// 读取到负缓存后，不再查询后面的缓存
+	ret = sc.Get(ctx, "k1")
+	fst.ErrorIs(t, ret.Err, fscache.ErrAbsent)
+	fst.True(t, fscache.IsAbsent(ret))
+	has, err := ret.Value(new(string))
+	fst.NoError(t, err)
+	fst.False(t, has)
+	fst.Equal(t, 1, t1.calls)
+	fst.False(t, sc.Has(ctx, "k1").Has)
+
+	mg := sc.MGet(ctx, []any{"k1", "k2"})
+	fst.ErrorIs(t, mg.Get("k1").Err, fscache.ErrAbsent)
+	fst.ErrorIs(t, mg.Get("k2").Err, fscache.ErrNotExists)
+	fst.False(t, fscache.IsAbsent(mg.Get("k2")))
+	fst.True(t, fscache.IsAbsent(t0.Get(ctx, "k2")))
+	fst.False(t, sc.MHas(ctx, []any{"k1", "k2"}).Get("k2").Has)
+
+	// 后面的缓存读取失败时，不写入负缓存
+	t1.err = errors.New("failed")
+	fst.Error(t, sc.Get(ctx, "k3").Err)
+	fst.ErrorIs(t, t0.Get(ctx, "k3").Err, fscache.ErrNotExists)
+	t1.err = nil
+
+	// 通过链式缓存 Set 后，负缓存被覆盖
+	fst.NoError(t, sc.Set(ctx, "k1", "v1", time.Minute).Err)
+	var got string
+	has, err = sc.Get(ctx, "k1").Value(&got)
+	fst.NoError(t, err)
+	fst.True(t, has)
+	fst.Equal(t, "v1", got)
+}
@@ -5,7 +5,11 @@
 package fscache
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
 	"encoding/json"
+	"fmt"
 )
 
 // Codec 数据编解码器
@@ -25,7 +29,24 @@ type (
 	UnmarshalFunc func(bf []byte, obj any) error
 )
 
-var DefaultCodec Codec = NewCodec(json.Marshal, json.Unmarshal)
+var (
+	// JSONCodec 使用 encoding/json 的编解码器
+	JSONCodec Codec = NewCodec(json.Marshal, json.Unmarshal)
+
+	// GobCodec 使用 encoding/gob 的编解码器
+	// 能保留 int64、[]byte 等类型信息，若数据中有 interface 类型的字段，需要先使用 gob.Register 注册具体类型
+	GobCodec Codec = NewCodec(gobMarshal, gobUnmarshal)
+
+	// MsgpackCodec 兼容 msgpack 格式的紧凑二进制编解码器
+	// 解码到 any 时，整数为 int64 或 uint64，二进制数据为 []byte，时间为 time.Time
+	MsgpackCodec Codec = NewCodec(msgpackMarshal, msgpackUnmarshal)
+
+	// PassthroughCodec 直接使用对象自身序列化方法的编解码器，不支持的类型会返回错误
+	PassthroughCodec Codec = NewPassthroughCodec(nil)
+)
+
+// DefaultCodec 默认的编解码器，为 JSONCodec
+var DefaultCodec = JSONCodec
 
 // NewCodec 创建一个新的编解码器
 func NewCodec(encode MarshalFunc, decode UnmarshalFunc) Codec {
@@ -49,3 +70,81 @@ func (c *codec) Marshal(obj any) ([]byte, error) {
 func (c *codec) Unmarshal(bf []byte, obj any) error {
 	return c.decode(bf, obj)
 }
+
+func gobMarshal(obj any) ([]byte, error) {
+	bf := &bytes.Buffer{}
+	if err := gob.NewEncoder(bf).Encode(obj); err != nil {
+		return nil, err
+	}
+	return bf.Bytes(), nil
+}
+
+func gobUnmarshal(bf []byte, obj any) error {
+	return gob.NewDecoder(bytes.NewReader(bf)).Decode(obj)
+}
+
+type (
+	// protoMarshaler 类似 protobuf(gogo) 生成的对象的序列化方法
+	protoMarshaler interface {
+		Marshal() ([]byte, error)
+	}
+
+	// protoUnmarshaler 类似 protobuf(gogo) 生成的对象的反序列化方法
+	protoUnmarshaler interface {
+		Unmarshal(bf []byte) error
+	}
+)
+
+// NewPassthroughCodec 创建一个直接使用对象自身序列化方法的编解码器
+//
+// 按照以下顺序选择序列化方法：
+// encoding.BinaryMarshaler、Marshal() ([]byte, error)、[]byte(会复制一份)、string；
+// 反序列化时对应的为：
+// encoding.BinaryUnmarshaler、Unmarshal([]byte) error、*[]byte、*string。
+// 都不支持时，使用 fallback 编解码，fallback 为 nil 时返回错误
+func NewPassthroughCodec(fallback Codec) Codec {
+	return &passthroughCodec{
+		fallback: fallback,
+	}
+}
+
+type passthroughCodec struct {
+	fallback Codec
+}
+
+func (p *passthroughCodec) Marshal(obj any) ([]byte, error) {
+	switch v := obj.(type) {
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case protoMarshaler:
+		return v.Marshal()
+	case []byte:
+		// 复制一份，避免调用方之后修改或复用底层数组时，影响编码的结果
+		return append([]byte(nil), v...), nil
+	case string:
+		return []byte(v), nil
+	}
+	if p.fallback != nil {
+		return p.fallback.Marshal(obj)
+	}
+	return nil, fmt.Errorf("passthrough codec: cannot marshal %T", obj)
+}
+
+func (p *passthroughCodec) Unmarshal(bf []byte, obj any) error {
+	switch v := obj.(type) {
+	case encoding.BinaryUnmarshaler:
+		return v.UnmarshalBinary(bf)
+	case protoUnmarshaler:
+		return v.Unmarshal(bf)
+	case *[]byte:
+		*v = append([]byte(nil), bf...)
+		return nil
+	case *string:
+		*v = string(bf)
+		return nil
+	}
+	if p.fallback != nil {
+		return p.fallback.Unmarshal(bf, obj)
+	}
+	return fmt.Errorf("passthrough codec: cannot unmarshal into %T", obj)
+}
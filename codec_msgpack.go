@@ -0,0 +1,717 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 兼容 msgpack 格式的编解码实现，支持常用的类型：
+// nil、bool、整数、浮点数、string、[]byte、time.Time(timestamp 扩展类型)、slice、array、map 和 struct。
+// struct 编码为 map，字段名可以使用 tag `msgpack:"name"` 指定，`msgpack:"-"` 表示忽略该字段。
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+func msgpackMarshal(obj any) ([]byte, error) {
+	enc := &msgpackEncoder{}
+	if err := enc.encode(reflect.ValueOf(obj)); err != nil {
+		return nil, err
+	}
+	return enc.buf, nil
+}
+
+func msgpackUnmarshal(bf []byte, obj any) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("msgpack: Unmarshal(non-pointer %T)", obj)
+	}
+	dec := &msgpackDecoder{data: bf}
+	val, err := dec.decode()
+	if err != nil {
+		return err
+	}
+	if dec.pos != len(dec.data) {
+		return fmt.Errorf("msgpack: %d bytes remaining after decode", len(dec.data)-dec.pos)
+	}
+	return msgpackAssign(rv.Elem(), val)
+}
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) writeByte(bs ...byte) {
+	e.buf = append(e.buf, bs...)
+}
+
+func (e *msgpackEncoder) writeUint(code byte, size int, v uint64) {
+	e.buf = append(e.buf, code)
+	switch size {
+	case 1:
+		e.buf = append(e.buf, byte(v))
+	case 2:
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+	case 4:
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+	}
+}
+
+func (e *msgpackEncoder) encode(rv reflect.Value) error {
+	if !rv.IsValid() {
+		e.writeByte(0xc0)
+		return nil
+	}
+	if rv.Type() == timeType {
+		e.encodeTime(rv.Interface().(time.Time))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		return e.encode(rv.Elem())
+	case reflect.Bool:
+		if rv.Bool() {
+			e.writeByte(0xc3)
+		} else {
+			e.writeByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(rv.Uint())
+	case reflect.Float32:
+		e.writeUint(0xca, 4, uint64(math.Float32bits(float32(rv.Float()))))
+	case reflect.Float64:
+		e.writeUint(0xcb, 8, math.Float64bits(rv.Float()))
+	case reflect.String:
+		e.encodeString(rv.String())
+	case reflect.Slice:
+		if rv.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			e.encodeBytes(rv.Bytes())
+			return nil
+		}
+		return e.encodeArray(rv)
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			bs := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(bs), rv)
+			e.encodeBytes(bs)
+			return nil
+		}
+		return e.encodeArray(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			e.writeByte(0xc0)
+			return nil
+		}
+		e.encodeLen(0x80, 0xde, 0xdf, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := e.encode(iter.Key()); err != nil {
+				return err
+			}
+			if err := e.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := msgpackFields(rv.Type())
+		names := make([]string, 0, len(fields))
+		values := make([]reflect.Value, 0, len(fields))
+		for _, f := range fields {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				// 字段是通过值为 nil 的匿名 struct 指针提升的，忽略
+				continue
+			}
+			names = append(names, f.name)
+			values = append(values, fv)
+		}
+		e.encodeLen(0x80, 0xde, 0xdf, len(names))
+		for i, name := range names {
+			e.encodeString(name)
+			if err := e.encode(values[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeInt(v int64) {
+	switch {
+	case v >= 0:
+		e.encodeUintAsInt(uint64(v))
+	case v >= -32:
+		e.writeByte(byte(v))
+	case v >= math.MinInt8:
+		e.writeUint(0xd0, 1, uint64(v))
+	case v >= math.MinInt16:
+		e.writeUint(0xd1, 2, uint64(v))
+	case v >= math.MinInt32:
+		e.writeUint(0xd2, 4, uint64(v))
+	default:
+		e.writeUint(0xd3, 8, uint64(v))
+	}
+}
+
+// encodeUintAsInt 使用有符号的格式编码非负整数，解码后仍为 int64
+func (e *msgpackEncoder) encodeUintAsInt(v uint64) {
+	switch {
+	case v <= 127:
+		e.writeByte(byte(v))
+	case v <= math.MaxInt16:
+		e.writeUint(0xd1, 2, v)
+	case v <= math.MaxInt32:
+		e.writeUint(0xd2, 4, v)
+	default:
+		e.writeUint(0xd3, 8, v)
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(v uint64) {
+	switch {
+	case v <= 127:
+		e.writeByte(byte(v))
+	case v <= math.MaxUint8:
+		e.writeUint(0xcc, 1, v)
+	case v <= math.MaxUint16:
+		e.writeUint(0xcd, 2, v)
+	case v <= math.MaxUint32:
+		e.writeUint(0xce, 4, v)
+	default:
+		e.writeUint(0xcf, 8, v)
+	}
+}
+
+func (e *msgpackEncoder) encodeString(s string) {
+	switch n := len(s); {
+	case n < 32:
+		e.writeByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.writeUint(0xd9, 1, uint64(n))
+	case n <= math.MaxUint16:
+		e.writeUint(0xda, 2, uint64(n))
+	default:
+		e.writeUint(0xdb, 4, uint64(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) encodeBytes(bs []byte) {
+	switch n := len(bs); {
+	case n <= math.MaxUint8:
+		e.writeUint(0xc4, 1, uint64(n))
+	case n <= math.MaxUint16:
+		e.writeUint(0xc5, 2, uint64(n))
+	default:
+		e.writeUint(0xc6, 4, uint64(n))
+	}
+	e.buf = append(e.buf, bs...)
+}
+
+// encodeLen 编码 array 或者 map 的长度
+func (e *msgpackEncoder) encodeLen(fix byte, code16 byte, code32 byte, n int) {
+	switch {
+	case n < 16:
+		e.writeByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		e.writeUint(code16, 2, uint64(n))
+	default:
+		e.writeUint(code32, 4, uint64(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeArray(rv reflect.Value) error {
+	e.encodeLen(0x90, 0xdc, 0xdd, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		if err := e.encode(rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeTime 使用 timestamp 96 格式编码时间
+func (e *msgpackEncoder) encodeTime(t time.Time) {
+	e.writeByte(0xc7, 12, 0xff)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
+
+type msgpackField struct {
+	name  string
+	index []int
+}
+
+func msgpackFields(t reflect.Type) []msgpackField {
+	fields := make([]msgpackField, 0, t.NumField())
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("msgpack")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		// 匿名的 struct 或 struct 指针字段，使用其展开后的字段
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, msgpackField{name: name, index: f.Index})
+	}
+	return fields
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errMsgpackShort
+	}
+	bs := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return bs, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	bs, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(bs[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bs)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bs)), nil
+	default:
+		return binary.BigEndian.Uint64(bs), nil
+	}
+}
+
+// decode 解码下一个值，返回值的类型为：
+// nil、bool、int64、uint64、float32、float64、string、[]byte、time.Time、[]any、map[string]any 或 map[any]any
+func (d *msgpackDecoder) decode() (any, error) {
+	bs, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	code := bs[0]
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xe0 == 0xa0:
+		return d.decodeString(int(code & 0x1f))
+	case code&0xf0 == 0x90:
+		return d.decodeArray(int(code & 0x0f))
+	case code&0xf0 == 0x80:
+		return d.decodeMap(int(code & 0x0f))
+	}
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		bs, err := d.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append(make([]byte, 0, len(bs)), bs...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readUint(1 << (code - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(int(n))
+	case 0xca:
+		v, err := d.readUint(4)
+		return math.Float32frombits(uint32(v)), err
+	case 0xcb:
+		v, err := d.readUint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.readUint(1 << (code - 0xcc))
+	case 0xd0:
+		v, err := d.readUint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := d.readUint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := d.readUint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := d.readUint(8)
+		return int64(v), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (code - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+	return nil, fmt.Errorf("msgpack: invalid code 0x%x", code)
+}
+
+func (d *msgpackDecoder) decodeString(n int) (any, error) {
+	bs, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(bs), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackShort
+	}
+	arr := make([]any, n)
+	for i := 0; i < n; i++ {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackShort
+	}
+	keys := make([]any, n)
+	values := make([]any, n)
+	allString := true
+	for i := 0; i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.(string); !ok {
+			allString = false
+		}
+		keys[i], values[i] = k, v
+	}
+	if allString {
+		mp := make(map[string]any, n)
+		for i, k := range keys {
+			mp[k.(string)] = values[i]
+		}
+		return mp, nil
+	}
+	mp := make(map[any]any, n)
+	for i, k := range keys {
+		if k != nil && !reflect.TypeOf(k).Comparable() {
+			return nil, fmt.Errorf("msgpack: invalid map key type %T", k)
+		}
+		mp[k] = values[i]
+	}
+	return mp, nil
+}
+
+func (d *msgpackDecoder) decodeExt(n int) (any, error) {
+	tp, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(tp[0]) != -1 {
+		return nil, fmt.Errorf("msgpack: unsupported ext type %d", int8(tp[0]))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(bs)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(bs)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(bs[:4])
+		sec := binary.BigEndian.Uint64(bs[4:])
+		return time.Unix(int64(sec), int64(nsec)), nil
+	}
+	return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+}
+
+// msgpackAssign 将解码后的值赋值给 rv
+func msgpackAssign(rv reflect.Value, val any) error {
+	if val == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		vv := reflect.ValueOf(val)
+		if !vv.Type().AssignableTo(rv.Type()) {
+			return fmt.Errorf("msgpack: cannot assign %T to %s", val, rv.Type())
+		}
+		rv.Set(vv)
+		return nil
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return msgpackAssign(rv.Elem(), val)
+	}
+
+	if rv.Type() == timeType {
+		t, ok := val.(time.Time)
+		if !ok {
+			return fmt.Errorf("msgpack: cannot assign %T to time.Time", val)
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, ok := val.(bool)
+		if !ok {
+			return fmt.Errorf("msgpack: cannot assign %T to bool", val)
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := val.(type) {
+		case int64:
+			n = v
+		case uint64:
+			if v > math.MaxInt64 {
+				return fmt.Errorf("msgpack: %d overflows %s", v, rv.Type())
+			}
+			n = int64(v)
+		default:
+			return fmt.Errorf("msgpack: cannot assign %T to %s", val, rv.Type())
+		}
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("msgpack: %d overflows %s", n, rv.Type())
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch v := val.(type) {
+		case uint64:
+			n = v
+		case int64:
+			if v < 0 {
+				return fmt.Errorf("msgpack: %d overflows %s", v, rv.Type())
+			}
+			n = uint64(v)
+		default:
+			return fmt.Errorf("msgpack: cannot assign %T to %s", val, rv.Type())
+		}
+		if rv.OverflowUint(n) {
+			return fmt.Errorf("msgpack: %d overflows %s", n, rv.Type())
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch v := val.(type) {
+		case float64:
+			rv.SetFloat(v)
+		case float32:
+			rv.SetFloat(float64(v))
+		case int64:
+			rv.SetFloat(float64(v))
+		case uint64:
+			rv.SetFloat(float64(v))
+		default:
+			return fmt.Errorf("msgpack: cannot assign %T to %s", val, rv.Type())
+		}
+	case reflect.String:
+		switch v := val.(type) {
+		case string:
+			rv.SetString(v)
+		case []byte:
+			rv.SetString(string(v))
+		default:
+			return fmt.Errorf("msgpack: cannot assign %T to string", val)
+		}
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			switch v := val.(type) {
+			case []byte:
+				rv.SetBytes(v)
+				return nil
+			case string:
+				rv.SetBytes([]byte(v))
+				return nil
+			}
+		}
+		arr, ok := val.([]any)
+		if !ok {
+			return fmt.Errorf("msgpack: cannot assign %T to %s", val, rv.Type())
+		}
+		sl := reflect.MakeSlice(rv.Type(), len(arr), len(arr))
+		for i, item := range arr {
+			if err := msgpackAssign(sl.Index(i), item); err != nil {
+				return err
+			}
+		}
+		rv.Set(sl)
+	case reflect.Array:
+		if bs, ok := val.([]byte); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(rv, reflect.ValueOf(bs))
+			return nil
+		}
+		arr, ok := val.([]any)
+		if !ok {
+			return fmt.Errorf("msgpack: cannot assign %T to %s", val, rv.Type())
+		}
+		for i := 0; i < rv.Len() && i < len(arr); i++ {
+			if err := msgpackAssign(rv.Index(i), arr[i]); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return msgpackAssignMap(rv, val)
+	case reflect.Struct:
+		return msgpackAssignStruct(rv, val)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+func msgpackRange(val any, fn func(k any, v any) error) error {
+	switch mp := val.(type) {
+	case map[string]any:
+		for k, v := range mp {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		for k, v := range mp {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: cannot assign %T to map or struct", val)
+	}
+	return nil
+}
+
+func msgpackAssignMap(rv reflect.Value, val any) error {
+	tp := rv.Type()
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(tp))
+	}
+	return msgpackRange(val, func(k any, v any) error {
+		kv := reflect.New(tp.Key()).Elem()
+		if err := msgpackAssign(kv, k); err != nil {
+			return err
+		}
+		vv := reflect.New(tp.Elem()).Elem()
+		if err := msgpackAssign(vv, v); err != nil {
+			return err
+		}
+		rv.SetMapIndex(kv, vv)
+		return nil
+	})
+}
+
+func msgpackAssignStruct(rv reflect.Value, val any) error {
+	fields := msgpackFields(rv.Type())
+	return msgpackRange(val, func(k any, v any) error {
+		name, ok := k.(string)
+		if !ok {
+			return nil
+		}
+		for _, f := range fields {
+			if f.name != name {
+				continue
+			}
+			fv, ok := msgpackFieldByIndex(rv, f.index)
+			if !ok {
+				return nil
+			}
+			return msgpackAssign(fv, v)
+		}
+		return nil
+	})
+}
+
+// msgpackFieldByIndex 和 reflect.Value.FieldByIndex 类似，
+// 路径上值为 nil 的匿名 struct 指针会被初始化，无法初始化(未导出)时返回 false
+func msgpackFieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
@@ -0,0 +1,265 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+)
+
+type codecTestUser struct {
+	ID       int64
+	Name     string `msgpack:"name"`
+	Avatar   []byte
+	Tags     []string
+	Extra    map[string]any
+	Birthday time.Time
+	Skip     string `msgpack:"-"`
+	Ptr      *int
+}
+
+func TestMsgpackCodec(t *testing.T) {
+	now := time.Unix(1700000000, 123456789)
+	num := 7
+	u := codecTestUser{
+		ID:       1 << 40,
+		Name:     strings.Repeat("n", 40),
+		Avatar:   []byte{0, 1, 2},
+		Tags:     []string{"a", "b"},
+		Extra:    map[string]any{"at": now, "n": int64(-100000), "u": uint8(200), "f": 1.5, "nil": nil},
+		Birthday: now,
+		Skip:     "skip",
+		Ptr:      &num,
+	}
+	bf, err := MsgpackCodec.Marshal(u)
+	fst.NoError(t, err)
+
+	var got codecTestUser
+	fst.NoError(t, MsgpackCodec.Unmarshal(bf, &got))
+	fst.Equal(t, u.ID, got.ID)
+	fst.Equal(t, u.Name, got.Name)
+	fst.Equal(t, u.Avatar, got.Avatar)
+	fst.Equal(t, u.Tags, got.Tags)
+	fst.Equal(t, "", got.Skip)
+	fst.Equal(t, 7, *got.Ptr)
+	fst.True(t, u.Birthday.Equal(got.Birthday))
+	fst.True(t, now.Equal(got.Extra["at"].(time.Time)))
+	fst.Equal[any](t, int64(-100000), got.Extra["n"])
+	fst.Equal[any](t, uint64(200), got.Extra["u"])
+	fst.Equal[any](t, 1.5, got.Extra["f"])
+	fst.Nil(t, got.Extra["nil"])
+
+	t.Run("any", func(t *testing.T) {
+		bf, err := MsgpackCodec.Marshal(map[int]any{1: []byte("hello"), 2: int64(3)})
+		fst.NoError(t, err)
+		var val any
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &val))
+		mp := val.(map[any]any)
+		fst.Equal[any](t, []byte("hello"), mp[int64(1)])
+		fst.Equal[any](t, int64(3), mp[int64(2)])
+	})
+
+	t.Run("large", func(t *testing.T) {
+		in := make([]int, 70000)
+		for i := range in {
+			in[i] = i * 1000
+		}
+		bf, err := MsgpackCodec.Marshal(in)
+		fst.NoError(t, err)
+		var out []int
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &out))
+		fst.Equal(t, in, out)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var n int8
+		bf, _ := MsgpackCodec.Marshal(1000)
+		fst.Error(t, MsgpackCodec.Unmarshal(bf, &n))
+		fst.Error(t, MsgpackCodec.Unmarshal(bf[:1], &n))
+		fst.Error(t, MsgpackCodec.Unmarshal(bf, n))
+		_, err := MsgpackCodec.Marshal(make(chan int))
+		fst.Error(t, err)
+	})
+}
+
+type codecTestBase struct {
+	BaseID int64
+}
+
+type codecTestEmbed struct {
+	*codecTestBase
+	*CodecTestExported
+	Name string
+}
+
+type CodecTestExported struct {
+	Level int
+}
+
+func TestMsgpackCodec_embedPointer(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		bf, err := MsgpackCodec.Marshal(codecTestEmbed{Name: "a"})
+		fst.NoError(t, err)
+		var got map[string]any
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &got))
+		fst.Equal(t, map[string]any{"Name": "a"}, got)
+
+		var out codecTestEmbed
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &out))
+		fst.Equal(t, "a", out.Name)
+		fst.Nil(t, out.CodecTestExported)
+	})
+
+	t.Run("not nil", func(t *testing.T) {
+		in := codecTestEmbed{
+			codecTestBase:     &codecTestBase{BaseID: 1},
+			CodecTestExported: &CodecTestExported{Level: 2},
+			Name:              "a",
+		}
+		bf, err := MsgpackCodec.Marshal(in)
+		fst.NoError(t, err)
+		var got map[string]any
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &got))
+		fst.Equal(t, map[string]any{"Name": "a", "BaseID": int64(1), "Level": int64(2)}, got)
+
+		// 未导出的匿名指针无法初始化，其字段被忽略
+		var out codecTestEmbed
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &out))
+		fst.Nil(t, out.codecTestBase)
+		fst.Equal(t, 2, out.Level)
+		fst.Equal(t, "a", out.Name)
+	})
+}
+
+func TestMsgpackCodec_roundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 123456789)
+	cases := []any{
+		nil,
+		true,
+		int64(-1),
+		int64(-32),
+		int64(-33),
+		int64(math.MinInt64),
+		uint64(math.MaxUint64),
+		int64(127),
+		int64(128),
+		int64(1 << 20),
+		1.5,
+		"",
+		strings.Repeat("s", 300),
+		strings.Repeat("s", 70000),
+		[]byte{},
+		[]byte(strings.Repeat("b", 300)),
+		now,
+		time.Unix(1, 0),
+		time.Unix(1<<35, 1),
+		[]any{int64(1), "a", nil},
+		map[string]any{"a": int64(1), "b": []any{"c"}},
+		map[any]any{int64(1): "a", true: nil},
+	}
+	for _, in := range cases {
+		bf, err := MsgpackCodec.Marshal(in)
+		fst.NoError(t, err)
+		var out any
+		fst.NoError(t, MsgpackCodec.Unmarshal(bf, &out))
+		if tm, ok := in.(time.Time); ok {
+			fst.True(t, tm.Equal(out.(time.Time)))
+			continue
+		}
+		fst.Equal(t, in, out)
+	}
+}
+
+func FuzzMsgpackUnmarshal(f *testing.F) {
+	seeds := []any{
+		nil, int64(-1), uint64(1 << 40), 1.5, "hello", []byte("hello"), time.Unix(1700000000, 1),
+		[]any{int64(1), "a"}, map[string]any{"a": []any{nil}}, map[any]any{int64(1): true},
+		codecTestUser{ID: 1, Name: "n", Tags: []string{"a"}},
+	}
+	for _, v := range seeds {
+		bf, err := MsgpackCodec.Marshal(v)
+		fst.NoError(f, err)
+		f.Add(bf)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var user codecTestUser
+		_ = MsgpackCodec.Unmarshal(data, &user)
+		var embed codecTestEmbed
+		_ = MsgpackCodec.Unmarshal(data, &embed)
+
+		var val any
+		if MsgpackCodec.Unmarshal(data, &val) != nil {
+			return
+		}
+		bf, err := MsgpackCodec.Marshal(val)
+		if err != nil {
+			t.Fatalf("re-marshal %#v: %v", val, err)
+		}
+		var val2 any
+		if err = MsgpackCodec.Unmarshal(bf, &val2); err != nil {
+			t.Fatalf("unmarshal re-marshaled data: %v", err)
+		}
+	})
+}
+
+func TestGobCodec(t *testing.T) {
+	in := map[string]int64{"a": 1 << 50}
+	bf, err := GobCodec.Marshal(in)
+	fst.NoError(t, err)
+	var out map[string]int64
+	fst.NoError(t, GobCodec.Unmarshal(bf, &out))
+	fst.Equal(t, in, out)
+}
+
+type codecTestProto struct {
+	val string
+}
+
+func (p *codecTestProto) Marshal() ([]byte, error) {
+	return []byte(p.val), nil
+}
+
+func (p *codecTestProto) Unmarshal(bf []byte) error {
+	p.val = string(bf)
+	return nil
+}
+
+func TestPassthroughCodec(t *testing.T) {
+	bf, err := PassthroughCodec.Marshal(&codecTestProto{val: "hello"})
+	fst.NoError(t, err)
+	got := &codecTestProto{}
+	fst.NoError(t, PassthroughCodec.Unmarshal(bf, got))
+	fst.Equal(t, "hello", got.val)
+
+	now := time.Now()
+	bf, err = PassthroughCodec.Marshal(now)
+	fst.NoError(t, err)
+	var t2 time.Time
+	fst.NoError(t, PassthroughCodec.Unmarshal(bf, &t2))
+	fst.True(t, now.Equal(t2))
+
+	var bs []byte
+	raw := []byte("abc")
+	bf, err = PassthroughCodec.Marshal(raw)
+	fst.NoError(t, err)
+	raw[0] = 'x'
+	fst.NoError(t, PassthroughCodec.Unmarshal(bf, &bs))
+	fst.Equal(t, []byte("abc"), bs)
+
+	_, err = PassthroughCodec.Marshal(1)
+	fst.Error(t, err)
+	var n int
+	fst.Error(t, PassthroughCodec.Unmarshal(bf, &n))
+
+	withJSON := NewPassthroughCodec(JSONCodec)
+	bf, err = withJSON.Marshal(1)
+	fst.NoError(t, err)
+	fst.NoError(t, withJSON.Unmarshal(bf, &n))
+	fst.Equal(t, 1, n)
+}
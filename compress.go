@@ -0,0 +1,254 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Compressor 数据压缩算法
+type Compressor interface {
+	// ID 压缩算法的唯一标识，会写入到数据头部，用于解码时选择对应的算法
+	// 1-15 为内置算法保留，自定义算法请使用其他值
+	ID() byte
+
+	// Compress 压缩数据
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress 解压数据
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	// FlateCompressor 使用标准库 compress/flate 的压缩算法
+	FlateCompressor Compressor = &stdCompressor{
+		id: 1,
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	}
+
+	// GzipCompressor 使用标准库 compress/gzip 的压缩算法
+	GzipCompressor Compressor = &stdCompressor{
+		id: 2,
+		newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	}
+)
+
+type stdCompressor struct {
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+	newReader func(r io.Reader) (io.ReadCloser, error)
+	id        byte
+}
+
+func (s *stdCompressor) ID() byte {
+	return s.id
+}
+
+func (s *stdCompressor) Compress(data []byte) ([]byte, error) {
+	bf := &bytes.Buffer{}
+	w, err := s.newWriter(bf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return bf.Bytes(), nil
+}
+
+func (s *stdCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := s.newReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+const (
+	// compressFlagRaw 数据未压缩
+	compressFlagRaw byte = 0xFD
+
+	// compressFlagCompressed 数据已压缩，下一个字节为压缩算法的 ID
+	compressFlagCompressed byte = 0xFC
+)
+
+// CompressCodec 对编码后的数据进行压缩的编解码器
+//
+// 编码后的数据第一个字节为标记位，用于区分是否压缩。
+// 没有标记位的数据(如在启用压缩前写入的数据)会直接使用 Codec 解码。
+// 只有 Codec 编码的数据第一个字节不会是 0xFC、0xFD 时(如 JSONCodec)，新旧数据才可以混合存储；
+// MsgpackCodec(负数 fixint)、GobCodec 编码的数据可能以这些字节开头，启用压缩前应清空旧数据。
+type CompressCodec struct {
+	// Codec 实际的编解码器，可选，默认为 DefaultCodec
+	Codec Codec
+
+	// Compressor 编码时使用的压缩算法，可选，默认为 GzipCompressor
+	Compressor Compressor
+
+	// Decompressors 解码时额外支持的压缩算法，可选
+	// Compressor、GzipCompressor 和 FlateCompressor 总是支持的
+	Decompressors []Compressor
+
+	// Threshold 编码后的数据长度超过此值才会压缩，可选
+	// 为 0 时使用默认值 1024，小于 0 时总是压缩
+	Threshold int
+
+	marshalCount    atomic.Int64
+	compressedCount atomic.Int64
+	rawBytes        atomic.Int64
+	compressedBytes atomic.Int64
+}
+
+// CompressStats 压缩的统计信息
+type CompressStats struct {
+	// MarshalCount 编码的总次数
+	MarshalCount int64
+
+	// CompressedCount 压缩的次数
+	CompressedCount int64
+
+	// RawBytes 被压缩的数据压缩前的总长度
+	RawBytes int64
+
+	// CompressedBytes 被压缩的数据压缩后的总长度
+	CompressedBytes int64
+}
+
+// Ratio 压缩率，压缩后的长度/压缩前的长度，没有压缩过数据时返回 1
+func (cs CompressStats) Ratio() float64 {
+	if cs.RawBytes == 0 {
+		return 1
+	}
+	return float64(cs.CompressedBytes) / float64(cs.RawBytes)
+}
+
+func (cs CompressStats) String() string {
+	return fmt.Sprintf("marshal=%d compressed=%d raw_bytes=%d compressed_bytes=%d ratio=%.3f",
+		cs.MarshalCount, cs.CompressedCount, cs.RawBytes, cs.CompressedBytes, cs.Ratio())
+}
+
+func (cc *CompressCodec) getCodec() Codec {
+	if cc.Codec == nil {
+		return DefaultCodec
+	}
+	return cc.Codec
+}
+
+func (cc *CompressCodec) getCompressor() Compressor {
+	if cc.Compressor == nil {
+		return GzipCompressor
+	}
+	return cc.Compressor
+}
+
+func (cc *CompressCodec) getThreshold() int {
+	if cc.Threshold == 0 {
+		return 1024
+	}
+	return cc.Threshold
+}
+
+func (cc *CompressCodec) findCompressor(id byte) Compressor {
+	if c := cc.getCompressor(); c.ID() == id {
+		return c
+	}
+	for _, c := range cc.Decompressors {
+		if c.ID() == id {
+			return c
+		}
+	}
+	for _, c := range []Compressor{GzipCompressor, FlateCompressor} {
+		if c.ID() == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Marshal 编码，并在数据长度超过 Threshold 时压缩
+func (cc *CompressCodec) Marshal(obj any) ([]byte, error) {
+	bf, err := cc.getCodec().Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	cc.marshalCount.Add(1)
+	if len(bf) > cc.getThreshold() {
+		c := cc.getCompressor()
+		compressed, err := c.Compress(bf)
+		if err != nil {
+			return nil, err
+		}
+		// 压缩后没有变小的，直接存储原始数据
+		if len(compressed) < len(bf) {
+			cc.compressedCount.Add(1)
+			cc.rawBytes.Add(int64(len(bf)))
+			cc.compressedBytes.Add(int64(len(compressed)))
+
+			out := make([]byte, 0, len(compressed)+2)
+			out = append(out, compressFlagCompressed, c.ID())
+			return append(out, compressed...), nil
+		}
+	}
+	out := make([]byte, 0, len(bf)+1)
+	out = append(out, compressFlagRaw)
+	return append(out, bf...), nil
+}
+
+// Unmarshal 解码，支持压缩和未压缩的数据
+func (cc *CompressCodec) Unmarshal(bf []byte, obj any) error {
+	if len(bf) == 0 {
+		return cc.getCodec().Unmarshal(bf, obj)
+	}
+	switch bf[0] {
+	case compressFlagRaw:
+		return cc.getCodec().Unmarshal(bf[1:], obj)
+	case compressFlagCompressed:
+		if len(bf) < 2 {
+			return fmt.Errorf("invalid compressed data, length=%d", len(bf))
+		}
+		c := cc.findCompressor(bf[1])
+		if c == nil {
+			return fmt.Errorf("compressor id=%d not found", bf[1])
+		}
+		raw, err := c.Decompress(bf[2:])
+		if err != nil {
+			return err
+		}
+		return cc.getCodec().Unmarshal(raw, obj)
+	default:
+		// 没有标记位的旧数据
+		return cc.getCodec().Unmarshal(bf, obj)
+	}
+}
+
+// Stats 获取压缩的统计信息
+func (cc *CompressCodec) Stats() CompressStats {
+	return CompressStats{
+		MarshalCount:    cc.marshalCount.Load(),
+		CompressedCount: cc.compressedCount.Load(),
+		RawBytes:        cc.rawBytes.Load(),
+		CompressedBytes: cc.compressedBytes.Load(),
+	}
+}
+
+var _ Codec = (*CompressCodec)(nil)
@@ -0,0 +1,66 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fsgo/fst"
+)
+
+func TestCompressCodec(t *testing.T) {
+	long := strings.Repeat("hello fscache ", 200)
+	for _, c := range []Compressor{GzipCompressor, FlateCompressor} {
+		cc := &CompressCodec{Compressor: c}
+
+		bf, err := cc.Marshal(long)
+		fst.NoError(t, err)
+		fst.Equal(t, compressFlagCompressed, bf[0])
+		fst.Equal(t, c.ID(), bf[1])
+		fst.Less(t, len(bf), len(long))
+
+		var got string
+		fst.NoError(t, cc.Unmarshal(bf, &got))
+		fst.Equal(t, long, got)
+
+		stats := cc.Stats()
+		fst.Equal(t, int64(1), stats.CompressedCount)
+		fst.Less(t, stats.Ratio(), 0.5)
+	}
+
+	t.Run("small value", func(t *testing.T) {
+		cc := &CompressCodec{}
+		bf, err := cc.Marshal("hello")
+		fst.NoError(t, err)
+		fst.Equal(t, compressFlagRaw, bf[0])
+		var got string
+		fst.NoError(t, cc.Unmarshal(bf, &got))
+		fst.Equal(t, "hello", got)
+		fst.Equal(t, float64(1), cc.Stats().Ratio())
+	})
+
+	t.Run("legacy data", func(t *testing.T) {
+		cc := &CompressCodec{}
+		bf, err := DefaultCodec.Marshal(map[string]int{"a": 1})
+		fst.NoError(t, err)
+		var got map[string]int
+		fst.NoError(t, cc.Unmarshal(bf, &got))
+		fst.Equal(t, map[string]int{"a": 1}, got)
+	})
+
+	t.Run("decoded by other codec", func(t *testing.T) {
+		bf, err := (&CompressCodec{Compressor: FlateCompressor}).Marshal(long)
+		fst.NoError(t, err)
+		var got string
+		fst.NoError(t, (&CompressCodec{}).Unmarshal(bf, &got))
+		fst.Equal(t, long, got)
+	})
+
+	t.Run("unknown compressor", func(t *testing.T) {
+		var got string
+		fst.Error(t, (&CompressCodec{}).Unmarshal([]byte{compressFlagCompressed, 100, 1}, &got))
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ErrVersionMismatch 缓存数据的版本号或者编解码器和当前不一致，并且没有对应的迁移方法
+// 会被当做缓存不存在处理：errors.Is(ErrVersionMismatch, ErrNotExists) 为 true
+var ErrVersionMismatch = fmt.Errorf("%w: version mismatch", ErrNotExists)
+
+// MigrateFunc 将旧版本的数据解码到 obj 中
+//
+// 参数 codec 为旧数据的编解码器，若旧数据的编解码器无法识别，为 nil
+type MigrateFunc func(payload []byte, codec Codec, obj any) error
+
+// envelopeMagic 数据头部的标记位
+const envelopeMagic byte = 0xFE
+
+// EnvelopeCodec 在数据头部记录数据结构版本号和编解码器 ID 的编解码器
+//
+// 数据格式为：1 字节标记位 + 4 字节版本号 + 1 字节编解码器 ID 长度 + 编解码器 ID + 编码后的数据。
+// 没有头部的旧数据，会被当做版本号为 0，使用 Codec 编码的数据。
+// 只有 Codec 编码的数据第一个字节不会是 0xFE 时(如 JSONCodec)，才能可靠地识别旧数据；
+// MsgpackCodec(负数 fixint)、GobCodec 编码的数据可能以 0xFE 开头，启用前应清空旧数据。
+// 解码时，若版本号或者编解码器 ID 和当前的不一致，会使用 Migrations 中对应的方法迁移，
+// 若没有对应的方法，返回 ErrVersionMismatch，GetResult.Value 会返回不存在
+type EnvelopeCodec struct {
+	// Codec 实际的编解码器，可选，默认为 DefaultCodec
+	Codec Codec
+
+	// CodecID 编解码器的 ID，可选，长度不能超过 255
+	CodecID string
+
+	// Version 当前的数据结构版本号，可选
+	// 当缓存的数据结构不兼容时，应修改此值
+	Version uint32
+
+	// Migrations 旧版本数据的迁移方法，可选，key 为旧数据的版本号
+	Migrations map[uint32]MigrateFunc
+
+	// Codecs 其他编解码器，可选，key 为编解码器 ID
+	// 用于解码 CodecID 和当前不一致的数据
+	Codecs map[string]Codec
+}
+
+func (ec *EnvelopeCodec) getCodec() Codec {
+	if ec.Codec == nil {
+		return DefaultCodec
+	}
+	return ec.Codec
+}
+
+// Marshal 编码，并在头部添加版本号和编解码器 ID
+func (ec *EnvelopeCodec) Marshal(obj any) ([]byte, error) {
+	if len(ec.CodecID) > 255 {
+		return nil, fmt.Errorf("CodecID too long, len=%d", len(ec.CodecID))
+	}
+	bf, err := ec.getCodec().Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(bf)+6+len(ec.CodecID))
+	out = append(out, envelopeMagic)
+	out = binary.BigEndian.AppendUint32(out, ec.Version)
+	out = append(out, byte(len(ec.CodecID)))
+	out = append(out, ec.CodecID...)
+	return append(out, bf...), nil
+}
+
+// Unmarshal 解码，版本号不一致时，会尝试迁移
+func (ec *EnvelopeCodec) Unmarshal(bf []byte, obj any) error {
+	version, codecID, payload, err := ec.parse(bf)
+	if err != nil {
+		return err
+	}
+	codec := ec.findCodec(codecID)
+	if version == ec.Version && codecID == ec.CodecID {
+		return codec.Unmarshal(payload, obj)
+	}
+	if fn, ok := ec.Migrations[version]; ok {
+		return fn(payload, codec, obj)
+	}
+	if version == ec.Version && codec != nil {
+		return codec.Unmarshal(payload, obj)
+	}
+	return ErrVersionMismatch
+}
+
+func (ec *EnvelopeCodec) parse(bf []byte) (version uint32, codecID string, payload []byte, err error) {
+	if len(bf) == 0 || bf[0] != envelopeMagic {
+		// 没有头部的旧数据
+		return 0, ec.CodecID, bf, nil
+	}
+	if len(bf) < 6 {
+		return 0, "", nil, fmt.Errorf("invalid envelope data, length=%d", len(bf))
+	}
+	version = binary.BigEndian.Uint32(bf[1:5])
+	idLen := int(bf[5])
+	if len(bf) < 6+idLen {
+		return 0, "", nil, fmt.Errorf("invalid envelope data, length=%d", len(bf))
+	}
+	return version, string(bf[6 : 6+idLen]), bf[6+idLen:], nil
+}
+
+func (ec *EnvelopeCodec) findCodec(codecID string) Codec {
+	if codecID == ec.CodecID {
+		return ec.getCodec()
+	}
+	return ec.Codecs[codecID]
+}
+
+var _ Codec = (*EnvelopeCodec)(nil)
@@ -0,0 +1,115 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+)
+
+func TestEnvelopeCodec(t *testing.T) {
+	type userV1 struct {
+		Name string
+	}
+	type userV2 struct {
+		FirstName string
+		LastName  string
+	}
+
+	v1 := &EnvelopeCodec{Version: 1, CodecID: "json"}
+	bf, err := v1.Marshal(userV1{Name: "Ada Lovelace"})
+	fst.NoError(t, err)
+
+	var u1 userV1
+	fst.NoError(t, v1.Unmarshal(bf, &u1))
+	fst.Equal(t, "Ada Lovelace", u1.Name)
+
+	t.Run("mismatch as miss", func(t *testing.T) {
+		v2 := &EnvelopeCodec{Version: 2, CodecID: "json"}
+		var u2 userV2
+		err := v2.Unmarshal(bf, &u2)
+		fst.True(t, errors.Is(err, ErrNotExists))
+
+		ret := GetResult{Payload: bf, UnmarshalFunc: v2.Unmarshal}
+		has, err := ret.Value(&u2)
+		fst.NoError(t, err)
+		fst.False(t, has)
+
+		ps := &ProS[string, userV2]{SCache: &staticSCache{ret: ret}}
+		_, err = ps.Get(context.Background(), "key")
+		fst.Equal(t, ErrNotExists, err)
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		v2 := &EnvelopeCodec{
+			Version: 2,
+			CodecID: "json",
+			Migrations: map[uint32]MigrateFunc{
+				1: func(payload []byte, codec Codec, obj any) error {
+					var old userV1
+					if err := codec.Unmarshal(payload, &old); err != nil {
+						return err
+					}
+					u := obj.(*userV2)
+					u.FirstName = old.Name[:3]
+					u.LastName = old.Name[4:]
+					return nil
+				},
+			},
+		}
+		var u2 userV2
+		fst.NoError(t, v2.Unmarshal(bf, &u2))
+		fst.Equal(t, userV2{FirstName: "Ada", LastName: "Lovelace"}, u2)
+	})
+
+	t.Run("other codec", func(t *testing.T) {
+		mp := &EnvelopeCodec{Version: 1, CodecID: "msgpack", Codec: MsgpackCodec}
+		var u userV1
+		fst.True(t, errors.Is(mp.Unmarshal(bf, &u), ErrVersionMismatch))
+
+		mp.Codecs = map[string]Codec{"json": JSONCodec}
+		fst.NoError(t, mp.Unmarshal(bf, &u))
+		fst.Equal(t, "Ada Lovelace", u.Name)
+	})
+
+	t.Run("legacy data", func(t *testing.T) {
+		legacy, err := JSONCodec.Marshal(userV1{Name: "legacy"})
+		fst.NoError(t, err)
+		var u userV1
+		fst.NoError(t, (&EnvelopeCodec{}).Unmarshal(legacy, &u))
+		fst.Equal(t, "legacy", u.Name)
+		fst.True(t, errors.Is(v1.Unmarshal(legacy, &u), ErrNotExists))
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		var u userV1
+		fst.Error(t, v1.Unmarshal([]byte{envelopeMagic, 0, 0}, &u))
+	})
+}
+
+// staticSCache 总是返回固定的 Get 结果
+type staticSCache struct {
+	ret GetResult
+}
+
+func (s *staticSCache) Get(ctx context.Context, key any) GetResult {
+	return s.ret
+}
+
+func (s *staticSCache) Set(ctx context.Context, key any, value any, ttl time.Duration) SetResult {
+	return setRetSuc
+}
+
+func (s *staticSCache) Has(ctx context.Context, key any) HasResult {
+	return HasResult{Has: s.ret.Err == nil}
+}
+
+func (s *staticSCache) Delete(ctx context.Context, key any) DeleteResult {
+	return deleteRetSucHas0
+}
@@ -0,0 +1,173 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"log"
+	"path"
+	"time"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/filecache/cachefs"
+)
+
+const lockStripes = 64
+
+// lock 对缓存文件 fp 所在的目录加锁
+// 进程内使用 SCache.locks，若 FS 实现了 cachefs.Locker，还会使用其加跨进程的锁
+func (f *SCache) lock(fp string) (func(), error) {
+	dir := path.Dir(fp)
+	mu := &f.locks[crc32.ChecksumIEEE([]byte(dir))%lockStripes]
+	mu.Lock()
+	lk, ok := f.fs.(cachefs.Locker)
+	if !ok {
+		return mu.Unlock, nil
+	}
+	if !fileExists(f.fs, dir) {
+		if err := f.fs.MkdirAll(dir, 0755); err != nil {
+			mu.Unlock()
+			return nil, err
+		}
+	}
+	unlock, err := lk.Lock(dir)
+	if err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		if err := unlock(); err != nil {
+			log.Printf("[fileCache][warn] unlock %q failed, %s\n", dir, err.Error())
+		}
+		mu.Unlock()
+	}, nil
+}
+
+// nextCAS 生成新的版本号，不为 0
+// 初始值为随机数，使用相同目录的多个进程生成的版本号几乎不会重复
+func (f *SCache) nextCAS() uint64 {
+	for {
+		if cas := f.cas.Add(1); cas != 0 {
+			return cas
+		}
+	}
+}
+
+func randUint64() uint64 {
+	var bf [8]byte
+	_, _ = rand.Read(bf[:])
+	return binary.BigEndian.Uint64(bf[:])
+}
+
+// current 读取未过期的缓存文件，需要在加锁后调用，不存在、已过期或者已损坏时返回 nil
+func (f *SCache) current(fp string) (*cacheFile, error) {
+	cf, err := f.readFile(fp)
+	if err != nil {
+		if errors.Is(err, ErrCorrupted) {
+			return nil, nil
+		}
+		if errors.Is(err, fscache.ErrNotExists) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if cf.ExpireAt < timeNow().UnixNano() {
+		return nil, nil
+	}
+	return cf, nil
+}
+
+// atomic 在加锁后，使用当前的缓存文件调用 fn，fn 返回的缓存文件不为 nil 时写入
+func (f *SCache) atomic(ctx context.Context, key any, fn func(cur *cacheFile) (*cacheFile, error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer f.autoGC()
+
+	fp := f.opt.CacheName(key)
+	unlock, err := f.lock(fp)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cur, err := f.current(fp)
+	if err != nil {
+		return err
+	}
+	cf, err := fn(cur)
+	if err != nil || cf == nil {
+		return err
+	}
+	return f.writeFile(fp, cf)
+}
+
+// Incr 将 key 的整数值加上 delta，缓存值使用 Option.Codec 编解码
+//
+// 原子操作之间通过文件锁互斥，包括其他进程中使用相同目录的缓存；
+// 和 Set 等非原子操作并发时，以最后写入的为准
+func (f *SCache) Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error) {
+	num := delta
+	err := f.atomic(ctx, key, func(cur *cacheFile) (*cacheFile, error) {
+		if cur == nil {
+			msg, err := f.encode(delta)
+			if err != nil {
+				return nil, err
+			}
+			return f.newCacheFile(key, ttl, msg), nil
+		}
+		nv, n, err := fscache.AddInt(fscache.RawValue(cur.Payload), delta, f.opt.GetCodec())
+		if err != nil {
+			return nil, err
+		}
+		num = n
+		cf := f.newCacheFile(key, 0, nv.(fscache.RawValue))
+		cf.ExpireAt = cur.ExpireAt
+		return cf, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return num, nil
+}
+
+// SetNX 当 key 不存在时写入，互斥的规则同 Incr
+func (f *SCache) SetNX(ctx context.Context, key any, value any, ttl time.Duration) (bool, error) {
+	var ok bool
+	err := f.atomic(ctx, key, func(cur *cacheFile) (*cacheFile, error) {
+		if cur != nil {
+			return nil, nil
+		}
+		msg, err := fscache.MarshalValue(f.encode, value)
+		if err != nil {
+			return nil, err
+		}
+		ok = true
+		return f.newCacheFile(key, ttl, msg), nil
+	})
+	return ok && err == nil, err
+}
+
+// CompareAndSwap 当 key 存在且版本号和 cas 一致时写入，互斥的规则同 Incr
+// 版本号保存在缓存文件的头部，每次写入都会变化，即使写入的值和原来的相同
+func (f *SCache) CompareAndSwap(ctx context.Context, key any, cas uint64, value any, ttl time.Duration) (bool, error) {
+	var ok bool
+	err := f.atomic(ctx, key, func(cur *cacheFile) (*cacheFile, error) {
+		if cur == nil || cur.CAS == 0 || cur.CAS != cas {
+			return nil, nil
+		}
+		msg, err := fscache.MarshalValue(f.encode, value)
+		if err != nil {
+			return nil, err
+		}
+		ok = true
+		return f.newCacheFile(key, ttl, msg), nil
+	})
+	return ok && err == nil, err
+}
@@ -14,12 +14,15 @@ import (
 func TestNew(t *testing.T) {
 	dir := "./testdata/cache_dir/"
 	c, err := New(&Option{
-		Dir: dir,
+		Dir:      dir,
+		StoreKey: true,
 	})
 	if err != nil {
 		t.Fatalf("new cache with error:%v", err)
 	}
 	cachetest.CacheTest(t, c, "fileCache")
+	cachetest.ScanTest(t, c, "fileCache")
+	cachetest.AtomicTest(t, c, "fileCache")
 	_ = os.RemoveAll(dir)
 }
 
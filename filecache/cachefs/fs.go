@@ -0,0 +1,167 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+// Package cachefs 文件缓存(filecache)使用的文件系统抽象
+//
+// 在 io/fs.FS 的基础上增加了写、rename、删除等操作，路径均为 io/fs 风格的相对路径，
+// 使用 "/" 分隔，如 "a/b/c.cache"，根目录为 "."
+package cachefs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FS 可读写的文件系统
+type FS interface {
+	fs.StatFS
+	fs.ReadDirFS
+
+	// MkdirAll 创建目录，包括所有不存在的上级目录
+	MkdirAll(name string, perm fs.FileMode) error
+
+	// CreateTemp 在 dir 目录下创建一个新的临时文件，规则同 os.CreateTemp
+	// 返回的 File.Name() 为文件在 FS 中的路径
+	CreateTemp(dir string, pattern string) (File, error)
+
+	// Rename 重命名文件，若 newName 已存在，会被替换
+	Rename(oldName string, newName string) error
+
+	// Remove 删除文件或者空目录
+	Remove(name string) error
+
+	// SyncDir 将目录的变更(如新建、重命名文件)持久化
+	SyncDir(name string) error
+}
+
+// Locker 支持跨进程的目录锁的 FS，可选
+// 如 Dir 创建的 FS 在类 Unix 系统上使用 flock 实现
+type Locker interface {
+	// Lock 对目录 name 加排它锁，会阻塞直到加锁成功，返回解锁的方法
+	Lock(name string) (unlock func() error, err error)
+}
+
+// File 可写的文件
+type File interface {
+	io.Writer
+	io.WriterAt
+
+	// Name 文件在 FS 中的路径
+	Name() string
+
+	// Sync 将文件内容持久化
+	Sync() error
+
+	Close() error
+}
+
+// Dir 创建一个以操作系统目录 dir 为根目录的 FS
+func Dir(dir string) FS {
+	return &dirFS{dir: dir}
+}
+
+type dirFS struct {
+	dir string
+}
+
+func (d *dirFS) join(op string, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
+
+func (d *dirFS) Open(name string) (fs.File, error) {
+	fp, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(fp)
+}
+
+func (d *dirFS) Stat(name string) (fs.FileInfo, error) {
+	fp, err := d.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(fp)
+}
+
+func (d *dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	fp, err := d.join("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(fp)
+}
+
+func (d *dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	fp, err := d.join("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(fp, perm)
+}
+
+func (d *dirFS) CreateTemp(dir string, pattern string) (File, error) {
+	fp, err := d.join("createtemp", dir)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(fp, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &osFile{File: f, name: path.Join(dir, filepath.Base(f.Name()))}, nil
+}
+
+func (d *dirFS) Rename(oldName string, newName string) error {
+	oldPath, err := d.join("rename", oldName)
+	if err != nil {
+		return err
+	}
+	newPath, err := d.join("rename", newName)
+	if err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+func (d *dirFS) Remove(name string) error {
+	fp, err := d.join("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(fp)
+}
+
+func (d *dirFS) SyncDir(name string) error {
+	fp, err := d.join("syncdir", name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fp)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+type osFile struct {
+	*os.File
+	name string
+}
+
+func (f *osFile) Name() string {
+	return f.name
+}
+
+var _ FS = (*dirFS)(nil)
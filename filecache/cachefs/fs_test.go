@@ -0,0 +1,86 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachefs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fsgo/fst"
+)
+
+func TestFS(t *testing.T) {
+	dir := "./testdata/dir_fs/"
+	defer os.RemoveAll(dir)
+	fst.NoError(t, os.MkdirAll(dir, 0755))
+
+	for name, fsys := range map[string]FS{"dir": Dir(dir), "mem": NewMem()} {
+		t.Run(name, func(t *testing.T) {
+			fst.NoError(t, fsys.MkdirAll("a/b", 0755))
+			fst.NoError(t, fsys.SyncDir("a/b"))
+
+			f, err := fsys.CreateTemp("a/b", "x.cache*")
+			fst.NoError(t, err)
+			fst.True(t, strings.HasPrefix(f.Name(), "a/b/x.cache"))
+			_, err = f.Write([]byte("hello world"))
+			fst.NoError(t, err)
+			_, err = f.WriteAt([]byte("HELLO"), 0)
+			fst.NoError(t, err)
+			fst.NoError(t, f.Sync())
+			fst.NoError(t, f.Close())
+
+			fst.NoError(t, fsys.Rename(f.Name(), "a/b/x.cache"))
+			got, err := fs.ReadFile(fsys, "a/b/x.cache")
+			fst.NoError(t, err)
+			fst.Equal(t, "HELLO world", string(got))
+
+			info, err := fsys.Stat("a/b/x.cache")
+			fst.NoError(t, err)
+			fst.Equal(t, int64(11), info.Size())
+
+			fst.NoError(t, fstest.TestFS(fsys, "a/b/x.cache"))
+
+			_, err = fsys.Open("a/b/not_exists")
+			fst.True(t, errors.Is(err, fs.ErrNotExist))
+			_, err = fsys.Open("../a")
+			fst.True(t, errors.Is(err, fs.ErrInvalid))
+
+			fst.Error(t, fsys.Remove("a/b"))
+			fst.NoError(t, fsys.Remove("a/b/x.cache"))
+			fst.True(t, errors.Is(fsys.Remove("a/b/x.cache"), fs.ErrNotExist))
+			fst.NoError(t, fsys.Remove("a/b"))
+			entries, err := fsys.ReadDir("a")
+			fst.NoError(t, err)
+			fst.Empty(t, entries)
+		})
+	}
+}
+
+func TestMemVisibility(t *testing.T) {
+	fsys := NewMem()
+	f, err := fsys.CreateTemp(".", "tmp")
+	fst.NoError(t, err)
+	_, err = f.Write([]byte("abc"))
+	fst.NoError(t, err)
+
+	// 关闭前写入的内容不可见
+	rd, err := fsys.Open(f.Name())
+	fst.NoError(t, err)
+	got, err := io.ReadAll(rd)
+	fst.NoError(t, err)
+	fst.Empty(t, got)
+
+	fst.NoError(t, f.Close())
+	got, err = fs.ReadFile(fsys, f.Name())
+	fst.NoError(t, err)
+	fst.Equal(t, "abc", string(got))
+	_, err = f.Write([]byte("d"))
+	fst.True(t, errors.Is(err, fs.ErrClosed))
+}
@@ -0,0 +1,46 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+//go:build unix
+
+package cachefs
+
+import (
+	"os"
+	"syscall"
+)
+
+func (d *dirFS) Lock(name string) (func() error, error) {
+	fp, err := d.join("lock", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	if err = flock(f, syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, &os.PathError{Op: "flock", Path: fp, Err: err}
+	}
+	unlock := func() error {
+		err := flock(f, syscall.LOCK_UN)
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+		return err
+	}
+	return unlock, nil
+}
+
+func flock(f *os.File, how int) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+var _ Locker = (*dirFS)(nil)
@@ -0,0 +1,377 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package cachefs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewMem 创建一个内存文件系统，一般用于测试
+// 写入的文件在 Sync 或者 Close 后，内容才对读取可见
+func NewMem() FS {
+	return &memFS{
+		root: newMemDir("."),
+	}
+}
+
+type memFS struct {
+	root *memNode
+	mux  sync.RWMutex
+	seq  uint64
+}
+
+type memNode struct {
+	children map[string]*memNode
+	modTime  time.Time
+	name     string
+	data     []byte
+	dir      bool
+}
+
+func newMemDir(name string) *memNode {
+	return &memNode{
+		name:     name,
+		dir:      true,
+		children: map[string]*memNode{},
+		modTime:  time.Now(),
+	}
+}
+
+func (n *memNode) info() fs.FileInfo {
+	return &memInfo{
+		name:    n.name,
+		size:    int64(len(n.data)),
+		dir:     n.dir,
+		modTime: n.modTime,
+	}
+}
+
+// lookup 查找节点，需要在加锁后调用
+func (m *memFS) lookup(op string, name string) (*memNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	node := m.root
+	if name == "." {
+		return node, nil
+	}
+	for _, part := range strings.Split(name, "/") {
+		if !node.dir {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		child, ok := node.children[part]
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// lookupParent 查找父目录，需要在加锁后调用
+func (m *memFS) lookupParent(op string, name string) (*memNode, string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	parent, err := m.lookup(op, path.Dir(name))
+	if err != nil {
+		return nil, "", err
+	}
+	if !parent.dir {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return parent, path.Base(name), nil
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	node, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.dir {
+		return &memDirFile{info: node.info(), entries: m.entries(node)}, nil
+	}
+	return &memReader{Reader: bytes.NewReader(node.data), info: node.info()}, nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	node, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return node.info(), nil
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	node, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return m.entries(node), nil
+}
+
+func (m *memFS) entries(node *memNode) []fs.DirEntry {
+	list := make([]fs.DirEntry, 0, len(node.children))
+	for _, child := range node.children {
+		list = append(list, fs.FileInfoToDirEntry(child.info()))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list
+}
+
+func (m *memFS) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return nil
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	node := m.root
+	for _, part := range strings.Split(name, "/") {
+		child, ok := node.children[part]
+		if !ok {
+			child = newMemDir(part)
+			node.children[part] = child
+		} else if !child.dir {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		node = child
+	}
+	return nil
+}
+
+func (m *memFS) CreateTemp(dir string, pattern string) (File, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	parent, err := m.lookup("createtemp", dir)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.dir {
+		return nil, &fs.PathError{Op: "createtemp", Path: dir, Err: errors.New("not a directory")}
+	}
+	prefix, suffix := pattern, ""
+	if pos := strings.LastIndex(pattern, "*"); pos >= 0 {
+		prefix, suffix = pattern[:pos], pattern[pos+1:]
+	}
+	for {
+		m.seq++
+		base := prefix + strconv.FormatUint(m.seq, 10) + suffix
+		if _, ok := parent.children[base]; ok {
+			continue
+		}
+		node := &memNode{name: base, modTime: time.Now()}
+		parent.children[base] = node
+		return &memFile{fs: m, node: node, name: path.Join(dir, base)}, nil
+	}
+}
+
+func (m *memFS) Rename(oldName string, newName string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	oldParent, oldBase, err := m.lookupParent("rename", oldName)
+	if err != nil {
+		return err
+	}
+	node, ok := oldParent.children[oldBase]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrNotExist}
+	}
+	newParent, newBase, err := m.lookupParent("rename", newName)
+	if err != nil {
+		return err
+	}
+	if old, ok := newParent.children[newBase]; ok && old.dir {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+	}
+	delete(oldParent.children, oldBase)
+	node.name = newBase
+	newParent.children[newBase] = node
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	parent, base, err := m.lookupParent("remove", name)
+	if err != nil {
+		return err
+	}
+	node, ok := parent.children[base]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if node.dir && len(node.children) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	delete(parent.children, base)
+	return nil
+}
+
+func (m *memFS) SyncDir(name string) error {
+	_, err := m.Stat(name)
+	return err
+}
+
+// memFile 可写的内存文件，写入的内容在 Sync 或者 Close 后才对读取可见
+type memFile struct {
+	fs     *memFS
+	node   *memNode
+	name   string
+	buf    []byte
+	closed bool
+}
+
+func (f *memFile) Name() string {
+	return f.name
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	f.buf = append(f.buf, p...)
+	return len(p), nil
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	if end := int(off) + len(p); end > len(f.buf) {
+		f.buf = append(f.buf, make([]byte, end-len(f.buf))...)
+	}
+	copy(f.buf[off:], p)
+	return len(p), nil
+}
+
+func (f *memFile) Sync() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.flush()
+	return nil
+}
+
+func (f *memFile) flush() {
+	f.fs.mux.Lock()
+	f.node.data = append([]byte(nil), f.buf...)
+	f.node.modTime = time.Now()
+	f.fs.mux.Unlock()
+}
+
+func (f *memFile) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.flush()
+	f.closed = true
+	return nil
+}
+
+type memReader struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (r *memReader) Stat() (fs.FileInfo, error) {
+	return r.info, nil
+}
+
+func (r *memReader) Close() error {
+	return nil
+}
+
+type memDirFile struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *memDirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (d *memDirFile) Close() error {
+	return nil
+}
+
+func (d *memDirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	remain := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remain, nil
+	}
+	if len(remain) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remain) {
+		n = len(remain)
+	}
+	d.offset += n
+	return remain[:n], nil
+}
+
+type memInfo struct {
+	modTime time.Time
+	name    string
+	size    int64
+	dir     bool
+}
+
+func (i *memInfo) Name() string {
+	return i.name
+}
+
+func (i *memInfo) Size() int64 {
+	return i.size
+}
+
+func (i *memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (i *memInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+func (i *memInfo) IsDir() bool {
+	return i.dir
+}
+
+func (i *memInfo) Sys() any {
+	return nil
+}
+
+var _ FS = (*memFS)(nil)
@@ -0,0 +1,217 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strconv"
+)
+
+// 缓存文件格式(版本 2)，数值均为大端序：
+//
+//	magic(4 字节，"FSCB") + version(1 字节) + 过期时间(8 字节，UnixNano) +
+//	创建时间(8 字节，Unix 时间戳) + 缓存内容长度(8 字节) + 版本号(8 字节) +
+//	CRC32(4 字节) + key 的长度(4 字节) + key
+//
+// 之后为缓存内容。key 为明文，仅在 Option.StoreKey 为 true 时保存，否则长度为 0，
+// 用于 Scan 和迁移目录结构(Migrate)时重新计算缓存文件的路径。
+// 版本号用于 CompareAndSwap，每次写入新的缓存值时生成。
+// CRC32 为缓存内容和头部(CRC32 字段为 0)依次计算的结果，读取完缓存内容时校验。
+//
+// 版本 1 为旧的文本格式：第 1 行为 "etime=过期时间"，第 2 行为 "ctime=创建时间"，之后为缓存内容，
+// 读取时依然支持。
+const (
+	fileMagic   = "FSCB"
+	fileVersion = 2
+
+	// headerLen 头部中固定部分(key 之前)的长度
+	headerLen = 45
+
+	// crcOffset 头部中 CRC32 的位置
+	crcOffset = 37
+
+	// maxKeyLen 头部中保存的 key 的最大长度，超过时不保存 key
+	maxKeyLen = 64 << 10
+)
+
+// headerSize 头部的长度
+func headerSize(key string) int {
+	return headerLen + len(key)
+}
+
+// ErrCorrupted 缓存文件已损坏，如内容被截断、校验和不一致
+// 读取到损坏的缓存文件时，会当做缓存不存在，并将文件重命名为 .corrupt 后缀隔离
+var ErrCorrupted = errors.New("corrupted cache file")
+
+func corrupted(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrCorrupted, fmt.Sprintf(format, args...))
+}
+
+// encodeHeader 编码头部，crc 为缓存内容的 CRC32
+func encodeHeader(cf *cacheFile, size uint64, crc uint32) []byte {
+	bf := make([]byte, 0, headerSize(cf.Key))
+	bf = append(bf, fileMagic...)
+	bf = append(bf, fileVersion)
+	bf = binary.BigEndian.AppendUint64(bf, uint64(cf.ExpireAt))
+	bf = binary.BigEndian.AppendUint64(bf, uint64(cf.CreateAt))
+	bf = binary.BigEndian.AppendUint64(bf, size)
+	bf = binary.BigEndian.AppendUint64(bf, cf.CAS)
+	bf = binary.BigEndian.AppendUint32(bf, 0)
+	bf = binary.BigEndian.AppendUint32(bf, uint32(len(cf.Key)))
+	bf = append(bf, cf.Key...)
+	binary.BigEndian.PutUint32(bf[crcOffset:], crc32.Update(crc, crc32.IEEETable, bf))
+	return bf
+}
+
+// binaryHeader 二进制格式的头部信息
+type binaryHeader struct {
+	file *cacheFile
+
+	// len 头部的长度
+	len  int
+	size uint64
+	crc  uint32
+
+	// raw CRC32 字段为 0 的头部，用于校验
+	raw []byte
+}
+
+func readBinaryHeader(br *bufio.Reader) (*binaryHeader, error) {
+	bf := make([]byte, headerLen)
+	if _, err := io.ReadFull(br, bf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, corrupted("header too short")
+		}
+		return nil, err
+	}
+	if string(bf[:4]) != fileMagic {
+		return nil, corrupted("invalid magic %q", bf[:4])
+	}
+	if version := bf[4]; version != fileVersion {
+		return nil, corrupted("unsupported version %d", version)
+	}
+	keyLen := binary.BigEndian.Uint32(bf[crcOffset+4:])
+	if keyLen > maxKeyLen {
+		return nil, corrupted("key too long, length=%d", keyLen)
+	}
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(br, key); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, corrupted("header too short")
+		}
+		return nil, err
+	}
+	h := &binaryHeader{
+		file: &cacheFile{
+			ExpireAt: int64(binary.BigEndian.Uint64(bf[5:13])),
+			CreateAt: int64(binary.BigEndian.Uint64(bf[13:21])),
+			Size:     int64(binary.BigEndian.Uint64(bf[21:29])),
+			CAS:      binary.BigEndian.Uint64(bf[29:crcOffset]),
+			Key:      string(key),
+		},
+		len:  headerLen + len(key),
+		size: binary.BigEndian.Uint64(bf[21:29]),
+		crc:  binary.BigEndian.Uint32(bf[crcOffset:]),
+	}
+	binary.BigEndian.PutUint32(bf[crcOffset:], 0)
+	h.raw = append(bf, key...)
+	return h, nil
+}
+
+// readTextHeader 读取版本 1 文本格式的头部信息
+func readTextHeader(br *bufio.Reader) (*cacheFile, error) {
+	// 第一行为过期时间，格式为：etime=UnixNano()
+	expireAt, err := readLine(br, "etime=")
+	if err != nil {
+		return nil, err
+	}
+	// 第二行为创建时间，格式为：ctime=unix时间戳
+	createAt, err := readLine(br, "ctime=")
+	if err != nil {
+		return nil, err
+	}
+	cf := &cacheFile{
+		ExpireAt: expireAt,
+		CreateAt: createAt,
+		Size:     -1,
+	}
+	return cf, nil
+}
+
+func readLine(br *bufio.Reader, prefix string) (int64, error) {
+	line, err := br.ReadSlice('\n')
+	if err != nil {
+		if err == io.EOF || err == bufio.ErrBufferFull {
+			return 0, corrupted("invalid %s line", prefix)
+		}
+		return 0, err
+	}
+	line = line[:len(line)-1]
+	if !bytes.HasPrefix(line, []byte(prefix)) {
+		return 0, corrupted("expect %s\\d+, got=%q", prefix, line)
+	}
+	num, err := strconv.ParseInt(string(line[len(prefix):]), 10, 64)
+	if err != nil {
+		return 0, corrupted("%s", err.Error())
+	}
+	return num, nil
+}
+
+// checksumReader 读取缓存内容，读取完成时校验长度和 CRC32
+type checksumReader struct {
+	r      io.Reader
+	hash   hash.Hash32
+	remain int64
+	want   uint32
+
+	// header 计算 CRC32 时，在缓存内容之后需要包含的头部
+	header []byte
+
+	// onCorrupt 发现文件损坏时的回调，可选
+	onCorrupt func(err error)
+}
+
+func newChecksumReader(r io.Reader, h *binaryHeader, onCorrupt func(err error)) *checksumReader {
+	return &checksumReader{
+		r:         io.LimitReader(r, int64(h.size)),
+		hash:      crc32.NewIEEE(),
+		remain:    int64(h.size),
+		want:      h.crc,
+		header:    h.raw,
+		onCorrupt: onCorrupt,
+	}
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+	c.remain -= int64(n)
+	if err != io.EOF {
+		return n, err
+	}
+	if c.remain > 0 {
+		return n, c.corrupted("content truncated, %d bytes missing", c.remain)
+	}
+	if got := crc32.Update(c.hash.Sum32(), crc32.IEEETable, c.header); got != c.want {
+		return n, c.corrupted("crc32 mismatch, got=%d want=%d", got, c.want)
+	}
+	return n, io.EOF
+}
+
+func (c *checksumReader) corrupted(format string, args ...any) error {
+	err := corrupted(format, args...)
+	if c.onCorrupt != nil {
+		c.onCorrupt(err)
+		c.onCorrupt = nil
+	}
+	return err
+}
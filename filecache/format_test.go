@@ -0,0 +1,174 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+)
+
+func TestFileFormat(t *testing.T) {
+	dir := "./testdata/format_dir/"
+	defer os.RemoveAll(dir)
+	sc, err := NewSCache(&Option{Dir: dir})
+	fst.NoError(t, err)
+	fc := sc.(*SCache)
+	// 避免自动 gc 读取到正在写入的文件
+	fc.gcTime = time.Now().UnixNano()
+	ctx := context.Background()
+
+	checkValue := func(t *testing.T, key string, want string) {
+		var got string
+		has, err := fc.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, want, got)
+	}
+	checkCorrupted := func(t *testing.T, key string) {
+		fp := fc.opt.CachePath(key)
+		fst.Equal(t, fscache.ErrNotExists, fc.Get(ctx, key).Err)
+		fst.False(t, osFileExists(fp))
+		fst.True(t, osFileExists(fp+corruptFileExt))
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		fst.NoError(t, fc.Set(ctx, "k1", "hello", time.Minute).Err)
+		content, err := os.ReadFile(fc.opt.CachePath("k1"))
+		fst.NoError(t, err)
+		fst.Equal(t, fileMagic, string(content[:4]))
+		fst.Equal(t, headerSize("")+len(`"hello"`), len(content))
+		checkValue(t, "k1", "hello")
+
+		// 开启 StoreKey 后，头部中保存 key
+		sk, err := NewSCache(&Option{Dir: dir, StoreKey: true})
+		fst.NoError(t, err)
+		sk.(*SCache).gcTime = time.Now().UnixNano()
+		fst.NoError(t, sk.Set(ctx, "k2", "hello", time.Minute).Err)
+		content, err = os.ReadFile(fc.opt.CachePath("k2"))
+		fst.NoError(t, err)
+		fst.Equal(t, headerSize("k2")+len(`"hello"`), len(content))
+		fst.Equal(t, "k2", string(content[headerLen:headerSize("k2")]))
+		checkValue(t, "k2", "hello")
+	})
+
+	t.Run("legacy text", func(t *testing.T) {
+		fp := fc.opt.CachePath("legacy")
+		fst.NoError(t, os.MkdirAll(filepath.Dir(fp), 0755))
+		content := fmt.Sprintf("etime=%d\nctime=%d\n\"old\"", time.Now().Add(time.Minute).UnixNano(), time.Now().Unix())
+		fst.NoError(t, os.WriteFile(fp, []byte(content), 0644))
+		checkValue(t, "legacy", "old")
+		fst.True(t, fc.Has(ctx, "legacy").Has)
+
+		content = fmt.Sprintf("etime=%d\nctime=%d\n\"old\"", time.Now().Add(-time.Minute).UnixNano(), time.Now().Unix())
+		fst.NoError(t, os.WriteFile(fp, []byte(content), 0644))
+		fst.Equal(t, fscache.ErrNotExists, fc.Get(ctx, "legacy").Err)
+
+		fst.NoError(t, os.WriteFile(fp, []byte("etime=abc\n"), 0644))
+		checkCorrupted(t, "legacy")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		fst.NoError(t, fc.Set(ctx, "k2", "hello world", time.Minute).Err)
+		fp := fc.opt.CachePath("k2")
+		fst.NoError(t, os.Truncate(fp, headerLen+3))
+		fst.False(t, fc.Has(ctx, "k2").Has)
+		fst.True(t, osFileExists(fp+corruptFileExt))
+
+		fst.NoError(t, fc.Set(ctx, "k2", "hello world", time.Minute).Err)
+		fst.NoError(t, os.Truncate(fp, 10))
+		checkCorrupted(t, "k2")
+
+		fst.NoError(t, os.WriteFile(fp, nil, 0644))
+		checkCorrupted(t, "k2")
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		fst.NoError(t, fc.Set(ctx, "k3", "hello world", time.Minute).Err)
+		fp := fc.opt.CachePath("k3")
+		content, err := os.ReadFile(fp)
+		fst.NoError(t, err)
+		content[len(content)-2] = 'X'
+		fst.NoError(t, os.WriteFile(fp, content, 0644))
+
+		rd, _, err := fc.GetReader(ctx, "k3")
+		fst.NoError(t, err)
+		_, err = io.ReadAll(rd)
+		fst.True(t, errors.Is(err, ErrCorrupted))
+		fst.NoError(t, rd.Close())
+		fst.True(t, osFileExists(fp+corruptFileExt))
+
+		fst.NoError(t, os.WriteFile(fp, content, 0644))
+		checkCorrupted(t, "k3")
+	})
+
+	t.Run("header checksum", func(t *testing.T) {
+		fst.NoError(t, fc.Set(ctx, "k4", "hello", time.Minute).Err)
+		fp := fc.opt.CachePath("k4")
+		content, err := os.ReadFile(fp)
+		fst.NoError(t, err)
+		// 修改过期时间
+		binary.BigEndian.PutUint64(content[5:13], uint64(time.Now().Add(time.Hour).UnixNano()))
+		fst.NoError(t, os.WriteFile(fp, content, 0644))
+		checkCorrupted(t, "k4")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		fst.NoError(t, fc.Set(ctx, "k5", "hello", time.Minute).Err)
+		fp := fc.opt.CachePath("k5")
+		content, err := os.ReadFile(fp)
+		fst.NoError(t, err)
+		content[4] = fileVersion + 1
+		fst.NoError(t, os.WriteFile(fp, content, 0644))
+		checkCorrupted(t, "k5")
+	})
+
+	t.Run("quarantine replaced", func(t *testing.T) {
+		fst.NoError(t, fc.Set(ctx, "k6", "old", time.Minute).Err)
+		fp := fc.opt.CacheName("k6")
+		info, err := fc.fs.Stat(fp)
+		fst.NoError(t, err)
+		fst.NoError(t, fc.Set(ctx, "k6", "new value", time.Minute).Err)
+		// 读取的文件已被替换，不会隔离新的文件
+		fc.quarantine(fp, info, ErrCorrupted)
+		checkValue(t, "k6", "new value")
+		fst.False(t, osFileExists(fc.opt.CachePath("k6")+corruptFileExt))
+	})
+
+	t.Run("gc and reset", func(t *testing.T) {
+		fp := fc.opt.CachePath("k3")
+		fst.True(t, osFileExists(fp+corruptFileExt))
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		fst.ErrorIs(t, fc.GC(cctx), context.Canceled)
+		fst.True(t, osFileExists(fp+corruptFileExt))
+		fst.NoError(t, fc.GC(ctx))
+		fst.False(t, osFileExists(fp+corruptFileExt))
+
+		fst.NoError(t, fc.Set(ctx, "expired", "v", -time.Second).Err)
+		fst.NoError(t, fc.GC(ctx))
+		fst.False(t, fileExists(fc.fs, fc.opt.CacheName("expired")))
+
+		fst.ErrorIs(t, fc.Reset(cctx), context.Canceled)
+		fst.True(t, fileExists(fc.fs, fc.opt.CacheName("k1")))
+		fst.NoError(t, fc.Reset(ctx))
+		fst.False(t, fileExists(fc.fs, fc.opt.CacheName("k1")))
+	})
+}
+
+func osFileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,169 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/cachetest"
+	"github.com/fsgo/fscache/filecache/cachefs"
+)
+
+// tempFiles 查找残留的临时文件
+func tempFiles(t *testing.T, fsys fs.FS) []string {
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !strings.HasSuffix(name, cacheFileExt) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	fst.NoError(t, err)
+	return names
+}
+
+func TestDurability(t *testing.T) {
+	dir := "./testdata/durability_dir/"
+	defer os.RemoveAll(dir)
+
+	newCache := func(t *testing.T, d Durability) (*SCache, *cachetest.FaultFS) {
+		ff := cachetest.NewFaultFS(cachefs.Dir(dir))
+		sc, err := NewSCache(&Option{FS: ff, Durability: d})
+		fst.NoError(t, err)
+		return sc.(*SCache), ff
+	}
+	ctx := context.Background()
+
+	t.Run("none", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityNone)
+		fst.NoError(t, fc.Set(ctx, "k1", "v1", time.Minute).Err)
+		fst.Equal(t, 0, ff.Calls(cachetest.OpSync, ""))
+		fst.Equal(t, 0, ff.Calls(cachetest.OpSyncDir, ""))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityFile)
+		fst.NoError(t, fc.Set(ctx, "k1", "v1", time.Minute).Err)
+		fst.Equal(t, 1, ff.Calls(cachetest.OpSync, ""))
+		fst.Equal(t, 0, ff.Calls(cachetest.OpSyncDir, ""))
+	})
+
+	t.Run("file and dir", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityFileDir)
+		fst.NoError(t, fc.Set(ctx, "k1", "v1", time.Minute).Err)
+		fst.Equal(t, 1, ff.Calls(cachetest.OpSync, ""))
+		fst.Equal(t, 1, ff.Calls(cachetest.OpSyncDir, path.Dir(fc.opt.CacheName("k1"))))
+	})
+
+	t.Run("MSet batch", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityFileDir)
+		kvs := fscache.KVData{}
+		for i := 0; i < 20; i++ {
+			kvs[fmt.Sprintf("m_%d", i)] = i
+		}
+		ret := fc.MSet(ctx, kvs, time.Minute)
+		fst.NoError(t, ret.Err())
+		fst.Equal(t, 20, len(ret))
+		fst.Equal(t, 20, ff.Calls(cachetest.OpSync, ""))
+		for k := range kvs {
+			d := path.Dir(fc.opt.CacheName(k))
+			if num := ff.Calls(cachetest.OpSyncDir, d); num != 1 {
+				t.Errorf("dir %q synced %d times, want=1", d, num)
+			}
+		}
+		for k, v := range kvs {
+			var got int
+			has, err := fc.Get(ctx, k).Value(&got)
+			fst.NoError(t, err)
+			fst.True(t, has)
+			fst.Equal(t, v.(int), got)
+		}
+	})
+
+	checkOld := func(t *testing.T, fc *SCache) {
+		var got string
+		has, err := fc.Get(ctx, "keep").Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, "old", got)
+		fst.Empty(t, tempFiles(t, fc.fs))
+	}
+
+	t.Run("partial write", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityFile)
+		fst.NoError(t, fc.Set(ctx, "keep", "old", time.Minute).Err)
+		ff.SetWriteLimit(headerLen + 3)
+		fst.True(t, errors.Is(fc.Set(ctx, "keep", strings.Repeat("new", 2000), time.Minute).Err, cachetest.ErrInjected))
+		checkOld(t, fc)
+
+		ret := fc.MSet(ctx, fscache.KVData{"keep": strings.Repeat("new", 2000)}, time.Minute)
+		fst.True(t, errors.Is(ret.Err(), cachetest.ErrInjected))
+		checkOld(t, fc)
+	})
+
+	t.Run("sync failed", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityFile)
+		ff.FailOn(cachetest.OpSync)
+		fst.True(t, errors.Is(fc.Set(ctx, "keep", "new", time.Minute).Err, cachetest.ErrInjected))
+		checkOld(t, fc)
+	})
+
+	t.Run("rename failed", func(t *testing.T) {
+		fc, ff := newCache(t, DurabilityFile)
+		ff.FailOn(cachetest.OpRename)
+		fst.True(t, errors.Is(fc.Set(ctx, "keep", "new", time.Minute).Err, cachetest.ErrInjected))
+		checkOld(t, fc)
+	})
+}
+
+func TestFaultFS(t *testing.T) {
+	for _, d := range []Durability{DurabilityNone, DurabilityFileDir} {
+		t.Run(fmt.Sprint("durability_", d), func(t *testing.T) {
+			ff := cachetest.NewFaultFS(cachefs.NewMem())
+			sc, err := NewSCache(&Option{FS: ff, Durability: d})
+			fst.NoError(t, err)
+			cachetest.FaultFSTest(t, sc, ff)
+			fst.Empty(t, tempFiles(t, ff))
+		})
+	}
+
+	t.Run("GC on read error", func(t *testing.T) {
+		ctx := context.Background()
+		ff := cachetest.NewFaultFS(cachefs.NewMem())
+		sc, err := NewSCache(&Option{FS: ff})
+		fst.NoError(t, err)
+		fc := sc.(*SCache)
+		fc.gcTime = time.Now().UnixNano()
+		fst.NoError(t, fc.Set(ctx, "k1", "v1", time.Minute).Err)
+
+		// 读取失败时，不能当做已过期删除
+		ff.FailOn(cachetest.OpOpen)
+		fst.NoError(t, fc.GC(ctx))
+		ff.FailOn()
+		fst.True(t, fc.Has(ctx, "k1").Has)
+	})
+}
+
+func TestMemFS(t *testing.T) {
+	c, err := New(&Option{FS: cachefs.NewMem(), StoreKey: true})
+	fst.NoError(t, err)
+	cachetest.CacheTest(t, c, "memFS")
+	cachetest.ScanTest(t, c, "memFS")
+	cachetest.AtomicTest(t, c, "memFS")
+}
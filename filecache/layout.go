@@ -0,0 +1,162 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Hash 计算缓存文件路径时使用的哈希算法
+type Hash uint8
+
+const (
+	// HashMD5 md5，32 个十六进制字符
+	HashMD5 Hash = iota
+
+	// HashSHA256 sha256，64 个十六进制字符
+	HashSHA256
+
+	// HashFNV 64 位的 FNV-1a，16 个十六进制字符
+	HashFNV
+
+	// HashXXHash 64 位的 xxHash，16 个十六进制字符
+	HashXXHash
+)
+
+// String 哈希算法的名称
+func (h Hash) String() string {
+	switch h {
+	case HashMD5:
+		return "md5"
+	case HashSHA256:
+		return "sha256"
+	case HashFNV:
+		return "fnv"
+	case HashXXHash:
+		return "xxhash"
+	default:
+		return fmt.Sprintf("Hash(%d)", uint8(h))
+	}
+}
+
+// size 十六进制哈希值的长度
+func (h Hash) size() int {
+	switch h {
+	case HashMD5:
+		return md5.Size * 2
+	case HashSHA256:
+		return sha256.Size * 2
+	case HashFNV, HashXXHash:
+		return 16
+	default:
+		return 0
+	}
+}
+
+// sum 计算十六进制的哈希值
+func (h Hash) sum(s string) string {
+	switch h {
+	case HashSHA256:
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	case HashFNV:
+		fh := fnv.New64a()
+		fh.Write([]byte(s))
+		return hex.EncodeToString(fh.Sum(nil))
+	case HashXXHash:
+		return hex.EncodeToString(binary.BigEndian.AppendUint64(nil, xxhash.Sum64String(s)))
+	default:
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// Layout 缓存文件的目录结构
+//
+// 对 key 计算十六进制的哈希值，依次取 Width 个字符作为 Depth 层目录，剩余的字符作为文件名，
+// 如 Layout{Hash: HashFNV, Depth: 2, Width: 2} 时，缓存文件为 "ab/cd/ef0123456789.cache"
+type Layout struct {
+	// Hash 哈希算法，可选，默认为 HashMD5
+	Hash Hash
+
+	// Depth 目录层级，为 0 时所有缓存文件都在根目录下
+	// 缓存文件较少时，可以使用较小的值，避免创建大量几乎为空的目录
+	Depth int
+
+	// Width 每层目录名的长度，可选，默认为 2
+	Width int
+}
+
+func (l *Layout) getWidth() int {
+	if l.Width == 0 {
+		return 2
+	}
+	return l.Width
+}
+
+// Check 检查是否正确
+func (l *Layout) Check() error {
+	size := l.Hash.size()
+	if size == 0 {
+		return fmt.Errorf("unsupported hash %s", l.Hash)
+	}
+	if l.Depth < 0 || l.Width < 0 {
+		return fmt.Errorf("invalid layout, depth=%d width=%d", l.Depth, l.Width)
+	}
+	if l.Depth*l.getWidth() >= size {
+		return fmt.Errorf("invalid layout, depth*width=%d exceeds the length of %s(%d)",
+			l.Depth*l.getWidth(), l.Hash, size)
+	}
+	return nil
+}
+
+// CacheName 获取缓存文件的路径
+func (l *Layout) CacheName(key any) string {
+	return l.nameOf(l.Hash.sum(keyString(key)))
+}
+
+// nameOf 使用十六进制的哈希值计算缓存文件的路径
+func (l *Layout) nameOf(s string) string {
+	width := l.getWidth()
+	parts := make([]string, 0, l.Depth+1)
+	for i := 0; i < l.Depth; i++ {
+		parts = append(parts, s[i*width:(i+1)*width])
+	}
+	parts = append(parts, s[l.Depth*width:]+cacheFileExt)
+	return path.Join(parts...)
+}
+
+// hashOf 从缓存文件的路径中还原哈希值，用于迁移不包含 key 的旧缓存文件
+func (l *Layout) hashOf(name string) (string, bool) {
+	s := strings.ReplaceAll(strings.TrimSuffix(name, cacheFileExt), "/", "")
+	if len(s) != l.Hash.size() {
+		return "", false
+	}
+	return s, true
+}
+
+// legacyCacheName 旧版本的缓存文件路径：md5 后使用 5 层目录，每层 3 个字符，
+// 文件名会跳过第 16 个字符，所以无法从路径中还原出完整的哈希值
+func legacyCacheName(key any) string {
+	return legacyNameOf(HashMD5.sum(keyString(key)))
+}
+
+func legacyNameOf(s string) string {
+	return path.Join(s[:3], s[3:6], s[6:9], s[9:12], s[12:15], s[16:]) + cacheFileExt
+}
+
+// keyString 将 key 转换为计算哈希值使用的字符串
+func keyString(key any) string {
+	return fmt.Sprint(key)
+}
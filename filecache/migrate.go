@@ -0,0 +1,269 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fsgo/fscache"
+)
+
+// MigrateResult 迁移的结果
+type MigrateResult struct {
+	// Moved 迁移到新路径的缓存文件数
+	Moved int
+
+	// Unchanged 路径不变，无需迁移的缓存文件数
+	Unchanged int
+
+	// Expired 已过期被删除的缓存文件数
+	Expired int
+
+	// Skipped 无法计算新路径，保留在原处的缓存文件数
+	// 如旧版本的缓存文件中没有保存 key，且无法从路径中还原出哈希值
+	Skipped int
+}
+
+// ErrNotMigrated 所有的缓存文件都无法计算新路径，没有迁移任何缓存文件
+var ErrNotMigrated = errors.New("no cache file can be migrated")
+
+// Migrate 将 from 中的缓存文件迁移到 to 的目录结构(Layout)下，from 和 to 可以是同一个目录
+//
+// 保存了 key(Option.StoreKey)的缓存文件，可以迁移到任意的目录结构；
+// 没有保存 key 的缓存文件，若 key 在 keys 中，也可以迁移到任意的目录结构。
+// 其他缓存文件，只有在 from 和 to 使用相同的哈希算法，且 from 不是旧版本的目录结构时，
+// 才能通过路径还原出哈希值完成迁移，否则会保留在原处(计入 Skipped)，不会删除。
+// 旧版本的目录结构(Layout 为 nil)中的缓存文件都没有保存 key，需要通过 keys 传入已知的 key 才能迁移。
+//
+// 若有缓存文件，但都无法迁移，返回的 error 为 ErrNotMigrated。
+// 迁移完成后，会删除 from 中的空目录。迁移时不应有其他进程在读写 from。
+func Migrate(ctx context.Context, from *Option, to *Option, keys ...any) (*MigrateResult, error) {
+	if err := from.Check(); err != nil {
+		return nil, fmt.Errorf("invalid from option: %w", err)
+	}
+	if err := to.Check(); err != nil {
+		return nil, fmt.Errorf("invalid to option: %w", err)
+	}
+	src, err := NewSCache(from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := NewSCache(to)
+	if err != nil {
+		return nil, err
+	}
+	m := &migrator{
+		from:   src.(*SCache),
+		to:     dst.(*SCache),
+		sameFS: from.FS == nil && to.FS == nil && filepath.Clean(from.Dir) == filepath.Clean(to.Dir),
+		result: &MigrateResult{},
+		keys:   make(map[string]string, len(keys)),
+	}
+	for _, key := range keys {
+		m.keys[from.CacheName(key)] = keyString(key)
+	}
+	if err = m.run(ctx); err != nil {
+		return m.result, err
+	}
+	if r := m.result; r.Skipped > 0 && r.Moved == 0 && r.Unchanged == 0 {
+		return m.result, fmt.Errorf("%w, %d files skipped, the keys are not stored in the files", ErrNotMigrated, r.Skipped)
+	}
+	return m.result, nil
+}
+
+type migrator struct {
+	from   *SCache
+	to     *SCache
+	result *MigrateResult
+	dirs   []string
+
+	// keys 调用方传入的 key，缓存文件在 from 中的路径 -> key 转换后的字符串
+	keys map[string]string
+
+	// sameFS from 和 to 是否为同一个目录，是的话可以直接 rename
+	sameFS bool
+}
+
+func (m *migrator) run(ctx context.Context) error {
+	// 先收集所有的缓存文件再迁移，避免在同一目录中迁移时，已迁移的文件被再次遍历到
+	var files []string
+	err := fs.WalkDir(m.from.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name != "." {
+				m.dirs = append(m.dirs, name)
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, cacheFileExt) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = m.migrate(name); err != nil {
+			return err
+		}
+	}
+	m.removeEmptyDirs()
+	return nil
+}
+
+func (m *migrator) migrate(name string) error {
+	cf, err := m.from.readHeader(name)
+	if err != nil {
+		if err == fscache.ErrNotExists {
+			return nil
+		}
+		if errors.Is(err, ErrCorrupted) {
+			return nil
+		}
+		return fmt.Errorf("read %q failed: %w", name, err)
+	}
+	if cf.ExpireAt < timeNow().UnixNano() {
+		if _, err = unlink(m.from.fs, name); err != nil {
+			return err
+		}
+		m.result.Expired++
+		return nil
+	}
+
+	target, ok := m.targetName(name, cf)
+	if !ok {
+		m.result.Skipped++
+		return nil
+	}
+	if m.sameFS && target == name {
+		m.result.Unchanged++
+		return nil
+	}
+	if err = m.move(name, target); err != nil {
+		return fmt.Errorf("migrate %q to %q failed: %w", name, target, err)
+	}
+	m.result.Moved++
+	return nil
+}
+
+// targetName 计算缓存文件在新目录结构下的路径
+func (m *migrator) targetName(name string, cf *cacheFile) (string, bool) {
+	if cf.Key != "" {
+		return m.to.opt.CacheName(cf.Key), true
+	}
+	if key, ok := m.keys[name]; ok {
+		return m.to.opt.CacheName(key), true
+	}
+	fl, tl := m.from.opt.Layout, m.to.opt.Layout
+	if fl == nil {
+		if tl == nil {
+			return name, true
+		}
+		return "", false
+	}
+	s, ok := fl.hashOf(name)
+	if !ok {
+		return "", false
+	}
+	if tl == nil {
+		if fl.Hash != HashMD5 {
+			return "", false
+		}
+		return legacyNameOf(s), true
+	}
+	if fl.Hash != tl.Hash {
+		return "", false
+	}
+	return tl.nameOf(s), true
+}
+
+// move 将缓存文件原样(不重新编码)移动到新的路径
+func (m *migrator) move(name string, target string) error {
+	dir := path.Dir(target)
+	if err := m.to.fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if m.sameFS {
+		return m.from.fs.Rename(name, target)
+	}
+	tmp, err := m.copyTemp(name, dir, path.Base(target))
+	if err != nil {
+		return err
+	}
+	if err = m.to.rename(tmp, target); err != nil {
+		return err
+	}
+	if m.to.opt.Durability >= DurabilityFileDir {
+		if err = m.to.fs.SyncDir(dir); err != nil {
+			return err
+		}
+	}
+	return m.from.fs.Remove(name)
+}
+
+func (m *migrator) copyTemp(name string, dir string, pattern string) (tmp string, err error) {
+	src, err := m.from.fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	file, err := m.to.fs.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		_ = file.Close()
+		m.to.removeTemp(file.Name())
+	}()
+	if _, err = io.Copy(file, src); err != nil {
+		return "", err
+	}
+	if m.to.opt.Durability >= DurabilityFile {
+		if err = file.Sync(); err != nil {
+			return "", err
+		}
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// removeEmptyDirs 从最深的目录开始，删除空目录
+func (m *migrator) removeEmptyDirs() {
+	sort.Slice(m.dirs, func(i, j int) bool {
+		return strings.Count(m.dirs[i], "/") > strings.Count(m.dirs[j], "/")
+	})
+	for _, dir := range m.dirs {
+		entries, err := m.from.fs.ReadDir(dir)
+		if err == nil && len(entries) == 0 {
+			_ = m.from.fs.Remove(dir)
+		}
+	}
+}
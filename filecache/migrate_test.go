@@ -0,0 +1,210 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache/filecache/cachefs"
+)
+
+func TestLayout(t *testing.T) {
+	t.Run("legacy", func(t *testing.T) {
+		sum := md5.Sum([]byte("123"))
+		s := hex.EncodeToString(sum[:])
+		want := path.Join(s[:3], s[3:6], s[6:9], s[9:12], s[12:15], s[16:]) + cacheFileExt
+		fst.Equal(t, want, (&Option{}).CacheName(123))
+	})
+
+	for _, h := range []Hash{HashMD5, HashSHA256, HashFNV, HashXXHash} {
+		t.Run(h.String(), func(t *testing.T) {
+			l := &Layout{Hash: h, Depth: 2}
+			fst.NoError(t, l.Check())
+			name := l.CacheName("hello")
+			parts := strings.Split(name, "/")
+			fst.Equal(t, 3, len(parts))
+			fst.Equal(t, 2, len(parts[0]))
+			fst.Equal(t, h.size()-4+len(cacheFileExt), len(parts[2]))
+			fst.Equal(t, name, l.CacheName("hello"))
+			fst.NotEqual(t, name, l.CacheName("world"))
+
+			s, ok := l.hashOf(name)
+			fst.True(t, ok)
+			fst.Equal(t, name, l.nameOf(s))
+		})
+	}
+
+	fst.Equal(t, "a430d84680aabd0b.cache", (&Layout{Hash: HashFNV}).CacheName("hello"))
+	fst.Error(t, (&Layout{Hash: HashFNV, Depth: 8}).Check())
+	fst.Error(t, (&Layout{Depth: -1}).Check())
+	fst.Error(t, (&Layout{Hash: 100}).Check())
+	fst.Error(t, (&Option{Dir: "a", Layout: &Layout{Hash: 100}}).Check())
+}
+
+// writeV1File 写入版本 1 文本格式(没有 key)的缓存文件
+func writeV1File(t *testing.T, fsys cachefs.FS, name string, value string, ttl time.Duration) {
+	content := fmt.Sprintf("etime=%d\nctime=%d\n%q", time.Now().Add(ttl).UnixNano(), time.Now().Unix(), value)
+
+	fst.NoError(t, fsys.MkdirAll(path.Dir(name), 0755))
+	f, err := fsys.CreateTemp(path.Dir(name), "tmp")
+	fst.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	fst.NoError(t, err)
+	fst.NoError(t, f.Close())
+	fst.NoError(t, fsys.Rename(f.Name(), name))
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	checkValue := func(t *testing.T, opt *Option, key string, want string) {
+		c, err := NewSCache(opt)
+		fst.NoError(t, err)
+		var got string
+		has, err := c.Get(ctx, key).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, want, got)
+	}
+	set := func(t *testing.T, opt *Option, key string, value string, ttl time.Duration) {
+		c, err := NewSCache(opt)
+		fst.NoError(t, err)
+		// 避免自动 gc 删除过期的缓存文件
+		c.(*SCache).gcTime = time.Now().UnixNano()
+		fst.NoError(t, c.Set(ctx, key, value, ttl).Err)
+	}
+	countDirs := func(t *testing.T, fsys fs.FS) int {
+		var num int
+		fst.NoError(t, fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if d.IsDir() && name != "." {
+				num++
+			}
+			return err
+		}))
+		return num
+	}
+
+	t.Run("legacy to sha256 in place", func(t *testing.T) {
+		dir := "./testdata/migrate_dir/"
+		defer os.RemoveAll(dir)
+		from := &Option{Dir: dir, StoreKey: true}
+		for i := 0; i < 10; i++ {
+			set(t, from, fmt.Sprint("k", i), fmt.Sprint("v", i), time.Minute)
+		}
+		set(t, from, "expired", "v", -time.Second)
+		// 旧版本的缓存文件，没有保存 key，无法迁移
+		writeV1File(t, from.GetFS(), from.CacheName("old"), "old", time.Minute)
+
+		to := &Option{Dir: dir, Layout: &Layout{Hash: HashSHA256, Depth: 1}}
+		ret, err := Migrate(ctx, from, to)
+		fst.NoError(t, err)
+		fst.Equal(t, MigrateResult{Moved: 10, Expired: 1, Skipped: 1}, *ret)
+		for i := 0; i < 10; i++ {
+			checkValue(t, to, fmt.Sprint("k", i), fmt.Sprint("v", i))
+		}
+		checkValue(t, from, "old", "old")
+
+		ret, err = Migrate(ctx, to, to)
+		fst.NoError(t, err)
+		fst.Equal(t, MigrateResult{Unchanged: 10, Skipped: 1}, *ret)
+	})
+
+	t.Run("without key in place", func(t *testing.T) {
+		dir := "./testdata/migrate_nokey_dir/"
+		defer os.RemoveAll(dir)
+		from := &Option{Dir: dir, Layout: &Layout{Hash: HashMD5, Depth: 1}}
+		for i := 0; i < 10; i++ {
+			set(t, from, fmt.Sprint("k", i), fmt.Sprint("v", i), time.Minute)
+		}
+		// 没有保存 key，无法迁移到使用不同哈希算法的目录结构
+		to := &Option{Dir: dir, Layout: &Layout{Hash: HashSHA256, Depth: 1}}
+		ret, err := Migrate(ctx, from, to)
+		fst.ErrorIs(t, err, ErrNotMigrated)
+		fst.Equal(t, MigrateResult{Skipped: 10}, *ret)
+
+		// 传入已知的 key 后可以迁移
+		ret, err = Migrate(ctx, from, to, "k1", "k2")
+		fst.NoError(t, err)
+		fst.Equal(t, MigrateResult{Moved: 2, Skipped: 8}, *ret)
+		checkValue(t, to, "k1", "v1")
+		checkValue(t, to, "k2", "v2")
+	})
+
+	t.Run("legacy without key", func(t *testing.T) {
+		// 旧版本写入的缓存：旧的目录结构和文本格式，没有保存 key
+		from := &Option{FS: cachefs.NewMem()}
+		keys := make([]any, 0, 10)
+		for i := 0; i < 10; i++ {
+			writeV1File(t, from.FS, from.CacheName(i), fmt.Sprint("v", i), time.Minute)
+			keys = append(keys, i)
+		}
+		to := &Option{FS: cachefs.NewMem(), Layout: &Layout{Hash: HashXXHash, Depth: 1}}
+		ret, err := Migrate(ctx, from, to)
+		fst.ErrorIs(t, err, ErrNotMigrated)
+		fst.Equal(t, MigrateResult{Skipped: 10}, *ret)
+
+		ret, err = Migrate(ctx, from, to, keys...)
+		fst.NoError(t, err)
+		fst.Equal(t, MigrateResult{Moved: 10}, *ret)
+		for i := 0; i < 10; i++ {
+			checkValue(t, to, fmt.Sprint(i), fmt.Sprint("v", i))
+		}
+		fst.Equal(t, 0, countDirs(t, from.FS))
+	})
+
+	t.Run("visit once in place", func(t *testing.T) {
+		dir := "./testdata/migrate_once_dir/"
+		defer os.RemoveAll(dir)
+		// 目录较多时，迁移的目标目录大多已存在，且可能还未被遍历到
+		from := &Option{Dir: dir, StoreKey: true, Layout: &Layout{Hash: HashMD5, Depth: 1, Width: 1}}
+		for i := 0; i < 200; i++ {
+			set(t, from, fmt.Sprint("k", i), fmt.Sprint("v", i), time.Minute)
+		}
+		to := &Option{Dir: dir, Layout: &Layout{Hash: HashSHA256, Depth: 1, Width: 1}}
+		ret, err := Migrate(ctx, from, to)
+		fst.NoError(t, err)
+		fst.Equal(t, MigrateResult{Moved: 200}, *ret)
+		for i := 0; i < 200; i++ {
+			checkValue(t, to, fmt.Sprint("k", i), fmt.Sprint("v", i))
+		}
+	})
+
+	t.Run("between filesystems", func(t *testing.T) {
+		from := &Option{FS: cachefs.NewMem(), Layout: &Layout{Hash: HashMD5, Depth: 5, Width: 3}}
+		for i := 0; i < 10; i++ {
+			set(t, from, fmt.Sprint("k", i), fmt.Sprint("v", i), time.Minute)
+		}
+		// 旧版本的缓存文件，可以从路径中还原出哈希值
+		writeV1File(t, from.FS, from.CacheName("old"), "old", time.Minute)
+
+		to := &Option{FS: cachefs.NewMem(), Layout: &Layout{Hash: HashMD5, Depth: 1}}
+		ret, err := Migrate(ctx, from, to)
+		fst.NoError(t, err)
+		fst.Equal(t, MigrateResult{Moved: 11}, *ret)
+		for i := 0; i < 10; i++ {
+			checkValue(t, to, fmt.Sprint("k", i), fmt.Sprint("v", i))
+		}
+		checkValue(t, to, "old", "old")
+		fst.Equal(t, 0, countDirs(t, from.FS))
+		fst.LessOrEqual(t, countDirs(t, to.FS), 11)
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		_, err := Migrate(ctx, &Option{}, &Option{Dir: "a"})
+		fst.Error(t, err)
+		_, err = Migrate(ctx, &Option{Dir: "a"}, &Option{Dir: "a", Layout: &Layout{Depth: 100}})
+		fst.Error(t, err)
+	})
+}
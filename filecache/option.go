@@ -5,31 +5,63 @@
 package filecache
 
 import (
-	"crypto/md5"
-	"encoding/hex"
 	"errors"
-	"fmt"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/filecache/cachefs"
 )
 
-const cacheFileExt = ".cache"
+const (
+	cacheFileExt = ".cache"
+
+	// corruptFileExt 已损坏并被隔离的缓存文件的后缀
+	corruptFileExt = ".corrupt"
+)
 
 // Option 配置选型
 type Option struct {
 	fscache.Option
 
-	// Dir 缓存文件存储目录，必填
+	// Dir 缓存文件存储目录，未设置 FS 时必填
 	Dir string
 
+	// FS 缓存文件所在的文件系统，可选，默认为 cachefs.Dir(Dir)
+	// 可以使用 cachefs.NewMem() 将缓存放在内存中，或者使用自定义的实现
+	FS cachefs.FS
+
 	// GCInterval 触发过期缓存清理的间隔时间，可选
 	// 若为 0，会使用默认值 300秒
 	GCInterval time.Duration
+
+	// Durability 写入缓存文件时的持久化级别，可选，默认为 DurabilityNone
+	Durability Durability
+
+	// Layout 缓存文件的目录结构，可选
+	// 为 nil 时使用旧版本的目录结构：md5 后使用 5 层目录，每层 3 个字符
+	// 修改后，可以使用 Migrate 将已有的缓存文件迁移到新的目录结构
+	Layout *Layout
+
+	// StoreKey 是否在缓存文件的头部保存 key(明文)，可选，默认不保存
+	// 保存后才能使用 Scan 遍历，以及使用 Migrate 迁移到使用不同哈希算法的目录结构
+	StoreKey bool
 }
 
+// Durability 写入缓存文件时的持久化级别
+type Durability int
+
+const (
+	// DurabilityNone 不主动 fsync，由操作系统决定何时落盘，断电时可能丢失或写入空的缓存文件
+	DurabilityNone Durability = iota
+
+	// DurabilityFile 在 rename 前 fsync 缓存文件，保证缓存文件的内容完整
+	DurabilityFile
+
+	// DurabilityFileDir 在 DurabilityFile 的基础上，rename 后 fsync 所在目录，保证缓存文件不会丢失
+	DurabilityFileDir
+)
+
 // GetGCInterval 获取自动 gc 的最小间隔
 func (o *Option) GetGCInterval() time.Duration {
 	if o.GCInterval == 0 {
@@ -43,19 +75,35 @@ func (o *Option) CacheDir() string {
 	return o.Dir
 }
 
+// GetFS 获取缓存文件所在的文件系统
+func (o *Option) GetFS() cachefs.FS {
+	if o.FS != nil {
+		return o.FS
+	}
+	return cachefs.Dir(o.Dir)
+}
+
 // CachePath 获取缓存文件地址
+// 使用自定义的 FS 时，返回的地址不一定是实际的文件路径，请使用 CacheName
 func (o *Option) CachePath(key any) string {
-	h := md5.New()
-	h.Write([]byte(fmt.Sprint(key)))
-	s := hex.EncodeToString(h.Sum(nil))
-	fp := filepath.Join(o.CacheDir(), s[:3], s[3:6], s[6:9], s[9:12], s[12:15], s[16:])
-	return strings.Join([]string{fp, cacheFileExt}, "")
+	return filepath.Join(o.CacheDir(), filepath.FromSlash(o.CacheName(key)))
+}
+
+// CacheName 获取缓存文件在 FS 中的路径
+func (o *Option) CacheName(key any) string {
+	if o.Layout == nil {
+		return legacyCacheName(key)
+	}
+	return o.Layout.CacheName(key)
 }
 
 // Check 检查是否正确
 func (o *Option) Check() error {
-	if len(o.Dir) == 0 {
+	if len(o.Dir) == 0 && o.FS == nil {
 		return errors.New("cache dir is empty")
 	}
+	if o.Layout != nil {
+		return o.Layout.Check()
+	}
 	return nil
 }
@@ -10,15 +10,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
 	"log"
 	"os"
-	"path/filepath"
-	"strconv"
+	"path"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/filecache/cachefs"
 	"github.com/fsgo/fscache/internal"
 )
 
@@ -28,29 +32,42 @@ func NewSCache(opt *Option) (fscache.SCache, error) {
 		return nil, err
 	}
 	codec := opt.GetCodec()
-	return &SCache{
+	sc := &SCache{
 		opt:    opt,
+		fs:     opt.GetFS(),
 		encode: codec.Marshal,
 		decode: codec.Unmarshal,
-	}, nil
+	}
+	sc.cas.Store(randUint64())
+	return sc, nil
 }
 
 // SCache 普通(非批量)缓存
 type SCache struct {
 	opt *Option
+	fs  cachefs.FS
 
 	decode fscache.UnmarshalFunc
 	encode fscache.MarshalFunc
 	gcTime int64
 
 	gcRunning atomic.Bool
+
+	// locks 原子操作时，进程内的锁，按照缓存文件所在的目录分组
+	locks [lockStripes]sync.Mutex
+
+	// cas 上一次生成的版本号，初始值为随机数
+	cas atomic.Uint64
 }
 
 // Get 获取
 func (f *SCache) Get(ctx context.Context, key any) fscache.GetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.GetResult{Err: err}
+	}
 	defer f.autoGC()
 
-	expire, data, err := f.readByKey(key, true)
+	expire, cf, err := f.readByKey(key, true)
 	if err != nil {
 		return fscache.GetResult{Err: err}
 	}
@@ -58,110 +75,323 @@ func (f *SCache) Get(ctx context.Context, key any) fscache.GetResult {
 		_, _ = f.delete(ctx, key)
 		return internal.GetRetNotExists
 	}
-	return fscache.GetResult{Payload: data, UnmarshalFunc: f.decode}
+	return fscache.GetResult{Payload: cf.Payload, UnmarshalFunc: f.decode, CAS: cf.CAS}
 }
 
 // Set 写入
 func (f *SCache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	defer f.autoGC()
+
+	msg, err := fscache.MarshalValue(f.encode, value)
+	if err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	cf := f.newCacheFile(key, ttl, msg)
+	if err = f.writeFile(f.opt.CacheName(key), cf); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	return internal.SetRetSuc
+}
+
+// MSet 批量写入
+//
+// 先写入所有的临时文件，再依次 rename 为缓存文件，
+// 当 Durability 为 DurabilityFileDir 时，每个目录只会 fsync 一次；
+// ctx 取消后，未写入临时文件的 key 的结果为 ctx.Err()，已写入临时文件的依然会 rename
+func (f *SCache) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
 	defer f.autoGC()
 
-	fp := f.opt.CachePath(key)
-	dir := filepath.Dir(fp)
-	if !fileExists(dir) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fscache.SetResult{Err: err}
+	result := make(fscache.MSetResult, len(kvs))
+	type pending struct {
+		key any
+		fp  string
+		tmp string
+	}
+	temps := make([]pending, 0, len(kvs))
+	for k, v := range kvs {
+		if err := ctx.Err(); err != nil {
+			result[k] = fscache.SetResult{Err: err}
+			continue
+		}
+		msg, err := fscache.MarshalValue(f.encode, v)
+		if err != nil {
+			result[k] = fscache.SetResult{Err: err}
+			continue
+		}
+		cf := f.newCacheFile(k, ttl, msg)
+		fp := f.opt.CacheName(k)
+		tmp, err := f.writeTemp(fp, cf, bytes.NewReader(msg))
+		if err != nil {
+			result[k] = fscache.SetResult{Err: err}
+			continue
 		}
+		temps = append(temps, pending{key: k, fp: fp, tmp: tmp})
+	}
+
+	dirs := make(map[string][]any, len(temps))
+	for _, item := range temps {
+		if err := f.rename(item.tmp, item.fp); err != nil {
+			result[item.key] = fscache.SetResult{Err: err}
+			continue
+		}
+		result[item.key] = internal.SetRetSuc
+		dir := path.Dir(item.fp)
+		dirs[dir] = append(dirs[dir], item.key)
+	}
+
+	if f.opt.Durability >= DurabilityFileDir {
+		for dir, keys := range dirs {
+			if err := f.fs.SyncDir(dir); err != nil {
+				for _, k := range keys {
+					result[k] = fscache.SetResult{Err: err}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// cacheFile 缓存文件的内容
+type cacheFile struct {
+	// ExpireAt 过期时间，UnixNano
+	ExpireAt int64
+
+	// CreateAt 创建时间，Unix 时间戳
+	CreateAt int64
+
+	// Key 缓存的 key 转换后的字符串，未开启 Option.StoreKey 或者版本 1 的缓存文件为空
+	Key string
+
+	// Size 缓存内容的长度，读取头部信息时设置，版本 1 的缓存文件为 -1
+	Size int64
+
+	// CAS 版本号，每次写入新的缓存值时生成，用于 CompareAndSwap，版本 1 的缓存文件为 0
+	CAS uint64
+
+	// Payload 编码后的数据
+	Payload []byte
+}
+
+func (f *SCache) newCacheFile(key any, ttl time.Duration, payload []byte) *cacheFile {
+	var ks string
+	if f.opt.StoreKey {
+		if ks = keyString(key); len(ks) > maxKeyLen {
+			ks = ""
+		}
+	}
+	return &cacheFile{
+		ExpireAt: timeNow().Add(ttl).UnixNano(),
+		CreateAt: timeNow().Unix(),
+		Key:      ks,
+		CAS:      f.nextCAS(),
+		Payload:  payload,
 	}
+}
+
+// writeFile 先写临时文件，然后 rename 为缓存文件
+func (f *SCache) writeFile(fp string, cf *cacheFile) error {
+	return f.writeStream(fp, cf, bytes.NewReader(cf.Payload))
+}
 
-	msg, err := f.encode(value)
+// writeStream 写入缓存文件，缓存内容从 body 中读取，cf.Payload 不会被使用
+func (f *SCache) writeStream(fp string, cf *cacheFile, body io.Reader) error {
+	tmp, err := f.writeTemp(fp, cf, body)
 	if err != nil {
-		return fscache.SetResult{Err: err}
+		return err
+	}
+	if err = f.rename(tmp, fp); err != nil {
+		return err
 	}
+	if f.opt.Durability >= DurabilityFileDir {
+		return f.fs.SyncDir(path.Dir(fp))
+	}
+	return nil
+}
 
-	expireAt := timeNow().Add(ttl)
+// writeTemp 写入临时文件，返回临时文件的路径
+// 若 Durability >= DurabilityFile，会在关闭前 fsync 文件
+func (f *SCache) writeTemp(fp string, cf *cacheFile, body io.Reader) (name string, err error) {
+	dir := path.Dir(fp)
+	if !fileExists(f.fs, dir) {
+		if err = f.fs.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
 
-	file, err := os.CreateTemp(dir, filepath.Base(fp))
+	file, err := f.fs.CreateTemp(dir, path.Base(fp))
 	if err != nil {
-		return fscache.SetResult{Err: err}
+		return "", err
 	}
 
 	defer func() {
-		_, err2 := unlink(file.Name())
-		if err2 != nil {
-			log.Printf("[fileCache.Set] unlink(%q) with error:%v\n", file.Name(), err2)
+		if err == nil {
+			return
 		}
+		_ = file.Close()
+		f.removeTemp(file.Name())
 	}()
 
-	// 写 cache 文件：
+	// 写 cache 文件：先写入占位的头部，写完缓存内容后，再写入实际的头部
 	writer := bufio.NewWriter(file)
-	err = writeStrings(writer,
-		// 第1行是缓存有效期，格式:etime=1590235951234907000
-		"etime=",
-		strconv.FormatInt(expireAt.UnixNano(), 10),
-		"\n",
-
-		// 第2行是创建时间：格式： ctime=1590235951
-		"ctime=",
-		strconv.FormatInt(timeNow().Unix(), 10),
-		"\n",
-	)
-
-	if err == nil {
-		_, err = writer.Write(msg)
+	if _, err = writer.Write(make([]byte, headerSize(cf.Key))); err != nil {
+		return "", err
 	}
+	crc := crc32.NewIEEE()
+	size, err := io.Copy(io.MultiWriter(writer, crc), body)
 	if err != nil {
-		return fscache.SetResult{Err: err}
+		return "", err
 	}
-
 	if err = writer.Flush(); err != nil {
-		return fscache.SetResult{Err: err}
+		return "", err
+	}
+	if _, err = file.WriteAt(encodeHeader(cf, uint64(size), crc.Sum32()), 0); err != nil {
+		return "", err
+	}
+	if f.opt.Durability >= DurabilityFile {
+		if err = file.Sync(); err != nil {
+			return "", err
+		}
 	}
 	if err = file.Close(); err != nil {
-		return fscache.SetResult{Err: err}
+		return "", err
 	}
-	if err = os.Rename(file.Name(), fp); err != nil {
-		return fscache.SetResult{Err: err}
+	return file.Name(), nil
+}
+
+// rename 将临时文件 rename 为缓存文件，失败时删除临时文件
+func (f *SCache) rename(tmp string, fp string) error {
+	if err := f.fs.Rename(tmp, fp); err != nil {
+		f.removeTemp(tmp)
+		return err
 	}
-	return internal.SetRetSuc
+	return nil
 }
 
-func (f *SCache) readByKey(key any, needData bool) (expire bool, data []byte, err error) {
-	fp := f.opt.CachePath(key)
+func (f *SCache) removeTemp(name string) {
+	if _, err := unlink(f.fs, name); err != nil {
+		log.Printf("[fileCache.Set] unlink(%q) with error:%v\n", name, err)
+	}
+}
+
+func (f *SCache) readByKey(key any, needData bool) (expire bool, cf *cacheFile, err error) {
+	fp := f.opt.CacheName(key)
 	return f.readByPath(fp, needData)
 }
 
-func (f *SCache) readByPath(fp string, needData bool) (expire bool, data []byte, err error) {
-	if !fileExists(fp) {
-		return false, nil, fscache.ErrNotExists
+func (f *SCache) readByPath(fp string, needData bool) (expire bool, cf *cacheFile, err error) {
+	if needData {
+		cf, err = f.readFile(fp)
+	} else {
+		cf, err = f.readHeader(fp)
 	}
+	if err != nil {
+		// 已损坏的文件在 openFile 中已被隔离
+		if errors.Is(err, ErrCorrupted) {
+			return false, nil, fscache.ErrNotExists
+		}
+		return false, nil, err
+	}
+	return cf.ExpireAt < timeNow().UnixNano(), cf, nil
+}
 
-	content, err := os.ReadFile(fp)
+// readFile 读取缓存文件的全部内容
+func (f *SCache) readFile(fp string) (*cacheFile, error) {
+	file, cf, body, err := f.openFile(fp)
 	if err != nil {
-		return true, nil, err
+		return nil, err
 	}
-	lines := bytes.SplitN(content, []byte("\n"), 3)
-	if len(lines) < 2 {
-		return true, nil, errors.New("invalid cache file")
+	defer file.Close()
+	if cf.Payload, err = io.ReadAll(body); err != nil {
+		return nil, err
 	}
-	// 第一行为过期时间，格式为：etime=UnixNano()
-	first := lines[0]
-	if len(first) < len("etime=") {
-		return true, nil, fmt.Errorf("not valid cache line, expect etime=\\d+, got=%q", first)
+	return cf, nil
+}
+
+// readHeader 只读取缓存文件的头部信息，返回的 Payload 为空
+func (f *SCache) readHeader(fp string) (*cacheFile, error) {
+	file, cf, _, err := f.openFile(fp)
+	if err != nil {
+		return nil, err
 	}
-	expireAt, err := strconv.ParseInt(string(first[len("etime="):]), 10, 64)
+	_ = file.Close()
+	return cf, nil
+}
+
+// openFile 打开缓存文件并解析头部信息，返回的 body 为缓存内容，读取完成后，需要关闭 file
+// 若文件已损坏，返回的 error 为 ErrCorrupted，读取 body 时发现损坏也会返回 ErrCorrupted，
+// 已损坏的文件会在这里被隔离，调用方无需再处理
+func (f *SCache) openFile(fp string) (_ fs.File, cf *cacheFile, body io.Reader, err error) {
+	file, err := f.fs.Open(fp)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil, fscache.ErrNotExists
+		}
+		return nil, nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			if errors.Is(err, ErrCorrupted) {
+				f.quarantine(fp, info, err)
+			}
+		}
+	}()
+
+	br := bufio.NewReader(file)
+	head, err := br.Peek(len(fileMagic))
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, nil, corrupted("file too short")
+		}
+		return nil, nil, nil, err
+	}
+
+	if string(head) != fileMagic {
+		// 版本 1 的文本格式
+		if cf, err = readTextHeader(br); err != nil {
+			return nil, nil, nil, err
+		}
+		return file, cf, br, nil
+	}
+
+	h, err := readBinaryHeader(br)
 	if err != nil {
-		return true, nil, err
+		return nil, nil, nil, err
 	}
-	expire = expireAt < timeNow().UnixNano()
-	if !needData {
-		return expire, nil, nil
+	if want := int64(h.len) + int64(h.size); info.Size() != want {
+		return nil, nil, nil, corrupted("file size mismatch, got=%d want=%d", info.Size(), want)
+	}
+	body = newChecksumReader(br, h, func(err error) {
+		f.quarantine(fp, info, err)
+	})
+	return file, h.file, body, nil
+}
+
+// quarantine 隔离已损坏的缓存文件，info 为读取时打开的文件的信息
+// 若 fp 已经被替换为其他文件(如并发的 Set 写入了新的缓存文件)，不会隔离
+func (f *SCache) quarantine(fp string, info fs.FileInfo, reason error) {
+	if cur, err := f.fs.Stat(fp); err != nil || !sameFile(info, cur) {
+		return
+	}
+	log.Printf("[fileCache][warn] quarantine %q, %s\n", fp, reason.Error())
+	if err := f.fs.Rename(fp, fp+corruptFileExt); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("[fileCache][warn] quarantine %q failed, %s\n", fp, err.Error())
 	}
-	// 第二行为创建时间，格式为：ctime=unix时间戳
-	return expire, lines[2], err
 }
 
 // Has 判断是否存在
 func (f *SCache) Has(ctx context.Context, key any) fscache.HasResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.HasResult{Err: err}
+	}
 	defer f.autoGC()
 
 	expire, _, err := f.readByKey(key, false)
@@ -176,28 +406,122 @@ func (f *SCache) Has(ctx context.Context, key any) fscache.HasResult {
 
 // Delete 删除
 func (f *SCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.DeleteResult{Err: err}
+	}
 	num, err := f.delete(ctx, key)
 	return fscache.DeleteResult{Deleted: num, Err: err}
 }
 
 func (f *SCache) delete(ctx context.Context, key any) (int, error) {
-	fp := f.opt.CachePath(key)
-	return unlink(fp)
+	fp := f.opt.CacheName(key)
+	return unlink(f.fs, fp)
 }
 
-// Reset  重置
+// Reset  重置，ctx 取消后会停止删除，返回 ctx.Err()
 func (f *SCache) Reset(ctx context.Context) error {
-	return filepath.Walk(f.opt.CacheDir(), func(path string, info os.FileInfo, err error) error {
-		if !info.IsDir() && strings.HasSuffix(info.Name(), cacheFileExt) {
-			err1 := os.Remove(path)
+	return fs.WalkDir(f.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() && isCacheFile(d.Name()) {
+			err1 := f.fs.Remove(name)
 			if err1 != nil {
-				log.Printf("[fileCache][warn] remove %q failed, %s\n", path, err1.Error())
+				log.Printf("[fileCache][warn] remove %q failed, %s\n", name, err1.Error())
 			}
 		}
 		return nil
 	})
 }
 
+// Rewrite 遍历所有未过期的缓存文件，使用 fn 重写缓存的内容，返回重写的文件数
+// fn 返回的内容和原内容一致时，不会重写文件；重写不会修改缓存的版本号(GetResult.CAS)。
+// 如更换 AEADCodec 的 key 后，可以使用 AEADCodec.Reencrypt 重新加密已有的缓存
+//
+// 重写时会和原子操作(Incr 等)一样对缓存文件所在的目录加锁；若重写期间缓存文件被 Set 修改了，
+// 会放弃重写该文件。单个文件重写失败时会继续处理其他文件，返回的 error 包含所有失败的原因。
+func (f *SCache) Rewrite(ctx context.Context, fn func(payload []byte) ([]byte, error)) (int, error) {
+	var num int
+	var errs []error
+	err := fs.WalkDir(f.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), cacheFileExt) {
+			return nil
+		}
+		ok, err := f.rewriteFile(name, fn)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rewrite %q failed: %w", name, err))
+		} else if ok {
+			num++
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return num, errors.Join(errs...)
+}
+
+// rewriteFile 加锁后重写一个缓存文件，返回是否重写了
+func (f *SCache) rewriteFile(fp string, fn func(payload []byte) ([]byte, error)) (bool, error) {
+	unlock, err := f.lock(fp)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	before, err := f.fs.Stat(fp)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	cf, err := f.current(fp)
+	if err != nil || cf == nil {
+		return false, err
+	}
+	payload, err := fn(cf.Payload)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(payload, cf.Payload) {
+		return false, nil
+	}
+	tmp, err := f.writeTemp(fp, cf, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	// 读取后缓存文件被其他的写入替换了，放弃重写，避免覆盖新的缓存
+	if after, err := f.fs.Stat(fp); err != nil || !sameFile(before, after) {
+		f.removeTemp(tmp)
+		return false, nil
+	}
+	if err = f.rename(tmp, fp); err != nil {
+		return false, err
+	}
+	if f.opt.Durability >= DurabilityFileDir {
+		if err = f.fs.SyncDir(path.Dir(fp)); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 func (f *SCache) autoGC() {
 	lastGc := atomic.LoadInt64(&f.gcTime)
 	newVal := timeNow().UnixNano()
@@ -215,59 +539,91 @@ func (f *SCache) autoGC() {
 				log.Printf("[fileCache][warn] autoGC panic:%v\n", re)
 			}
 		}()
-		f.gc()
+		// 自动 gc 的耗时不超过 gc 的间隔时间
+		ctx, cancel := context.WithTimeout(context.Background(), f.opt.GetGCInterval())
+		defer cancel()
+		f.gc(ctx)
 	}()
 }
 
-func (f *SCache) gc() {
+func (f *SCache) gc(ctx context.Context) {
+	if err := f.GC(ctx); err != nil {
+		log.Println("[fileCache.gc] with error:", err)
+	}
+}
+
+// GC 删除过期的缓存文件和已隔离的损坏文件，ctx 取消后会停止，返回 ctx.Err()
+// 默认会在读写缓存时按照 Option.GCInterval 的间隔自动执行
+func (f *SCache) GC(ctx context.Context) error {
 	if !f.gcRunning.CompareAndSwap(false, true) {
-		return
+		return nil
 	}
 	defer f.gcRunning.Store(false)
 
-	err := filepath.Walk(f.opt.CacheDir(), func(path string, info os.FileInfo, err error) error {
-		if os.IsNotExist(err) {
-			return nil
+	return fs.WalkDir(f.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
 		}
-		if !info.IsDir() {
-			if err1 := f.checkFile(path); err1 != nil {
-				log.Printf("[fileCache][warn] checkFile %q failed, %s\n", path, err1.Error())
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			if err1 := f.checkFile(name); err1 != nil {
+				log.Printf("[fileCache][warn] checkFile %q failed, %s\n", name, err1.Error())
 			}
 		}
 		return nil
 	})
-	if err != nil {
-		log.Println("[fileCache.gc] filepath.Walk with error:", err)
-	}
 }
 
 func (f *SCache) checkFile(fp string) error {
-	if strings.HasSuffix(fp, cacheFileExt) {
+	if strings.HasSuffix(fp, corruptFileExt) {
+		return f.fs.Remove(fp)
+	}
+	if !strings.HasSuffix(fp, cacheFileExt) {
 		return nil
 	}
 	expire, _, _ := f.readByPath(fp, false)
 	if expire {
-		return os.Remove(fp)
+		return f.fs.Remove(fp)
 	}
 
 	return nil
 }
 
+// isCacheFile 是否缓存文件(包括已隔离的损坏文件)
+func isCacheFile(name string) bool {
+	return strings.HasSuffix(name, cacheFileExt) || strings.HasSuffix(name, corruptFileExt)
+}
+
 var _ fscache.SCache = (*SCache)(nil)
+var _ fscache.MSetter = (*SCache)(nil)
 var _ fscache.ReSetter = (*SCache)(nil)
+var _ fscache.Atomic = (*SCache)(nil)
+
+// sameFile 判断是否为同一个文件，非 os 中的文件使用大小和修改时间判断
+func sameFile(a fs.FileInfo, b fs.FileInfo) bool {
+	if os.SameFile(a, b) {
+		return true
+	}
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
 
-func fileExists(name string) bool {
-	_, err := os.Stat(name)
-	return !os.IsNotExist(err)
+func fileExists(fsys fs.StatFS, name string) bool {
+	_, err := fsys.Stat(name)
+	return !errors.Is(err, fs.ErrNotExist)
 }
 
-func unlink(name string) (int, error) {
-	if fileExists(name) {
-		err := os.Remove(name)
+func unlink(fsys cachefs.FS, name string) (int, error) {
+	if fileExists(fsys, name) {
+		err := fsys.Remove(name)
 		if err == nil {
 			return 1, nil
 		}
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return 0, nil
 		}
 		return 0, err
@@ -5,8 +5,18 @@
 package filecache
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/filecache/cachefs"
 )
 
 func Test_fileExists(t *testing.T) {
@@ -35,7 +45,7 @@ func Test_fileExists(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := fileExists(tt.args.name); got != tt.want {
+			if got := fileExists(cachefs.Dir("."), tt.args.name); got != tt.want {
 				t.Errorf("fileExists() = %v, want %v", got, tt.want)
 			}
 		})
@@ -80,7 +90,7 @@ func Test_unlink(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := unlink(tt.args.getName())
+			got, err := unlink(cachefs.Dir("."), tt.args.getName())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("unlink() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -91,3 +101,65 @@ func Test_unlink(t *testing.T) {
 		})
 	}
 }
+
+func TestSCache_Rewrite(t *testing.T) {
+	dir := "./testdata/rewrite_dir/"
+	defer os.RemoveAll(dir)
+
+	key1 := bytes.Repeat([]byte("k"), 32)
+	key2 := bytes.Repeat([]byte("n"), 32)
+	c1, err := fscache.NewAEADCodec(nil, 1, map[uint32][]byte{1: key1})
+	fst.NoError(t, err)
+
+	opt := &Option{Dir: dir}
+	opt.Codec = c1
+	sc, err := NewSCache(opt)
+	fst.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		fst.NoError(t, sc.Set(ctx, i, fmt.Sprintf("token_%d", i), time.Minute).Err)
+	}
+
+	c2, err := fscache.NewAEADCodec(nil, 2, map[uint32][]byte{1: key1, 2: key2})
+	fst.NoError(t, err)
+	opt.Codec = c2
+	sc2, err := NewSCache(opt)
+	fst.NoError(t, err)
+	num, err := sc2.(*SCache).Rewrite(ctx, c2.Reencrypt)
+	fst.NoError(t, err)
+	fst.Equal(t, 10, num)
+
+	num, err = sc2.(*SCache).Rewrite(ctx, c2.Reencrypt)
+	fst.NoError(t, err)
+	fst.Equal(t, 0, num)
+
+	// 只有新 key 的时候，也可以读取
+	c3, err := fscache.NewAEADCodec(nil, 2, map[uint32][]byte{2: key2})
+	fst.NoError(t, err)
+	opt.Codec = c3
+	sc3, err := NewSCache(opt)
+	fst.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		var got string
+		has, err := sc3.Get(ctx, i).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, fmt.Sprintf("token_%d", i), got)
+	}
+
+	t.Run("continue on error", func(t *testing.T) {
+		errBad := errors.New("bad payload")
+		var calls int
+		num, err := sc3.(*SCache).Rewrite(ctx, func(payload []byte) ([]byte, error) {
+			calls++
+			if calls%2 == 0 {
+				return nil, errBad
+			}
+			return append(payload, ' '), nil
+		})
+		fst.ErrorIs(t, err, errBad)
+		fst.Equal(t, 10, calls)
+		fst.Equal(t, 5, num)
+	})
+}
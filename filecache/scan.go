@@ -0,0 +1,73 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// errStopScan 用于提前结束遍历
+var errStopScan = errors.New("stop scan")
+
+// Scan 遍历所有未过期，且以 prefix 开头的 key
+// 返回的 key 为缓存文件头部中保存的 key 的字符串形式(fmt.Sprint)，
+// 需要开启 Option.StoreKey，否则返回 errors.ErrUnsupported；没有保存 key 的缓存文件不会被遍历到
+func (f *SCache) Scan(ctx context.Context, prefix string, fn func(key any, meta fscache.ScanMeta) bool) error {
+	if !f.opt.StoreKey {
+		return fmt.Errorf("%w: filecache Scan requires Option.StoreKey", errors.ErrUnsupported)
+	}
+	err := fs.WalkDir(f.fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), cacheFileExt) {
+			return nil
+		}
+		cf, err := f.readHeader(name)
+		if err != nil {
+			if err == fscache.ErrNotExists || errors.Is(err, ErrCorrupted) {
+				return nil
+			}
+			return err
+		}
+		if cf.Key == "" || cf.ExpireAt < timeNow().UnixNano() || !strings.HasPrefix(cf.Key, prefix) {
+			return nil
+		}
+		meta := fscache.ScanMeta{
+			ExpireAt: time.Unix(0, cf.ExpireAt),
+			Size:     cf.Size,
+		}
+		if !fn(cf.Key, meta) {
+			return errStopScan
+		}
+		return nil
+	})
+	if err == errStopScan {
+		return nil
+	}
+	return err
+}
+
+// Load 从快照中恢复缓存
+func (f *SCache) Load(ctx context.Context, r io.Reader) error {
+	return fscache.LoadSnapshot(ctx, r, f)
+}
+
+var _ fscache.Scanner = (*SCache)(nil)
+var _ fscache.Loader = (*SCache)(nil)
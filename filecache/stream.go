@@ -0,0 +1,91 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/internal"
+)
+
+// Meta 缓存文件的元信息
+type Meta struct {
+	// ExpireAt 过期时间
+	ExpireAt time.Time
+
+	// CreateAt 创建时间
+	CreateAt time.Time
+}
+
+// SetReader 以流的方式写入，内容从 r 中读取，不会经过 Codec 编码，适用于较大的缓存内容
+// 和 Set 一样，先写入临时文件，完成后再 rename 为缓存文件
+// ctx 取消后会停止读取 r，返回 ctx.Err()，已有的缓存不受影响
+func (f *SCache) SetReader(ctx context.Context, key any, r io.Reader, ttl time.Duration) fscache.SetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	defer f.autoGC()
+
+	cf := f.newCacheFile(key, ttl, nil)
+	if err := f.writeStream(f.opt.CacheName(key), cf, &ctxReader{ctx: ctx, r: r}); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	return internal.SetRetSuc
+}
+
+// GetReader 以流的方式读取，返回的内容不会经过 Codec 解码，读取完成后需要 Close
+// 若缓存不存在或者已过期，返回 fscache.ErrNotExists；
+// 读取完成时会校验缓存内容，若已损坏，Read 会返回 ErrCorrupted
+func (f *SCache) GetReader(ctx context.Context, key any) (io.ReadCloser, *Meta, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	defer f.autoGC()
+
+	fp := f.opt.CacheName(key)
+	file, cf, body, err := f.openFile(fp)
+	if err != nil {
+		if errors.Is(err, ErrCorrupted) {
+			return nil, nil, fscache.ErrNotExists
+		}
+		return nil, nil, err
+	}
+	if cf.ExpireAt < timeNow().UnixNano() {
+		_ = file.Close()
+		_, _ = f.delete(ctx, key)
+		return nil, nil, fscache.ErrNotExists
+	}
+	meta := &Meta{
+		ExpireAt: time.Unix(0, cf.ExpireAt),
+		CreateAt: time.Unix(cf.CreateAt, 0),
+	}
+	rc := &readCloser{
+		Reader: body,
+		Closer: file,
+	}
+	return rc, meta, nil
+}
+
+// ctxReader ctx 取消后，Read 返回 ctx.Err()
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
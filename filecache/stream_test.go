@@ -0,0 +1,72 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package filecache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+)
+
+func TestSCache_SetReader(t *testing.T) {
+	dir := "./testdata/stream_dir/"
+	defer os.RemoveAll(dir)
+	sc, err := NewSCache(&Option{Dir: dir})
+	fst.NoError(t, err)
+	fc := sc.(*SCache)
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("0123456789\n"), 512*1024)
+	fst.NoError(t, fc.SetReader(ctx, "big", bytes.NewReader(content), time.Minute).Err)
+
+	rd, meta, err := fc.GetReader(ctx, "big")
+	fst.NoError(t, err)
+	got, err := io.ReadAll(rd)
+	fst.NoError(t, err)
+	fst.NoError(t, rd.Close())
+	fst.True(t, bytes.Equal(content, got))
+	fst.True(t, meta.ExpireAt.After(time.Now()))
+	fst.False(t, meta.CreateAt.IsZero())
+
+	fst.True(t, fc.Has(ctx, "big").Has)
+
+	t.Run("not exists", func(t *testing.T) {
+		_, _, err := fc.GetReader(ctx, "not_exists")
+		fst.Equal(t, fscache.ErrNotExists, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		fst.NoError(t, fc.SetReader(ctx, "expired", bytes.NewReader(content), -time.Second).Err)
+		_, _, err := fc.GetReader(ctx, "expired")
+		fst.Equal(t, fscache.ErrNotExists, err)
+		fst.False(t, fileExists(fc.fs, fc.opt.CacheName("expired")))
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		fst.NoError(t, fc.SetReader(ctx, "keep", bytes.NewReader([]byte("old")), time.Minute).Err)
+		r := io.MultiReader(bytes.NewReader(content), &errReader{})
+		fst.Error(t, fc.SetReader(ctx, "keep", r, time.Minute).Err)
+
+		rd, _, err := fc.GetReader(ctx, "keep")
+		fst.NoError(t, err)
+		got, err := io.ReadAll(rd)
+		fst.NoError(t, err)
+		fst.NoError(t, rd.Close())
+		fst.Equal(t, "old", string(got))
+	})
+}
+
+type errReader struct{}
+
+func (e *errReader) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
@@ -9,17 +9,3 @@ import (
 )
 
 var timeNow = time.Now
-
-type canWriteString interface {
-	WriteString(s string) (int, error)
-}
-
-func writeStrings(bf canWriteString, strs ...string) error {
-	for _, str := range strs {
-		_, err := bf.WriteString(str)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
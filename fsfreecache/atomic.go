@@ -0,0 +1,129 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fsfreecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/fsgo/fscache"
+)
+
+// ttlSeconds 原子操作写入时的有效期，单位为秒，和 Set 一致，<= 0 时表示永不过期
+func ttlSeconds(ttl time.Duration) int {
+	return int(ttl.Seconds())
+}
+
+// remainSeconds 剩余的有效期，expireAt 为 0 时表示永不过期
+func remainSeconds(expireAt uint32) int {
+	if expireAt == 0 {
+		return 0
+	}
+	return max(int(int64(expireAt)-time.Now().Unix()), 1)
+}
+
+// Incr 将 key 的整数值加上 delta，缓存值使用 Option.Codec 编解码
+// 使用 freecache 的 Update 写入，读取后被其他调用修改过(版本号变化)时会重试
+func (s *sCache) Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	kb, err := s.encodeKey(key)
+	if err != nil {
+		return 0, fmt.Errorf("encode key with error:%w", err)
+	}
+	for {
+		vb, expireAt, err := s.cache.GetWithExpiration(kb)
+		found := err == nil
+		if !found && !errors.Is(err, freecache.ErrNotFound) {
+			return 0, err
+		}
+		curCAS, cur := unpack(vb)
+		num := delta
+		expire := ttlSeconds(ttl)
+		var nb []byte
+		if found {
+			nv, n, err := fscache.AddInt(fscache.RawValue(cur), delta, s.opt.GetCodec())
+			if err != nil {
+				return 0, err
+			}
+			num, nb, expire = n, nv.(fscache.RawValue), remainSeconds(expireAt)
+		} else if nb, err = s.encode(num); err != nil {
+			return 0, fmt.Errorf("encode value with error:%w", err)
+		}
+
+		var swapped bool
+		_, _, err = s.cache.Update(kb, func(value []byte, exists bool) ([]byte, bool, int) {
+			if exists != found {
+				return nil, false, 0
+			}
+			if cas, _ := unpack(value); exists && cas != curCAS {
+				return nil, false, 0
+			}
+			swapped = true
+			return s.pack(nb), true, expire
+		})
+		if err != nil {
+			return 0, err
+		}
+		if swapped {
+			return num, nil
+		}
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// SetNX 当 key 不存在时写入，使用 freecache 的 GetOrSet 实现
+func (s *sCache) SetNX(ctx context.Context, key any, value any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	kb, err := s.encodeKey(key)
+	if err != nil {
+		return false, fmt.Errorf("encode key with error:%w", err)
+	}
+	vb, err := fscache.MarshalValue(s.encode, value)
+	if err != nil {
+		return false, fmt.Errorf("encode value with error:%w", err)
+	}
+	old, err := s.cache.GetOrSet(kb, s.pack(vb), ttlSeconds(ttl))
+	if err != nil {
+		return false, err
+	}
+	return old == nil, nil
+}
+
+// CompareAndSwap 当 key 存在且版本号和 cas 一致时写入，使用 freecache 的 Update 实现
+// 版本号和缓存值一起保存，每次写入都会变化，即使写入的值和原来的相同
+func (s *sCache) CompareAndSwap(ctx context.Context, key any, cas uint64, value any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	kb, err := s.encodeKey(key)
+	if err != nil {
+		return false, fmt.Errorf("encode key with error:%w", err)
+	}
+	vb, err := fscache.MarshalValue(s.encode, value)
+	if err != nil {
+		return false, fmt.Errorf("encode value with error:%w", err)
+	}
+	_, replaced, err := s.cache.Update(kb, func(old []byte, found bool) ([]byte, bool, int) {
+		if !found {
+			return nil, false, 0
+		}
+		if cur, _ := unpack(old); cur == 0 || cur != cas {
+			return nil, false, 0
+		}
+		return s.pack(vb), true, ttlSeconds(ttl)
+	})
+	return replaced, err
+}
+
+var _ fscache.Atomic = (*sCache)(nil)
@@ -5,9 +5,16 @@
 package fsfreecache
 
 import (
+	"bytes"
+	"context"
 	"testing"
+	"time"
 
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
 	"github.com/fsgo/fscache/cachetest"
+	"github.com/fsgo/fscache/lrucache"
 )
 
 func TestNew(t *testing.T) {
@@ -16,4 +23,52 @@ func TestNew(t *testing.T) {
 		t.Fatalf("new cache with error:%v", err)
 	}
 	cachetest.CacheTest(t, c, "freeCache")
+	cachetest.ScanTest(t, c, "freeCache")
+	cachetest.AtomicTest(t, c, "freeCache")
+}
+
+func TestSnapshot(t *testing.T) {
+	newCache := func() fscache.Cache {
+		c, err := New(&Option{})
+		fst.NoError(t, err)
+		return c
+	}
+	cachetest.SnapshotTest(t, newCache(), newCache(), "freeCache")
+
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	cachetest.SnapshotTest(t, newCache(), lc, "freeCache")
+	cachetest.SnapshotTest(t, lc, newCache(), "lru")
+}
+
+func TestKeyCodec(t *testing.T) {
+	ctx := context.Background()
+	t.Run("default", func(t *testing.T) {
+		// 默认使用 Codec 编码 key
+		opt := &Option{}
+		opt.Codec = fscache.MsgpackCodec
+		sc, err := NewSCache(opt)
+		fst.NoError(t, err)
+		fst.NoError(t, sc.Set(ctx, "k1", "v1", time.Minute).Err)
+		kb, err := fscache.MsgpackCodec.Marshal("k1")
+		fst.NoError(t, err)
+		_, err = sc.(*sCache).cache.Get(kb)
+		fst.NoError(t, err)
+		cachetest.ScanTest(t, sc, "msgpack")
+	})
+
+	t.Run("AEAD", func(t *testing.T) {
+		codec, err := fscache.NewAEADCodec(nil, 1, map[uint32][]byte{1: bytes.Repeat([]byte("k"), 32)})
+		fst.NoError(t, err)
+		opt := &Option{}
+		opt.Codec = codec
+		c, err := New(opt)
+		fst.NoError(t, err)
+		cachetest.CacheTest(t, c, "aead")
+		cachetest.ScanTest(t, c, "aead")
+
+		// 编码结果不确定的 KeyCodec
+		_, err = New(&Option{KeyCodec: codec})
+		fst.Error(t, err)
+	})
 }
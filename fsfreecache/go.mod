@@ -4,10 +4,9 @@ go 1.21
 
 require (
 	github.com/coocood/freecache v1.2.4
-	github.com/fsgo/fscache v0.0.4-0.20240809074344-6f0ac0264ee2
+	github.com/fsgo/fscache v0.1.0
+	github.com/fsgo/fst v0.0.4
 )
 
-require (
-	github.com/cespare/xxhash/v2 v2.1.2 // indirect
-	github.com/fsgo/fst v0.0.4 // indirect
-)
+require github.com/cespare/xxhash/v2 v2.1.2 // indirect
+
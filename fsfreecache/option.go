@@ -15,6 +15,24 @@ type Option struct {
 	// MemSize 内存大小，最小值 512 * 1024
 	// 若为 0，使用默认值 8*1024*1024
 	MemSize int
+
+	// KeyCodec 编码 key 使用的编解码器，可选，默认和缓存值一样使用 Codec，
+	// Codec 为 *fscache.AEADCodec 时，默认为 fscache.JSONCodec。
+	// key 的编码结果需要是确定的，否则 NewSCache 会返回错误
+	KeyCodec fscache.Codec
+}
+
+// GetKeyCodec 获取编码 key 使用的编解码器
+func (o *Option) GetKeyCodec() fscache.Codec {
+	if o.KeyCodec != nil {
+		return o.KeyCodec
+	}
+	codec := o.GetCodec()
+	if _, ok := codec.(*fscache.AEADCodec); ok {
+		// 每次加密的结果都不同，不能用于编码 key
+		return fscache.JSONCodec
+	}
+	return codec
 }
 
 const defaultSize = 8 * 1024 * 1024
@@ -5,9 +5,14 @@
 package fsfreecache
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/coocood/freecache"
@@ -17,6 +22,9 @@ import (
 
 // NewSCache 创建普通的缓存实例
 func NewSCache(opt *Option) (fscache.SCache, error) {
+	if err := checkKeyCodec(opt.GetKeyCodec()); err != nil {
+		return nil, err
+	}
 	c := freecache.NewCache(opt.GetMemSize())
 	codec := opt.GetCodec()
 	return &sCache{
@@ -27,19 +35,80 @@ func NewSCache(opt *Option) (fscache.SCache, error) {
 	}, nil
 }
 
+// checkKeyCodec 检查编码 key 的结果是否是确定的，否则写入的缓存都不能读取到
+func checkKeyCodec(kc fscache.Codec) error {
+	const key = "fsfreecache_key_check"
+	b1, err := kc.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("encode key with error:%w", err)
+	}
+	b2, err := kc.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("encode key with error:%w", err)
+	}
+	if !bytes.Equal(b1, b2) {
+		return errors.New("the encoded key is not deterministic, set Option.KeyCodec to another codec")
+	}
+	return nil
+}
+
 // sCache 普通缓存
+// freecache 中保存的值为：版本号(8 字节，大端序) + 编码后的缓存值，版本号用于 CompareAndSwap
 type sCache struct {
 	opt    *Option
 	cache  *freecache.Cache
 	decode fscache.UnmarshalFunc
 	encode fscache.MarshalFunc
+
+	// cas 上一次生成的版本号
+	cas atomic.Uint64
+}
+
+// casLen 保存在 freecache 中的值里，版本号的长度
+const casLen = 8
+
+// pack 在编码后的缓存值前加上新生成的版本号
+func (s *sCache) pack(payload []byte) []byte {
+	bf := make([]byte, 0, casLen+len(payload))
+	bf = binary.BigEndian.AppendUint64(bf, s.cas.Add(1))
+	return append(bf, payload...)
+}
+
+// unpack 拆分 freecache 中保存的值，返回版本号和编码后的缓存值
+func unpack(vb []byte) (uint64, []byte) {
+	if len(vb) < casLen {
+		return 0, vb
+	}
+	return binary.BigEndian.Uint64(vb), vb[casLen:]
+}
+
+// encodeKey 使用 Option.GetKeyCodec() 编码 key
+func (s *sCache) encodeKey(key any) ([]byte, error) {
+	return s.opt.GetKeyCodec().Marshal(key)
+}
+
+// decodeKey 解码 key，使用 fscache.JSONCodec 时，数字类型的 key 解码为 json.Number
+func (s *sCache) decodeKey(kb []byte) (any, error) {
+	var key any
+	kc := s.opt.GetKeyCodec()
+	if kc != fscache.JSONCodec {
+		err := kc.Unmarshal(kb, &key)
+		return key, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(kb))
+	dec.UseNumber()
+	err := dec.Decode(&key)
+	return key, err
 }
 
 func (s *sCache) Get(ctx context.Context, key any) fscache.GetResult {
-	kb, err := s.opt.GetCodec().Marshal(key)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return fscache.GetResult{Err: err}
 	}
+	kb, err := s.encodeKey(key)
+	if err != nil {
+		return fscache.GetResult{Err: fmt.Errorf("encode key with error:%w", err)}
+	}
 	vb, err := s.cache.Get(kb)
 	if err != nil {
 		if errors.Is(err, freecache.ErrNotFound) {
@@ -47,24 +116,31 @@ func (s *sCache) Get(ctx context.Context, key any) fscache.GetResult {
 		}
 		return fscache.GetResult{Err: err}
 	}
-	return fscache.GetResult{Payload: vb, UnmarshalFunc: s.decode}
+	cas, payload := unpack(vb)
+	return fscache.GetResult{Payload: payload, UnmarshalFunc: s.decode, CAS: cas}
 }
 
 func (s *sCache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
-	kb, err := s.encode(key)
+	if err := ctx.Err(); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	kb, err := s.encodeKey(key)
 	if err != nil {
 		return fscache.SetResult{Err: fmt.Errorf("encode key with error:%w", err)}
 	}
-	vb, err := s.encode(value)
+	vb, err := fscache.MarshalValue(s.encode, value)
 	if err != nil {
 		return fscache.SetResult{Err: fmt.Errorf("encode value with error:%w", err)}
 	}
-	errSet := s.cache.Set(kb, vb, int(ttl.Seconds()))
+	errSet := s.cache.Set(kb, s.pack(vb), int(ttl.Seconds()))
 	return fscache.SetResult{Err: errSet}
 }
 
 func (s *sCache) Has(ctx context.Context, key any) fscache.HasResult {
-	kb, err := s.encode(key)
+	if err := ctx.Err(); err != nil {
+		return fscache.HasResult{Err: err}
+	}
+	kb, err := s.encodeKey(key)
 	if err != nil {
 		return fscache.HasResult{Err: fmt.Errorf("encode key with error:%w", err)}
 	}
@@ -79,7 +155,10 @@ func (s *sCache) Has(ctx context.Context, key any) fscache.HasResult {
 }
 
 func (s *sCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
-	kb, err := s.encode(key)
+	if err := ctx.Err(); err != nil {
+		return fscache.DeleteResult{Err: err}
+	}
+	kb, err := s.encodeKey(key)
 	if err != nil {
 		return fscache.DeleteResult{Err: fmt.Errorf("encode key with error:%w", err)}
 	}
@@ -90,8 +169,103 @@ func (s *sCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
 }
 
 func (s *sCache) Reset(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	s.cache.Clear()
 	return nil
 }
 
+// Scan 遍历所有未过期，且以 prefix 开头的 key
+// 返回的 key 为编码后的 key 使用 Option.GetKeyCodec() 解码的结果，
+// 使用 fscache.JSONCodec(默认)时，数字类型的 key 会是 json.Number
+func (s *sCache) Scan(ctx context.Context, prefix string, fn func(key any, meta fscache.ScanMeta) bool) error {
+	// 迭代器会跳过已过期的缓存
+	it := s.cache.NewIterator()
+	for entry := it.Next(); entry != nil; entry = it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key, err := s.decodeKey(entry.Key)
+		if err != nil {
+			return fmt.Errorf("decode key %q with error:%w", entry.Key, err)
+		}
+		if !fscache.KeyHasPrefix(key, prefix) {
+			continue
+		}
+		_, payload := unpack(entry.Value)
+		meta := fscache.ScanMeta{
+			Size: int64(len(payload)),
+		}
+		if entry.ExpireAt != 0 {
+			meta.ExpireAt = time.Unix(int64(entry.ExpireAt), 0)
+		}
+		if !fn(key, meta) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Dump 导出所有未过期的缓存
+// 导出的 key 为编码后的 key 使用 Option.GetKeyCodec() 解码的结果，
+// 使用 fscache.JSONCodec(默认)时，数字类型的 key 会转换为 int64 或者 float64
+func (s *sCache) Dump(ctx context.Context, w io.Writer) error {
+	sw, err := fscache.NewSnapshotWriter(w)
+	if err != nil {
+		return err
+	}
+	it := s.cache.NewIterator()
+	for entry := it.Next(); entry != nil; entry = it.Next() {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		key, err := s.decodeKey(entry.Key)
+		if err != nil {
+			return fmt.Errorf("decode key %q with error:%w", entry.Key, err)
+		}
+		if num, ok := key.(json.Number); ok {
+			if key, err = num.Int64(); err != nil {
+				key, _ = num.Float64()
+			}
+		}
+		_, payload := unpack(entry.Value)
+		rec := &fscache.SnapshotRecord{
+			Key:     key,
+			Payload: payload,
+		}
+		if entry.ExpireAt != 0 {
+			rec.ExpireAt = time.Unix(int64(entry.ExpireAt), 0)
+		}
+		if err = sw.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load 从快照中恢复缓存
+// 快照中不过期的缓存恢复后也不过期，剩余有效期不足 1 秒的缓存会被忽略
+func (s *sCache) Load(ctx context.Context, r io.Reader) error {
+	sr, err := fscache.NewSnapshotReader(r)
+	if err != nil {
+		return err
+	}
+	return sr.Range(ctx, func(rec *fscache.SnapshotRecord) error {
+		var ttl time.Duration
+		if !rec.ExpireAt.IsZero() {
+			if ttl = rec.TTL(); ttl < time.Second {
+				return nil
+			}
+		}
+		if ret := s.Set(ctx, rec.Key, fscache.RawValue(rec.Payload), ttl); ret.Err != nil {
+			return fmt.Errorf("set key %v failed: %w", rec.Key, ret.Err)
+		}
+		return nil
+	})
+}
+
 var _ fscache.SCache = (*sCache)(nil)
+var _ fscache.Scanner = (*sCache)(nil)
+var _ fscache.Dumper = (*sCache)(nil)
+var _ fscache.Loader = (*sCache)(nil)
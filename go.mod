@@ -2,4 +2,7 @@ module github.com/fsgo/fscache
 
 go 1.21
 
-require github.com/fsgo/fst v0.0.4
+require (
+	github.com/cespare/xxhash/v2 v2.1.2
+	github.com/fsgo/fst v0.0.4
+)
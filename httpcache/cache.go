@@ -0,0 +1,176 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// fetchFunc 从上游获取响应
+type fetchFunc func(req *http.Request) (*http.Response, error)
+
+// cache Transport 和 Handler 共用的缓存逻辑
+type cache struct {
+	opt    *Option
+	shared bool
+}
+
+func (c *cache) do(req *http.Request, fetch fetchFunc) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := fetch(req)
+		if err == nil {
+			c.invalidate(req, resp.StatusCode)
+		}
+		return resp, err
+	}
+	reqCC := parseCacheControl(req.Header)
+	if reqCC.has("no-store") {
+		return fetch(req)
+	}
+	ctx := req.Context()
+	key := c.opt.key(req)
+	ent := c.load(ctx, key, req)
+	now := timeNow()
+	if ent != nil && ent.fresh(now, reqCC, req) {
+		return ent.response(req, now, StatusHit), nil
+	}
+
+	outReq := req
+	if ent != nil && ent.hasValidator() {
+		outReq = req.Clone(ctx)
+		outReq.Header.Del("If-None-Match")
+		outReq.Header.Del("If-Modified-Since")
+		if etag := ent.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		} else {
+			outReq.Header.Set("If-Modified-Since", ent.Header.Get("Last-Modified"))
+		}
+	}
+	resp, err := fetch(outReq)
+	now = timeNow()
+	if err != nil || serverErrors[resp.StatusCode] {
+		if ent != nil && ent.staleIfError(now, reqCC, c.opt.StaleIfError) {
+			if resp != nil {
+				discard(resp)
+			}
+			return ent.response(req, now, StatusStale), nil
+		}
+		return resp, err
+	}
+	if ent != nil && outReq != req && resp.StatusCode == http.StatusNotModified {
+		discard(resp)
+		ent.update(resp, now, c.shared)
+		c.store(ctx, key, ent, now)
+		return ent.response(req, now, StatusRevalidated), nil
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		// 客户端自己发起的条件请求，原样返回，不影响已有缓存
+		resp.Header.Set(HeaderCacheStatus, StatusMiss)
+		return resp, nil
+	}
+	if !c.storable(req, resp) {
+		if ent != nil {
+			c.opt.Cache.Delete(ctx, key)
+		}
+		resp.Header.Set(HeaderCacheStatus, StatusMiss)
+		return resp, nil
+	}
+	return c.storeResponse(ctx, key, req, resp, now)
+}
+
+// invalidate 不安全的方法执行成功后，删除相同 URL 的 GET 请求的缓存
+func (c *cache) invalidate(req *http.Request, code int) {
+	switch req.Method {
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return
+	}
+	if code >= http.StatusBadRequest {
+		return
+	}
+	getReq := req.Clone(req.Context())
+	getReq.Method = http.MethodGet
+	c.opt.Cache.Delete(req.Context(), c.opt.key(getReq))
+}
+
+func (c *cache) load(ctx context.Context, key string, req *http.Request) *entry {
+	var ent entry
+	has, err := c.opt.Cache.Get(ctx, key).Value(&ent)
+	if err != nil || !has || ent.Header == nil || !ent.matchVary(req) {
+		return nil
+	}
+	return &ent
+}
+
+func (c *cache) store(ctx context.Context, key string, ent *entry, now time.Time) {
+	if ttl := ent.storeTTL(now, c.opt); ttl > 0 {
+		c.opt.Cache.Set(ctx, key, *ent, ttl)
+	}
+}
+
+// storable 响应是否可以缓存
+func (c *cache) storable(req *http.Request, resp *http.Response) bool {
+	if !cacheableStatus[resp.StatusCode] {
+		return false
+	}
+	cc := parseCacheControl(resp.Header)
+	if cc.has("no-store") {
+		return false
+	}
+	// 事件流(SSE)是持续输出的，不能缓存
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	if c.shared {
+		if cc.has("private") {
+			return false
+		}
+		if req.Header.Get("Authorization") != "" && !cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate") {
+			return false
+		}
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// storeResponse 读取响应的 Body 并缓存，Body 超过 MaxBodySize 时不缓存
+func (c *cache) storeResponse(ctx context.Context, key string, req *http.Request, resp *http.Response, now time.Time) (*http.Response, error) {
+	limit := c.opt.getMaxBodySize()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Header.Set(HeaderCacheStatus, StatusMiss)
+	if int64(len(body)) > limit {
+		resp.Body = &readCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			Closer: resp.Body,
+		}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	c.store(ctx, key, newEntry(req, resp, body, now, c.shared), now)
+	return resp, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,293 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry 缓存的响应
+type entry struct {
+	Header http.Header `json:"header"`
+
+	// Vary 响应的 Vary 中的请求 Header 及其在请求中的值
+	Vary map[string]string `json:"vary,omitempty"`
+
+	Body []byte `json:"body"`
+
+	// Date 响应生成的时间，已扣除响应中的 Age，UnixNano
+	Date int64 `json:"date"`
+
+	// Lifetime 有效期
+	Lifetime time.Duration `json:"lifetime"`
+
+	Status int `json:"status"`
+}
+
+// hopHeaders 逐跳的 Header，不会缓存
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// cacheableStatus 默认可以缓存的状态码
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// serverErrors 可以使用 stale-if-error 的状态码
+var serverErrors = map[int]bool{
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+func newEntry(req *http.Request, resp *http.Response, body []byte, now time.Time, shared bool) *entry {
+	e := &entry{
+		Status: resp.StatusCode,
+		Header: cleanHeader(resp.Header, shared),
+		Body:   body,
+		Date:   now.UnixNano(),
+	}
+	if age, ok := headerSeconds(resp.Header, "Age"); ok {
+		e.Date -= int64(age)
+	}
+	e.Lifetime = lifetime(e.Header, shared)
+	for _, name := range varyHeaders(resp.Header) {
+		if e.Vary == nil {
+			e.Vary = make(map[string]string)
+		}
+		e.Vary[name] = strings.Join(req.Header.Values(name), ",")
+	}
+	return e
+}
+
+func cleanHeader(h http.Header, shared bool) http.Header {
+	h = h.Clone()
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+	for _, name := range h.Values("Connection") {
+		h.Del(name)
+	}
+	h.Del(HeaderCacheStatus)
+	if shared {
+		h.Del("Set-Cookie")
+	}
+	return h
+}
+
+// varyHeaders 响应的 Vary 中的 Header 名称，已规范化
+func varyHeaders(h http.Header) []string {
+	var names []string
+	for _, line := range h.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+func headerSeconds(h http.Header, name string) (time.Duration, bool) {
+	num, err := strconv.ParseInt(h.Get(name), 10, 64)
+	if err != nil || num < 0 {
+		return 0, false
+	}
+	return time.Duration(num) * time.Second, true
+}
+
+// lifetime 响应的有效期：s-maxage(仅共享缓存) > max-age > Expires - Date
+func lifetime(h http.Header, shared bool) time.Duration {
+	cc := parseCacheControl(h)
+	if shared {
+		if d, ok := cc.seconds("s-maxage"); ok {
+			return d
+		}
+	}
+	if d, ok := cc.seconds("max-age"); ok {
+		return d
+	}
+	if expires := h.Get("Expires"); expires != "" {
+		exp, err := http.ParseTime(expires)
+		if err != nil {
+			// 格式错误的 Expires 表示已过期
+			return 0
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			return 0
+		}
+		return max(exp.Sub(date), 0)
+	}
+	return 0
+}
+
+// hasValidator 是否可以通过 ETag 或者 Last-Modified 重新验证
+func (e *entry) hasValidator() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+func (e *entry) age(now time.Time) time.Duration {
+	return max(now.Sub(time.Unix(0, e.Date)), 0)
+}
+
+// matchVary 请求和缓存的响应的 Vary 是否匹配
+func (e *entry) matchVary(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if strings.Join(req.Header.Values(name), ",") != value {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh 是否可以不经过验证直接使用
+func (e *entry) fresh(now time.Time, reqCC directives, req *http.Request) bool {
+	if parseCacheControl(e.Header).has("no-cache") || reqCC.has("no-cache") {
+		return false
+	}
+	// 请求没有 Cache-Control 时，兼容 HTTP/1.0 的 "Pragma: no-cache"
+	if len(reqCC) == 0 && req.Header.Get("Pragma") == "no-cache" {
+		return false
+	}
+	age := e.age(now)
+	if d, ok := reqCC.seconds("max-age"); ok && age > d {
+		return false
+	}
+	if d, ok := reqCC.seconds("min-fresh"); ok {
+		age += d
+	}
+	if age < e.Lifetime {
+		return true
+	}
+	if parseCacheControl(e.Header).has("must-revalidate") {
+		return false
+	}
+	if reqCC.has("max-stale") {
+		d, ok := reqCC.seconds("max-stale")
+		// max-stale 没有值时，可以使用任意过期时长的缓存
+		return !ok || age < e.Lifetime+d
+	}
+	return false
+}
+
+// staleIfError 上游出错时，是否可以使用
+func (e *entry) staleIfError(now time.Time, reqCC directives, def time.Duration) bool {
+	cc := parseCacheControl(e.Header)
+	d, ok := cc.seconds("stale-if-error")
+	if !ok {
+		if cc.has("must-revalidate") || cc.has("proxy-revalidate") {
+			return false
+		}
+		if d, ok = reqCC.seconds("stale-if-error"); !ok {
+			d = def
+		}
+	}
+	return e.age(now) < e.Lifetime+d
+}
+
+// storeTTL 缓存的存储时长
+func (e *entry) storeTTL(now time.Time, opt *Option) time.Duration {
+	keep, _ := parseCacheControl(e.Header).seconds("stale-if-error")
+	keep = max(keep, opt.StaleIfError)
+	if e.hasValidator() {
+		keep = max(keep, opt.getKeepStale())
+	}
+	return e.Lifetime + keep - e.age(now)
+}
+
+// update 使用 304 响应的 Header 更新缓存
+// 缓存中的值可能是共享的(如 lrucache)，不能直接修改 Header
+func (e *entry) update(resp *http.Response, now time.Time, shared bool) {
+	e.Header = e.Header.Clone()
+	for name, values := range cleanHeader(resp.Header, shared) {
+		if name == "Content-Length" {
+			continue
+		}
+		e.Header[name] = values
+	}
+	e.Date = now.UnixNano()
+	if age, ok := headerSeconds(resp.Header, "Age"); ok {
+		e.Date -= int64(age)
+	}
+	e.Lifetime = lifetime(e.Header, shared)
+}
+
+// notModified 请求中的条件是否满足，满足时可以返回 304
+func (e *entry) notModified(req *http.Request) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := e.Header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	return err == nil && !lm.After(ims)
+}
+
+// response 使用缓存生成响应
+func (e *entry) response(req *http.Request, now time.Time, status string) *http.Response {
+	code := e.Status
+	body := e.Body
+	if e.Status == http.StatusOK && e.notModified(req) {
+		code = http.StatusNotModified
+		body = nil
+	}
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	if code == http.StatusNotModified {
+		resp.Header.Del("Content-Length")
+	} else {
+		resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	resp.Header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	resp.Header.Set(HeaderCacheStatus, status)
+	return resp
+}
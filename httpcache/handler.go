@@ -0,0 +1,243 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+)
+
+// NewHandler 创建一个会缓存 next 的响应的 http.Handler，作为共享缓存(忽略 Option.Shared)
+//
+// 缓存规则和 NewTransport 相同；next 返回 304 时会使用缓存重新验证，
+// 请求携带 If-None-Match 或者 If-Modified-Since 且缓存匹配时，返回 304。
+// next 的响应是流式返回的：不能缓存的响应会边执行边写入客户端，
+// 可以缓存的响应最多读取 Option.MaxBodySize，超过时不缓存，剩余的部分直接写入客户端
+func NewHandler(next http.Handler, opt *Option) (*Handler, error) {
+	if err := opt.Check(); err != nil {
+		return nil, err
+	}
+	return &Handler{
+		next:  next,
+		cache: &cache{opt: opt, shared: true},
+	}, nil
+}
+
+// Middleware 创建 NewHandler 的中间件，opt 不正确时会 panic
+func Middleware(opt *Option) func(next http.Handler) http.Handler {
+	if err := opt.Check(); err != nil {
+		panic(err)
+	}
+	return func(next http.Handler) http.Handler {
+		h, _ := NewHandler(next, opt)
+		return h
+	}
+}
+
+// Handler 会缓存响应的 http.Handler
+type Handler struct {
+	next  http.Handler
+	cache *cache
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		h.next.ServeHTTP(w, r)
+		return
+	default:
+		sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+		h.next.ServeHTTP(sw, r)
+		h.cache.invalidate(r, sw.code)
+		return
+	}
+	resp, err := h.cache.do(r, h.fetch)
+	if err != nil {
+		// 读取 next 的响应失败(如 next 在写入 Body 时 panic)，此时还没有写入任何数据
+		panic(http.ErrAbortHandler)
+	}
+	defer resp.Body.Close()
+	header := w.Header()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	if err = copyFlush(w, resp.Body); err != nil && !errors.Is(err, errWriteResponse) {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// errWriteResponse 写入客户端失败
+var errWriteResponse = errors.New("write response failed")
+
+// copyFlush 将 Body 写入 w，每次写入后都会 Flush，以支持流式的响应(如 SSE)
+func copyFlush(w http.ResponseWriter, body io.Reader) error {
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, we := w.Write(buf[:n]); we != nil {
+				return errWriteResponse
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fetch 在新的协程中执行 next，Header 确定后(第一次 Write、Flush 或者执行完成)即返回，
+// Body 通过 io.Pipe 边写边读，不会在内存中缓存完整的 Body
+func (h *Handler) fetch(req *http.Request) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	rec := &recorder{
+		header: make(http.Header),
+		pw:     pw,
+		ready:  make(chan struct{}),
+	}
+	go rec.serve(h.next, req)
+	<-rec.ready
+	if rec.panicValue != nil {
+		// 写入响应前 panic，在当前协程继续 panic，和直接执行 next 一致
+		panic(rec.panicValue)
+	}
+	return rec.response(req, pr), nil
+}
+
+// recorder 记录 next 的响应
+type recorder struct {
+	header http.Header
+	pw     *io.PipeWriter
+
+	// ready 响应的 Header 已确定
+	ready chan struct{}
+
+	// sent 确定时的 Header，之后修改 header 不再生效
+	sent http.Header
+
+	panicValue any
+	code       int
+	committed  bool
+}
+
+func (r *recorder) serve(next http.Handler, req *http.Request) {
+	defer func() {
+		if re := recover(); re != nil {
+			if !r.committed {
+				r.panicValue = re
+				r.pw.Close()
+				close(r.ready)
+				return
+			}
+			if re != http.ErrAbortHandler {
+				log.Printf("[httpcache] panic serving %s: %v\n%s", req.URL, re, debug.Stack())
+			}
+			r.pw.CloseWithError(fmt.Errorf("panic: %v", re))
+			return
+		}
+		r.commit(nil, true)
+		r.pw.Close()
+	}()
+	next.ServeHTTP(r, req)
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.commit(b, false)
+	return r.pw.Write(b)
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+}
+
+// Flush 实现 http.Flusher，Body 是直接写入 io.Pipe 的，只需要确定 Header
+func (r *recorder) Flush() {
+	r.commit(nil, false)
+}
+
+// commit 确定响应的 Header，first 为第一次写入的数据，用于检测 Content-Type
+// end 为 true 表示 next 已执行完成，且没有写入过数据
+func (r *recorder) commit(first []byte, end bool) {
+	if r.committed {
+		return
+	}
+	r.committed = true
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	r.sent = r.header.Clone()
+	if r.sent.Get("Content-Type") == "" && len(first) > 0 {
+		r.sent.Set("Content-Type", http.DetectContentType(first))
+	}
+	if end && r.code != http.StatusNotModified {
+		r.sent.Set("Content-Length", "0")
+	}
+	close(r.ready)
+}
+
+func (r *recorder) response(req *http.Request, body io.ReadCloser) *http.Response {
+	contentLength := int64(-1)
+	if cl, err := strconv.ParseInt(r.sent.Get("Content-Length"), 10, 64); err == nil {
+		contentLength = cl
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.code, http.StatusText(r.code)),
+		StatusCode:    r.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.sent,
+		Body:          body,
+		ContentLength: contentLength,
+		Request:       req,
+	}
+}
+
+// statusWriter 记录响应的状态码
+type statusWriter struct {
+	http.ResponseWriter
+	code  int
+	wrote bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wrote {
+		w.code = code
+		w.wrote = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap 用于 http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+var _ http.Handler = (*Handler)(nil)
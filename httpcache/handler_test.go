@@ -0,0 +1,164 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache/lrucache"
+)
+
+func TestHandler(t *testing.T) {
+	now := setNow(t)
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	up := newUpstream("public, max-age=10, s-maxage=60")
+	up.header.Set("Set-Cookie", "a=1")
+	ts := httptest.NewServer(Middleware(&Option{Cache: lc})(up))
+	defer ts.Close()
+	client := ts.Client()
+
+	resp, body := doGet(t, client, ts.URL+"/a", nil)
+	fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+	fst.Equal(t, "hello ", body)
+
+	t.Run("s-maxage", func(t *testing.T) {
+		*now = now.Add(30 * time.Second)
+		resp, body := doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, StatusHit, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello ", body)
+		fst.Equal(t, "30", resp.Header.Get("Age"))
+		fst.Equal(t, "", resp.Header.Get("Set-Cookie"))
+		fst.Equal(t, int64(1), up.calls.Load())
+	})
+
+	t.Run("client conditional", func(t *testing.T) {
+		resp, body := doGet(t, client, ts.URL+"/a", http.Header{"If-None-Match": {`"v1"`}})
+		fst.Equal(t, http.StatusNotModified, resp.StatusCode)
+		fst.Equal(t, "", body)
+		fst.Equal(t, int64(1), up.calls.Load())
+	})
+
+	t.Run("revalidate", func(t *testing.T) {
+		*now = now.Add(time.Minute)
+		resp, body := doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, StatusRevalidated, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, http.StatusOK, resp.StatusCode)
+		fst.Equal(t, "hello ", body)
+		fst.Equal(t, int64(2), up.calls.Load())
+	})
+
+	t.Run("private", func(t *testing.T) {
+		up.header.Set("Cache-Control", "private, max-age=60")
+		defer up.header.Set("Cache-Control", "public, max-age=10, s-maxage=60")
+		for i := 0; i < 2; i++ {
+			resp, _ := doGet(t, client, ts.URL+"/private", nil)
+			fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+		}
+	})
+
+	t.Run("delete invalidates", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/a", nil)
+		fst.NoError(t, err)
+		resp, err := client.Do(req)
+		fst.NoError(t, err)
+		resp.Body.Close()
+
+		resp, _ = doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+	})
+}
+
+func TestHandler_Host(t *testing.T) {
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	h := Middleware(&Option{Cache: lc})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		_, _ = io.WriteString(w, r.Host)
+	}))
+	get := func(host string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/p", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := get("a.example")
+	fst.Equal(t, "a.example", rec.Body.String())
+	rec = get("b.example")
+	fst.Equal(t, "b.example", rec.Body.String())
+	fst.Equal(t, StatusMiss, rec.Header().Get(HeaderCacheStatus))
+	rec = get("a.example")
+	fst.Equal(t, "a.example", rec.Body.String())
+	fst.Equal(t, StatusHit, rec.Header().Get(HeaderCacheStatus))
+}
+
+func TestHandler_Stream(t *testing.T) {
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+
+	t.Run("flush", func(t *testing.T) {
+		release := make(chan struct{})
+		h := Middleware(&Option{Cache: lc})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "data: 1\n\n")
+			w.(http.Flusher).Flush()
+			<-release
+			_, _ = io.WriteString(w, "data: 2\n\n")
+		}))
+		ts := httptest.NewServer(h)
+		defer ts.Close()
+
+		got := make(chan string, 1)
+		go func() {
+			resp, err := ts.Client().Get(ts.URL + "/sse")
+			if err != nil {
+				got <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			buf := make([]byte, len("data: 1\n\n"))
+			_, _ = io.ReadFull(resp.Body, buf)
+			got <- string(buf)
+		}()
+		select {
+		case msg := <-got:
+			fst.Equal(t, "data: 1\n\n", msg)
+		case <-time.After(time.Second):
+			t.Error("response is not streamed")
+		}
+		close(release)
+	})
+
+	t.Run("MaxBodySize", func(t *testing.T) {
+		var calls atomic.Int64
+		h := Middleware(&Option{Cache: lc, MaxBodySize: 4})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Cache-Control", "public, max-age=60")
+			_, _ = io.WriteString(w, "hello")
+			_, _ = io.WriteString(w, " world")
+		}))
+		for i := 0; i < 2; i++ {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/big", nil))
+			fst.Equal(t, "hello world", rec.Body.String())
+			fst.Equal(t, StatusMiss, rec.Header().Get(HeaderCacheStatus))
+		}
+		fst.Equal(t, int64(2), calls.Load())
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	fst.Panic(t, func() {
+		Middleware(&Option{})
+	})
+}
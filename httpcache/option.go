@@ -0,0 +1,138 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// HeaderCacheStatus 响应中表示缓存状态的 Header
+const HeaderCacheStatus = "X-Cache-Status"
+
+// 缓存状态，HeaderCacheStatus 的值
+const (
+	// StatusHit 缓存未过期，直接使用缓存
+	StatusHit = "HIT"
+
+	// StatusMiss 缓存不存在或者不可用，使用上游的响应
+	StatusMiss = "MISS"
+
+	// StatusRevalidated 缓存已过期，上游验证(304)后继续使用缓存
+	StatusRevalidated = "REVALIDATED"
+
+	// StatusStale 上游出错(stale-if-error)，使用已过期的缓存
+	StatusStale = "STALE"
+)
+
+var timeNow = time.Now
+
+// Option 缓存的配置
+type Option struct {
+	// Cache 存储响应的缓存，必填
+	Cache fscache.Cache
+
+	// KeyFunc 生成缓存 key 的方法，可选，默认为 "{Method} {URL}"
+	// 服务端收到的请求 URL 中没有 Host，默认会使用 req.Host 补全，以免不同域名的相同路径共用缓存
+	KeyFunc func(req *http.Request) string
+
+	// Shared 是否为共享缓存，可选
+	// 共享缓存会使用 s-maxage，并且不会存储 Cache-Control 为 private 或者请求包含 Authorization 的响应，
+	// 以及响应中的 Set-Cookie；NewHandler 创建的总是共享缓存
+	Shared bool
+
+	// KeepStale 缓存过期后继续保留的时长，用于重新验证(ETag/Last-Modified)和 stale-if-error，可选，默认为 1h
+	// 没有 ETag 和 Last-Modified 的响应，只会保留 stale-if-error 的时长
+	KeepStale time.Duration
+
+	// StaleIfError 默认的 stale-if-error 时长，可选
+	// 响应和请求的 Cache-Control 中的 stale-if-error 优先
+	StaleIfError time.Duration
+
+	// MaxBodySize 可以缓存的响应 Body 的最大长度，可选，默认为 10MB
+	MaxBodySize int64
+}
+
+// Check 检查配置是否正确
+func (o *Option) Check() error {
+	if o.Cache == nil {
+		return errors.New("option.Cache is nil")
+	}
+	return nil
+}
+
+func (o *Option) key(req *http.Request) string {
+	if o.KeyFunc != nil {
+		return o.KeyFunc(req)
+	}
+	u := req.URL
+	if u.Host == "" && req.Host != "" {
+		nu := *u
+		nu.Host = req.Host
+		if nu.Scheme == "" {
+			nu.Scheme = "http"
+			if req.TLS != nil {
+				nu.Scheme = "https"
+			}
+		}
+		u = &nu
+	}
+	return req.Method + " " + u.String()
+}
+
+func (o *Option) getKeepStale() time.Duration {
+	if o.KeepStale > 0 {
+		return o.KeepStale
+	}
+	return time.Hour
+}
+
+func (o *Option) getMaxBodySize() int64 {
+	if o.MaxBodySize > 0 {
+		return o.MaxBodySize
+	}
+	return 10 << 20
+}
+
+// directives Cache-Control 中的指令，名称为小写
+type directives map[string]string
+
+func parseCacheControl(h http.Header) directives {
+	cc := directives{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return cc
+}
+
+func (d directives) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// seconds 以秒为单位的指令值，不存在或者格式错误时返回 false
+func (d directives) seconds(name string) (time.Duration, bool) {
+	value, ok := d[name]
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || num < 0 {
+		return 0, false
+	}
+	return time.Duration(num) * time.Second, true
+}
@@ -0,0 +1,45 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"net/http"
+)
+
+// NewTransport 创建一个会缓存响应的 http.RoundTripper，base 为实际发送请求的 RoundTripper，为 nil 时使用 http.DefaultTransport
+//
+// 只会缓存 GET 请求，遵循 Cache-Control、Expires、Vary，过期后使用 ETag/Last-Modified 重新验证，
+// 上游出错(网络错误或者 500、502、503、504)时可以使用 stale-if-error 范围内的过期缓存；
+// 其他方法的请求成功后，会删除相同 URL 的 GET 请求的缓存。
+// 响应的 HeaderCacheStatus 为缓存的状态
+func NewTransport(base http.RoundTripper, opt *Option) (*Transport, error) {
+	if err := opt.Check(); err != nil {
+		return nil, err
+	}
+	return &Transport{
+		base:  base,
+		cache: &cache{opt: opt, shared: opt.Shared},
+	}, nil
+}
+
+// Transport 会缓存响应的 http.RoundTripper
+type Transport struct {
+	base  http.RoundTripper
+	cache *cache
+}
+
+func (t *Transport) getBase() http.RoundTripper {
+	if t.base != nil {
+		return t.base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.cache.do(req, t.getBase().RoundTrip)
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
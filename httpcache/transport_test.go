@@ -0,0 +1,225 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/filecache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+func setNow(t *testing.T) *time.Time {
+	now := time.Now()
+	timeNow = func() time.Time {
+		return now
+	}
+	t.Cleanup(func() {
+		timeNow = time.Now
+	})
+	return &now
+}
+
+// upstream 测试用的上游服务，fail 为 true 时返回 503
+type upstream struct {
+	header http.Header
+	body   string
+	calls  atomic.Int64
+	fail   atomic.Bool
+}
+
+func newUpstream(cc string) *upstream {
+	return &upstream{
+		header: http.Header{"Cache-Control": {cc}, "Etag": {`"v1"`}},
+		body:   "hello",
+	}
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.calls.Add(1)
+	if u.fail.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	for k, v := range u.header {
+		w.Header()[k] = v
+	}
+	if inm := r.Header.Get("If-None-Match"); r.Method == http.MethodGet && inm != "" && inm == u.header.Get("Etag") {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	_, _ = io.WriteString(w, u.body+" "+r.Header.Get("Accept-Language"))
+}
+
+func doGet(t *testing.T, c *http.Client, url string, header http.Header) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	fst.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := c.Do(req)
+	fst.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	fst.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestTransport(t *testing.T) {
+	fc, err := filecache.New(&filecache.Option{Dir: filepath.Join(t.TempDir(), "data")})
+	fst.NoError(t, err)
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	caches := map[string]fscache.Cache{
+		"filecache": fc,
+		"lrucache":  lc,
+	}
+	for name, store := range caches {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			testTransport(t, store)
+		})
+	}
+}
+
+func testTransport(t *testing.T, store fscache.Cache) {
+	now := setNow(t)
+	up := newUpstream("max-age=60, stale-if-error=300")
+	ts := httptest.NewServer(up)
+	defer ts.Close()
+
+	tr, err := NewTransport(nil, &Option{Cache: store})
+	fst.NoError(t, err)
+	client := &http.Client{Transport: tr}
+
+	resp, body := doGet(t, client, ts.URL+"/a", nil)
+	fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+	fst.Equal(t, "hello ", body)
+
+	resp, body = doGet(t, client, ts.URL+"/a", nil)
+	fst.Equal(t, StatusHit, resp.Header.Get(HeaderCacheStatus))
+	fst.Equal(t, http.StatusOK, resp.StatusCode)
+	fst.Equal(t, "hello ", body)
+	fst.Equal(t, int64(1), up.calls.Load())
+
+	t.Run("request no-cache", func(t *testing.T) {
+		resp, body := doGet(t, client, ts.URL+"/a", http.Header{"Cache-Control": {"no-cache"}})
+		fst.Equal(t, StatusRevalidated, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello ", body)
+		fst.Equal(t, int64(2), up.calls.Load())
+	})
+
+	t.Run("revalidate", func(t *testing.T) {
+		*now = now.Add(61 * time.Second)
+		resp, body := doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, StatusRevalidated, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello ", body)
+		fst.Equal(t, int64(3), up.calls.Load())
+
+		resp, _ = doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, StatusHit, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, int64(3), up.calls.Load())
+	})
+
+	t.Run("stale-if-error", func(t *testing.T) {
+		up.fail.Store(true)
+		defer up.fail.Store(false)
+
+		*now = now.Add(120 * time.Second)
+		resp, body := doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, StatusStale, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello ", body)
+
+		*now = now.Add(time.Hour)
+		resp, _ = doGet(t, client, ts.URL+"/a", nil)
+		fst.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		up.header.Set("Vary", "Accept-Language")
+		defer up.header.Del("Vary")
+		zh := http.Header{"Accept-Language": {"zh"}}
+		en := http.Header{"Accept-Language": {"en"}}
+
+		resp, body := doGet(t, client, ts.URL+"/vary", zh)
+		fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello zh", body)
+		resp, _ = doGet(t, client, ts.URL+"/vary", zh)
+		fst.Equal(t, StatusHit, resp.Header.Get(HeaderCacheStatus))
+
+		resp, body = doGet(t, client, ts.URL+"/vary", en)
+		fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello en", body)
+	})
+
+	t.Run("no-store", func(t *testing.T) {
+		up.header.Set("Cache-Control", "no-store")
+		defer up.header.Set("Cache-Control", "max-age=60, stale-if-error=300")
+		for i := 0; i < 2; i++ {
+			resp, _ := doGet(t, client, ts.URL+"/no-store", nil)
+			fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+		}
+	})
+
+	t.Run("unsafe method invalidates", func(t *testing.T) {
+		doGet(t, client, ts.URL+"/b", nil)
+		resp, _ := doGet(t, client, ts.URL+"/b", nil)
+		fst.Equal(t, StatusHit, resp.Header.Get(HeaderCacheStatus))
+
+		resp, err := client.Post(ts.URL+"/b", "text/plain", strings.NewReader("x"))
+		fst.NoError(t, err)
+		resp.Body.Close()
+
+		resp, _ = doGet(t, client, ts.URL+"/b", nil)
+		fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+	})
+
+	t.Run("client conditional", func(t *testing.T) {
+		up.header.Del("Etag")
+		doGet(t, client, ts.URL+"/cond", nil)
+		up.header.Set("Etag", `"v1"`)
+
+		*now = now.Add(61 * time.Second)
+		resp, _ := doGet(t, client, ts.URL+"/cond", http.Header{"If-None-Match": {`"v1"`}})
+		fst.Equal(t, http.StatusNotModified, resp.StatusCode)
+		fst.Equal(t, StatusMiss, resp.Header.Get(HeaderCacheStatus))
+
+		// 缓存没有被删除，上游出错时依然可以使用
+		up.fail.Store(true)
+		defer up.fail.Store(false)
+		resp, body := doGet(t, client, ts.URL+"/cond", nil)
+		fst.Equal(t, StatusStale, resp.Header.Get(HeaderCacheStatus))
+		fst.Equal(t, "hello ", body)
+	})
+
+	t.Run("key func", func(t *testing.T) {
+		tr2, err := NewTransport(nil, &Option{
+			Cache: store,
+			KeyFunc: func(req *http.Request) string {
+				return "path:" + req.URL.Path
+			},
+		})
+		fst.NoError(t, err)
+		c2 := &http.Client{Transport: tr2}
+		doGet(t, c2, ts.URL+"/c?a=1", nil)
+		resp, _ := doGet(t, c2, ts.URL+"/c?a=2", nil)
+		fst.Equal(t, StatusHit, resp.Header.Get(HeaderCacheStatus))
+	})
+}
+
+func TestNewTransport(t *testing.T) {
+	_, err := NewTransport(nil, &Option{})
+	fst.Error(t, err)
+}
@@ -0,0 +1,244 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package invalidate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// Option New 的参数
+type Option struct {
+	// Transport 传输失效消息的通道，必填
+	Transport Transport
+
+	// ParseKey 将消息中的 key 转换为本地缓存的 key，可选
+	// 消息中的 key 为 fmt.Sprint(key)，默认直接使用该字符串，若本地缓存的 key 不是 string 类型，需要设置
+	ParseKey func(key string) (any, error)
+
+	// OnError 发送或者处理失效消息失败的回调，可选，默认会打印日志
+	OnError func(err error)
+
+	// NodeID 当前节点的 ID，可选，默认为随机值
+	// 收到自己发送的消息时会忽略
+	NodeID string
+
+	// MaxMessageSize 一条消息的最大长度，可选，默认为 60000
+	// 一次失效的 key 较多时，会拆分为多条消息发送
+	MaxMessageSize int
+}
+
+// Check 检查配置是否正确
+func (o *Option) Check() error {
+	if o.Transport == nil {
+		return errors.New("option.Transport is nil")
+	}
+	if o.MaxMessageSize < 0 {
+		return fmt.Errorf("option.MaxMessageSize=%d, expect >= 0", o.MaxMessageSize)
+	}
+	return nil
+}
+
+func (o *Option) getMaxMessageSize() int {
+	if o.MaxMessageSize > 0 {
+		return o.MaxMessageSize
+	}
+	return 60000
+}
+
+func (o *Option) parseKey(key string) (any, error) {
+	if o.ParseKey != nil {
+		return o.ParseKey(key)
+	}
+	return key, nil
+}
+
+func (o *Option) onError(err error) {
+	if o.OnError != nil {
+		o.OnError(err)
+		return
+	}
+	log.Println("[invalidate][warn]", err)
+}
+
+// message 失效消息
+type message struct {
+	Source string   `json:"src"`
+	Keys   []string `json:"keys"`
+}
+
+// New 封装本地缓存，创建一个会跨进程失效的缓存
+//
+// 通过返回的缓存 Set、MSet、Delete、MDelete 时，会在本地操作完成后，通过 Transport 发送 key 的失效消息；
+// 其他节点收到消息后，会从各自的本地缓存中删除这些 key。
+// 使用完后需要调用 Close 取消订阅
+func New(local fscache.Cache, opt *Option) (*Cache, error) {
+	if err := opt.Check(); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		Cache:  local,
+		opt:    opt,
+		nodeID: opt.NodeID,
+	}
+	if c.nodeID == "" {
+		c.nodeID = randomID()
+	}
+	var sub io.Closer
+	var err error
+	if es, ok := opt.Transport.(ErrorSubscriber); ok {
+		sub, err = es.SubscribeWithError(c.receive, opt.onError)
+	} else {
+		sub, err = opt.Transport.Subscribe(c.receive)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.sub = sub
+	return c, nil
+}
+
+func randomID() string {
+	bf := make([]byte, 8)
+	_, _ = rand.Read(bf)
+	return hex.EncodeToString(bf)
+}
+
+// Cache 会跨进程失效的缓存
+type Cache struct {
+	fscache.Cache
+	opt    *Option
+	sub    io.Closer
+	nodeID string
+}
+
+// NodeID 当前节点的 ID
+func (c *Cache) NodeID() string {
+	return c.nodeID
+}
+
+// Set 写入本地缓存，并通知其他节点失效该 key
+func (c *Cache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	ret := c.Cache.Set(ctx, key, value, ttl)
+	c.publish(ctx, key)
+	return ret
+}
+
+// MSet 批量写入本地缓存，并通知其他节点失效这些 key
+func (c *Cache) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	ret := c.Cache.MSet(ctx, kvs, ttl)
+	keys := make([]any, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	c.publish(ctx, keys...)
+	return ret
+}
+
+// Delete 删除本地缓存，并通知其他节点失效该 key
+func (c *Cache) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	ret := c.Cache.Delete(ctx, key)
+	c.publish(ctx, key)
+	return ret
+}
+
+// MDelete 批量删除本地缓存，并通知其他节点失效这些 key
+func (c *Cache) MDelete(ctx context.Context, keys []any) fscache.MDeleteResult {
+	ret := c.Cache.MDelete(ctx, keys)
+	c.publish(ctx, keys...)
+	return ret
+}
+
+// Invalidate 通知其他节点失效这些 key，不会修改本地缓存
+func (c *Cache) Invalidate(ctx context.Context, keys ...any) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, msg := range c.encode(keys) {
+		if err := c.opt.Transport.Publish(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Cache) publish(ctx context.Context, keys ...any) {
+	if ctx.Err() != nil {
+		// ctx 取消时，本地缓存也没有修改
+		return
+	}
+	if err := c.Invalidate(ctx, keys...); err != nil {
+		c.opt.onError(fmt.Errorf("publish invalidation: %w", err))
+	}
+}
+
+// encode 将 keys 编码为消息，每条消息的长度不超过 MaxMessageSize
+func (c *Cache) encode(keys []any) [][]byte {
+	var result [][]byte
+	msg := message{Source: c.nodeID}
+	// 消息的长度：固定部分 + 每个 key 的长度(包含引号、逗号和可能的转义)
+	base := len(c.nodeID) + 32
+	size := base
+	flush := func() {
+		if len(msg.Keys) == 0 {
+			return
+		}
+		bf, _ := json.Marshal(msg)
+		result = append(result, bf)
+		msg.Keys = nil
+		size = base
+	}
+	for _, k := range keys {
+		str := fmt.Sprint(k)
+		bf, _ := json.Marshal(str)
+		if size+len(bf)+1 > c.opt.getMaxMessageSize() {
+			flush()
+		}
+		msg.Keys = append(msg.Keys, str)
+		size += len(bf) + 1
+	}
+	flush()
+	return result
+}
+
+func (c *Cache) receive(data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.opt.onError(fmt.Errorf("decode invalidation: %w", err))
+		return
+	}
+	if msg.Source == c.nodeID || len(msg.Keys) == 0 {
+		return
+	}
+	keys := make([]any, 0, len(msg.Keys))
+	for _, str := range msg.Keys {
+		key, err := c.opt.parseKey(str)
+		if err != nil {
+			c.opt.onError(fmt.Errorf("parse key %q: %w", str, err))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if err := c.Cache.MDelete(context.Background(), keys).Err(); err != nil {
+		c.opt.onError(fmt.Errorf("evict: %w", err))
+	}
+}
+
+// Close 取消订阅，不会关闭本地缓存
+func (c *Cache) Close() error {
+	return c.sub.Close()
+}
+
+var _ fscache.Cache = (*Cache)(nil)
@@ -0,0 +1,258 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package invalidate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/cachetest"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+func newNode(t *testing.T, tp Transport, opt *Option) (*Cache, fscache.Cache) {
+	local, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	if opt == nil {
+		opt = &Option{}
+	}
+	opt.Transport = tp
+	opt.OnError = func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	c, err := New(local, opt)
+	fst.NoError(t, err)
+	t.Cleanup(func() {
+		fst.NoError(t, c.Close())
+	})
+	return c, local
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	lb := NewLoopback()
+	a, _ := newNode(t, lb, nil)
+	b, localB := newNode(t, lb, nil)
+	fst.NotEqual(t, a.NodeID(), b.NodeID())
+	cachetest.CacheTest(t, a, "invalidate")
+
+	fst.NoError(t, localB.Set(ctx, "k1", "old", time.Minute).Err)
+	fst.NoError(t, a.Set(ctx, "k1", "new", time.Minute).Err)
+	fst.ErrorIs(t, localB.Get(ctx, "k1").Err, fscache.ErrNotExists)
+
+	// 自己发送的消息不会删除本地缓存
+	var got string
+	has, err := a.Get(ctx, "k1").Value(&got)
+	fst.NoError(t, err)
+	fst.True(t, has)
+	fst.Equal(t, "new", got)
+
+	fst.NoError(t, b.Set(ctx, "k2", "v2", time.Minute).Err)
+	fst.NoError(t, a.MDelete(ctx, []any{"k2"}).Err())
+	fst.ErrorIs(t, b.Get(ctx, "k2").Err, fscache.ErrNotExists)
+
+	fst.NoError(t, b.Set(ctx, "k3", "v3", time.Minute).Err)
+	fst.NoError(t, a.Invalidate(ctx, "k3"))
+	fst.ErrorIs(t, b.Get(ctx, "k3").Err, fscache.ErrNotExists)
+}
+
+func TestCache_ParseKey(t *testing.T) {
+	ctx := context.Background()
+	lb := NewLoopback()
+	opt := &Option{
+		ParseKey: func(key string) (any, error) {
+			return strconv.Atoi(key)
+		},
+		MaxMessageSize: 100,
+	}
+	a, _ := newNode(t, lb, nil)
+	_, localB := newNode(t, lb, opt)
+
+	var msgs int
+	sub, err := lb.Subscribe(func(msg []byte) {
+		fst.LessOrEqual(t, len(msg), 100)
+		msgs++
+	})
+	fst.NoError(t, err)
+	defer sub.Close()
+
+	kvs := fscache.KVData{}
+	var keys []any
+	for i := 0; i < 50; i++ {
+		kvs[i] = i
+		keys = append(keys, i)
+	}
+	fst.NoError(t, localB.MSet(ctx, kvs, time.Minute).Err())
+	a.opt.MaxMessageSize = 100
+	fst.NoError(t, a.MDelete(ctx, keys).Err())
+	fst.Greater(t, msgs, 1)
+	for _, k := range keys {
+		fst.ErrorIs(t, localB.Get(ctx, k).Err, fscache.ErrNotExists)
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	testTransport(t, &UnixSocket{Dir: dir, Name: "a"}, &UnixSocket{Dir: dir, Name: "b"})
+
+	t.Run("default name", func(t *testing.T) {
+		dir := t.TempDir()
+		tp := &UnixSocket{Dir: dir}
+		received := make(chan string, 10)
+		sub1, err := tp.Subscribe(func(msg []byte) {
+			received <- "1:" + string(msg)
+		})
+		fst.NoError(t, err)
+		sub2, err := tp.Subscribe(func(msg []byte) {
+			received <- "2:" + string(msg)
+		})
+		fst.NoError(t, err)
+		defer sub2.Close()
+
+		fst.NoError(t, tp.Publish(context.Background(), []byte("a")))
+		var got []string
+		for i := 0; i < 2; i++ {
+			select {
+			case msg := <-received:
+				got = append(got, msg)
+			case <-time.After(time.Second):
+				t.Fatal("message not received")
+			}
+		}
+		sort.Strings(got)
+		fst.Equal(t, []string{"1:a", "2:a"}, got)
+
+		// 多次 Close 不会 panic，也不会影响其他的订阅
+		fst.NoError(t, sub1.Close())
+		fst.NoError(t, sub1.Close())
+		fst.NoError(t, tp.Publish(context.Background(), []byte("b")))
+		select {
+		case msg := <-received:
+			fst.Equal(t, "2:b", msg)
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	})
+
+	t.Run("stuck subscriber", func(t *testing.T) {
+		dir := t.TempDir()
+		// 不读取消息的订阅者
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dir, "stuck.sock"), Net: "unixgram"})
+		fst.NoError(t, err)
+		defer conn.Close()
+
+		tp := &UnixSocket{Dir: dir}
+		msg := make([]byte, 32<<10)
+		for i := 0; i < 1000; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			start := time.Now()
+			err = tp.Publish(ctx, msg)
+			cancel()
+			if err != nil {
+				fst.ErrorIs(t, err, os.ErrDeadlineExceeded)
+				fst.Less(t, time.Since(start), time.Second)
+				return
+			}
+		}
+		t.Fatal("socket buffer is never full")
+	})
+}
+
+func TestUDPMulticast(t *testing.T) {
+	addr := "239.255.77.77:19527"
+	closed := &UDPMulticast{Addr: addr}
+	fst.NoError(t, closed.Close())
+	fst.ErrorIs(t, closed.Publish(context.Background(), []byte("1")), ErrClosed)
+
+	tp := &UDPMulticast{Addr: addr}
+	defer tp.Close()
+	sub, err := tp.Subscribe(func(msg []byte) {})
+	if err != nil {
+		t.Skipf("multicast not supported: %v", err)
+	}
+	_ = sub.Close()
+	testTransport(t, tp, &UDPMulticast{Addr: addr})
+}
+
+func TestPacketSub(t *testing.T) {
+	conn := &errPacketConn{closed: make(chan struct{})}
+	var mu sync.Mutex
+	var errs []error
+	sub := subscribePacket(conn, func(msg []byte) {}, func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}, nil)
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	fst.NoError(t, sub.Close())
+	fst.Less(t, time.Since(start), 100*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 重试间隔逐渐增加，200ms 内不会超过 10 次
+	fst.Greater(t, len(errs), 1)
+	fst.Less(t, len(errs), 10)
+	fst.ErrorIs(t, errs[0], errRead)
+}
+
+var errRead = errors.New("read failed")
+
+// errPacketConn 读取总是失败，直到 Close
+type errPacketConn struct {
+	net.PacketConn
+	closed chan struct{}
+}
+
+func (c *errPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	select {
+	case <-c.closed:
+		return 0, nil, net.ErrClosed
+	default:
+		return 0, nil, errRead
+	}
+}
+
+func (c *errPacketConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func testTransport(t *testing.T, ta Transport, tb Transport) {
+	ctx := context.Background()
+	received := make(chan []byte, 10)
+	sub, err := tb.Subscribe(func(msg []byte) {
+		received <- msg
+	})
+	fst.NoError(t, err)
+	defer sub.Close()
+
+	for i := 0; i < 3; i++ {
+		fst.NoError(t, ta.Publish(ctx, []byte(fmt.Sprint(i))))
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-received:
+			fst.Equal(t, fmt.Sprint(i), string(msg))
+		case <-time.After(time.Second):
+			if _, ok := ta.(*UDPMulticast); ok {
+				t.Skip("multicast message not received")
+			}
+			t.Fatal("message not received")
+		}
+	}
+}
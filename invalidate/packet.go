@@ -0,0 +1,273 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package invalidate
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxPacketSize 一个数据包的最大长度
+const maxPacketSize = 64 << 10
+
+// 读取消息失败后，重试的间隔时间，连续失败时逐渐增加
+const (
+	minReadBackoff = 10 * time.Millisecond
+	maxReadBackoff = time.Second
+)
+
+// packetSub 从 conn 中读取消息的订阅
+type packetSub struct {
+	conn    net.PacketConn
+	stop    chan struct{}
+	done    chan struct{}
+	onError func(err error)
+	onClose func()
+	once    sync.Once
+}
+
+func subscribePacket(conn net.PacketConn, fn func(msg []byte), onError func(err error), onClose func()) io.Closer {
+	s := &packetSub{
+		conn:    conn,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		onError: onError,
+		onClose: onClose,
+	}
+	go s.loop(fn)
+	return s
+}
+
+func (s *packetSub) loop(fn func(msg []byte)) {
+	defer close(s.done)
+	buf := make([]byte, maxPacketSize)
+	var backoff time.Duration
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if err == nil {
+			backoff = 0
+			fn(append([]byte(nil), buf[:n]...))
+			continue
+		}
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		s.reportError(err)
+		backoff = min(max(backoff*2, minReadBackoff), maxReadBackoff)
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (s *packetSub) reportError(err error) {
+	err = fmt.Errorf("invalidate: read message failed: %w", err)
+	if s.onError != nil {
+		s.onError(err)
+		return
+	}
+	log.Println("[invalidate][warn]", err)
+}
+
+// Close 取消订阅，可以多次调用
+func (s *packetSub) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		err = s.conn.Close()
+		<-s.done
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+	return err
+}
+
+// UDPMulticast 使用 UDP 组播传输消息，适用于同一个局域网内的多个节点
+type UDPMulticast struct {
+	// Interface 组播使用的网卡，可选，为 nil 时使用系统默认的网卡
+	Interface *net.Interface
+
+	// Addr 组播地址，必填，如 "239.0.0.1:9527"
+	Addr string
+
+	conn   net.Conn
+	closed bool
+	mu     sync.Mutex
+}
+
+// Publish 发送消息，消息长度不能超过 64KB，Close 之后调用会返回 ErrClosed
+func (u *UDPMulticast) Publish(ctx context.Context, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.closed {
+		return ErrClosed
+	}
+	if u.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "udp", u.Addr)
+		if err != nil {
+			return err
+		}
+		u.conn = conn
+	}
+	_, err := u.conn.Write(msg)
+	return err
+}
+
+// Subscribe 加入组播组并订阅消息
+func (u *UDPMulticast) Subscribe(fn func(msg []byte)) (io.Closer, error) {
+	return u.SubscribeWithError(fn, nil)
+}
+
+// SubscribeWithError 加入组播组并订阅消息，接收消息失败时调用 onError
+func (u *UDPMulticast) SubscribeWithError(fn func(msg []byte), onError func(err error)) (io.Closer, error) {
+	addr, err := net.ResolveUDPAddr("udp", u.Addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp", u.Interface, addr)
+	if err != nil {
+		return nil, err
+	}
+	return subscribePacket(conn, fn, onError, nil), nil
+}
+
+// Close 关闭用于发送消息的连接，不会影响已有的订阅
+func (u *UDPMulticast) Close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.closed = true
+	if u.conn == nil {
+		return nil
+	}
+	err := u.conn.Close()
+	u.conn = nil
+	return err
+}
+
+// unixSocketExt UnixSocket 的 socket 文件的后缀
+const unixSocketExt = ".sock"
+
+// UnixSocket 使用 Unix Domain Socket(unixgram) 传输消息，适用于同一台机器上的多个进程
+//
+// 每个订阅者在 Dir 目录下创建一个 socket 文件，Publish 时会向该目录下所有的 socket 文件发送消息
+type UnixSocket struct {
+	// Dir socket 文件所在的目录，必填，所有的进程需要使用相同的目录
+	Dir string
+
+	// Name socket 文件名，可选，默认为 "{pid}-{随机数}"，每次订阅都不同
+	// 同一个目录下不能重复，设置后只能订阅一次
+	Name string
+}
+
+// sendTimeout ctx 没有截止时间时，Publish 发送消息的超时时间
+const sendTimeout = time.Second
+
+func (u *UnixSocket) path() (string, error) {
+	name := u.Name
+	if name == "" {
+		var rnd [4]byte
+		if _, err := rand.Read(rnd[:]); err != nil {
+			return "", err
+		}
+		name = fmt.Sprintf("%d-%x", os.Getpid(), rnd)
+	}
+	return filepath.Join(u.Dir, name+unixSocketExt), nil
+}
+
+// Publish 向 Dir 目录下所有的 socket 文件发送消息，消息长度不能超过 64KB
+// 若 socket 文件对应的进程已经退出，会删除该文件；
+// 发送的截止时间为 ctx 的截止时间，没有时为 1 秒，以免订阅者不读取消息时阻塞写入
+func (u *UnixSocket) Publish(ctx context.Context, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(sendTimeout)
+	}
+	entries, err := os.ReadDir(u.Dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), unixSocketExt) {
+			continue
+		}
+		fp := filepath.Join(u.Dir, e.Name())
+		err = u.send(fp, msg, deadline)
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			_ = os.Remove(fp)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fp, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (u *UnixSocket) send(fp string, msg []byte, deadline time.Time) error {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: fp, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err = conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	_, err = conn.Write(msg)
+	return err
+}
+
+// Subscribe 在 Dir 目录下创建 socket 文件并订阅消息，取消订阅时会删除该文件
+func (u *UnixSocket) Subscribe(fn func(msg []byte)) (io.Closer, error) {
+	return u.SubscribeWithError(fn, nil)
+}
+
+// SubscribeWithError 和 Subscribe 相同，接收消息失败时调用 onError
+func (u *UnixSocket) SubscribeWithError(fn func(msg []byte), onError func(err error)) (io.Closer, error) {
+	if err := os.MkdirAll(u.Dir, 0755); err != nil {
+		return nil, err
+	}
+	fp, err := u.path()
+	if err != nil {
+		return nil, err
+	}
+	if u.Name != "" {
+		// 上次运行时未删除的文件
+		_ = os.Remove(fp)
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: fp, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return subscribePacket(conn, fn, onError, func() {
+		_ = os.Remove(fp)
+	}), nil
+}
+
+var _ Transport = (*UDPMulticast)(nil)
+var _ Transport = (*UnixSocket)(nil)
+var _ ErrorSubscriber = (*UDPMulticast)(nil)
+var _ ErrorSubscriber = (*UnixSocket)(nil)
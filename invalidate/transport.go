@@ -0,0 +1,88 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package invalidate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Transport 传输失效消息的通道
+// 可以使用 Loopback、UDPMulticast、UnixSocket，或者自行实现(如基于 Redis 的 Pub/Sub)
+type Transport interface {
+	// Publish 向所有的订阅者(包括自己)发送消息
+	Publish(ctx context.Context, msg []byte) error
+
+	// Subscribe 订阅消息，收到消息时调用 fn，返回的 io.Closer 用于取消订阅
+	// fn 可能会被并发调用
+	Subscribe(fn func(msg []byte)) (io.Closer, error)
+}
+
+// ErrorSubscriber 可选接口，订阅时可以传入接收消息失败的回调
+// New 时若 Transport 实现了该接口，接收消息失败时会调用 Option.OnError
+type ErrorSubscriber interface {
+	// SubscribeWithError 和 Subscribe 相同，接收消息失败时调用 onError，onError 为 nil 时打印日志
+	SubscribeWithError(fn func(msg []byte), onError func(err error)) (io.Closer, error)
+}
+
+// ErrClosed Transport 已经关闭
+var ErrClosed = errors.New("invalidate: transport closed")
+
+// NewLoopback 创建一个进程内的 Transport，Publish 时会同步的调用所有订阅者
+// 用于同一个进程内的多个缓存，或者测试
+func NewLoopback() *Loopback {
+	return &Loopback{
+		subs: make(map[*loopbackSub]struct{}),
+	}
+}
+
+// Loopback 进程内的 Transport
+type Loopback struct {
+	subs map[*loopbackSub]struct{}
+	mu   sync.RWMutex
+}
+
+type loopbackSub struct {
+	lb *Loopback
+	fn func(msg []byte)
+}
+
+func (s *loopbackSub) Close() error {
+	s.lb.mu.Lock()
+	delete(s.lb.subs, s)
+	s.lb.mu.Unlock()
+	return nil
+}
+
+// Publish 发送消息，会同步的调用所有订阅者
+func (lb *Loopback) Publish(ctx context.Context, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	lb.mu.RLock()
+	subs := make([]*loopbackSub, 0, len(lb.subs))
+	for s := range lb.subs {
+		subs = append(subs, s)
+	}
+	lb.mu.RUnlock()
+	for _, s := range subs {
+		// 订阅者可能会修改消息内容
+		s.fn(append([]byte(nil), msg...))
+	}
+	return nil
+}
+
+// Subscribe 订阅消息
+func (lb *Loopback) Subscribe(fn func(msg []byte)) (io.Closer, error) {
+	s := &loopbackSub{lb: lb, fn: fn}
+	lb.mu.Lock()
+	lb.subs[s] = struct{}{}
+	lb.mu.Unlock()
+	return s, nil
+}
+
+var _ Transport = (*Loopback)(nil)
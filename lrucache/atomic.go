@@ -0,0 +1,76 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package lrucache
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// lookup 查找未过期的值，需要在加锁后调用
+func (L *SCache) lookup(key any) *value {
+	el, has := L.data[key]
+	if !has {
+		return nil
+	}
+	if val := el.Value.(*value); !val.Expired() {
+		return val
+	}
+	return nil
+}
+
+// Incr 将 key 的整数值加上 delta，计算后的值和原值的类型相同
+// key 不存在时以 int64(0) 为初始值，缓存值为 fscache.RawValue 时使用 Option.Codec 解码
+func (L *SCache) Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	L.lock.Lock()
+	defer L.lock.Unlock()
+	old := L.lookup(key)
+	if old == nil {
+		L.set(key, delta, time.Now().Add(ttl))
+		return delta, nil
+	}
+	nv, num, err := fscache.AddInt(old.Data, delta, L.opt.GetCodec())
+	if err != nil {
+		return 0, err
+	}
+	L.set(key, nv, old.ExpireAt)
+	return num, nil
+}
+
+// SetNX 当 key 不存在时写入
+func (L *SCache) SetNX(ctx context.Context, key any, val any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	L.lock.Lock()
+	defer L.lock.Unlock()
+	if L.lookup(key) != nil {
+		return false, nil
+	}
+	L.set(key, val, time.Now().Add(ttl))
+	return true, nil
+}
+
+// CompareAndSwap 当 key 存在且版本号和 cas 一致时写入
+func (L *SCache) CompareAndSwap(ctx context.Context, key any, cas uint64, val any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	L.lock.Lock()
+	defer L.lock.Unlock()
+	old := L.lookup(key)
+	if old == nil || old.CAS != cas {
+		return false, nil
+	}
+	L.set(key, val, time.Now().Add(ttl))
+	return true, nil
+}
+
+var _ fscache.Atomic = (*SCache)(nil)
@@ -5,13 +5,17 @@
 package lrucache
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
 
 	"github.com/fsgo/fst"
 
+	"github.com/fsgo/fscache"
 	"github.com/fsgo/fscache/cachetest"
+	"github.com/fsgo/fscache/filecache"
+	"github.com/fsgo/fscache/filecache/cachefs"
 )
 
 func TestLRUCache(t *testing.T) {
@@ -20,6 +24,8 @@ func TestLRUCache(t *testing.T) {
 	})
 
 	cachetest.CacheTest(t, c, "lruCache")
+	cachetest.ScanTest(t, c, "lruCache")
+	cachetest.AtomicTest(t, c, "lruCache")
 }
 
 func TestLRUCache2(t *testing.T) {
@@ -33,6 +39,52 @@ func TestLRUCache2(t *testing.T) {
 	}
 }
 
+func TestLRUCache_Snapshot(t *testing.T) {
+	newCache := func() fscache.Cache {
+		c, err := New(&Option{Capacity: 100})
+		fst.NoError(t, err)
+		return c
+	}
+	t.Run("lru to lru", func(t *testing.T) {
+		cachetest.SnapshotTest(t, newCache(), newCache(), "lru")
+	})
+
+	t.Run("lru to filecache", func(t *testing.T) {
+		fc, err := filecache.New(&filecache.Option{FS: cachefs.NewMem(), StoreKey: true})
+		fst.NoError(t, err)
+		cachetest.SnapshotTest(t, newCache(), fc, "lru")
+	})
+
+	t.Run("keep order", func(t *testing.T) {
+		ctx := context.Background()
+		src, err := New(&Option{Capacity: 3})
+		fst.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			fst.NoError(t, src.Set(ctx, i, i, time.Minute).Err)
+		}
+		// 0 成为最近使用的
+		fst.NoError(t, src.Get(ctx, 0).Err)
+
+		dumper, ok := fscache.As[fscache.Dumper](src)
+		fst.True(t, ok)
+		buf := &bytes.Buffer{}
+		fst.NoError(t, dumper.Dump(ctx, buf))
+		dst, err := New(&Option{Capacity: 3})
+		fst.NoError(t, err)
+		loader, ok := fscache.As[fscache.Loader](dst)
+		fst.True(t, ok)
+		fst.NoError(t, loader.Load(ctx, buf))
+
+		fst.NoError(t, dst.Set(ctx, 3, 3, time.Minute).Err)
+		fst.Equal(t, fscache.ErrNotExists, dst.Get(ctx, 1).Err)
+		var got int
+		has, err := dst.Get(ctx, 0).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, 0, got)
+	})
+}
+
 func TestNewWithError(t *testing.T) {
 	_, err := New(&Option{
 		Capacity: 0,
@@ -6,10 +6,15 @@ package lrucache
 
 import (
 	"fmt"
+
+	"github.com/fsgo/fscache"
 )
 
 // Option LRU缓存的配置
 type Option struct {
+	// Option 其中的 Codec 用于导出快照(Dump)时编码缓存值，以及读取从快照恢复(Load)的缓存值
+	fscache.Option
+
 	// Capacity 缓存个数，必填，不得小于 1
 	Capacity int
 }
@@ -8,6 +8,7 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"sync"
 	"time"
@@ -34,10 +35,14 @@ type SCache struct {
 	data map[any]*list.Element
 	list *list.List
 	lock sync.Mutex
+	cas  uint64
 }
 
 // Get 读取
 func (L *SCache) Get(ctx context.Context, key any) fscache.GetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.GetResult{Err: err}
+	}
 	L.lock.Lock()
 	defer L.lock.Unlock()
 	el, has := L.data[key]
@@ -54,32 +59,50 @@ func (L *SCache) Get(ctx context.Context, key any) fscache.GetResult {
 		return fscache.GetResult{Err: fscache.ErrNotExists}
 	}
 	L.list.MoveToFront(el)
+	if raw, ok := val.Data.(fscache.RawValue); ok {
+		return fscache.GetResult{
+			Payload:       raw,
+			UnmarshalFunc: L.opt.GetCodec().Unmarshal,
+			CAS:           val.CAS,
+		}
+	}
 	return fscache.GetResult{
 		UnmarshalFunc: newUnmarshaler(val.Data),
+		CAS:           val.CAS,
 	}
 }
 
 // Set 设置
 func (L *SCache) Set(ctx context.Context, key any, val any, ttl time.Duration) fscache.SetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.SetResult{Err: err}
+	}
+	L.lock.Lock()
+	defer L.lock.Unlock()
+	L.set(key, val, time.Now().Add(ttl))
+	return internal.SetRetSuc
+}
+
+// set 写入，需要在加锁后调用
+func (L *SCache) set(key any, val any, expireAt time.Time) {
+	L.cas++
 	cacheVal := &value{
 		Key:      key,
 		Data:     val,
-		ExpireAt: time.Now().Add(ttl),
+		ExpireAt: expireAt,
+		CAS:      L.cas,
 	}
-	L.lock.Lock()
-	defer L.lock.Unlock()
 	el, has := L.data[key]
 	if has {
 		el.Value = cacheVal
 		L.list.MoveToFront(el)
-		return internal.SetRetSuc
+		return
 	}
 	elm := L.list.PushFront(cacheVal)
 	L.data[key] = elm
 	if L.list.Len() > L.opt.GetCapacity() {
 		L.weedOut()
 	}
-	return internal.SetRetSuc
 }
 
 func (L *SCache) weedOut() {
@@ -94,6 +117,9 @@ func (L *SCache) weedOut() {
 
 // Has 判断是否存在
 func (L *SCache) Has(ctx context.Context, key any) fscache.HasResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.HasResult{Err: err}
+	}
 	L.lock.Lock()
 	el, has := L.data[key]
 	L.lock.Unlock()
@@ -117,6 +143,9 @@ func (L *SCache) Has(ctx context.Context, key any) fscache.HasResult {
 
 // Delete 删除
 func (L *SCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.DeleteResult{Err: err}
+	}
 	L.lock.Lock()
 	defer L.lock.Unlock()
 	el, has := L.data[key]
@@ -130,6 +159,9 @@ func (L *SCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
 
 // Reset 重置、清空所有缓存
 func (L *SCache) Reset(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	L.lock.Lock()
 	L.data = make(map[any]*list.Element, L.opt.GetCapacity())
 	L.list = list.New()
@@ -137,7 +169,79 @@ func (L *SCache) Reset(ctx context.Context) error {
 	return nil
 }
 
+// Scan 遍历所有未过期，且以 prefix 开头的 key，从最近使用的开始遍历
+// 遍历的是调用时的快照，fn 中可以读写缓存
+func (L *SCache) Scan(ctx context.Context, prefix string, fn func(key any, meta fscache.ScanMeta) bool) error {
+	L.lock.Lock()
+	values := make([]*value, 0, L.list.Len())
+	for el := L.list.Front(); el != nil; el = el.Next() {
+		val := el.Value.(*value)
+		if !val.Expired() && fscache.KeyHasPrefix(val.Key, prefix) {
+			values = append(values, val)
+		}
+	}
+	L.lock.Unlock()
+
+	for _, val := range values {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		meta := fscache.ScanMeta{
+			ExpireAt: val.ExpireAt,
+			Size:     -1,
+		}
+		if !fn(val.Key, meta) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Dump 导出所有未过期的缓存，缓存值使用 Option.Codec 编码
+// 按照最久未使用到最近使用的顺序导出，Load 后可以保持相同的淘汰顺序
+func (L *SCache) Dump(ctx context.Context, w io.Writer) error {
+	L.lock.Lock()
+	values := make([]*value, 0, L.list.Len())
+	for el := L.list.Back(); el != nil; el = el.Prev() {
+		if val := el.Value.(*value); !val.Expired() {
+			values = append(values, val)
+		}
+	}
+	L.lock.Unlock()
+
+	sw, err := fscache.NewSnapshotWriter(w)
+	if err != nil {
+		return err
+	}
+	for _, val := range values {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		payload, err := fscache.MarshalValue(L.opt.GetCodec().Marshal, val.Data)
+		if err != nil {
+			return fmt.Errorf("encode value of key %v failed: %w", val.Key, err)
+		}
+		rec := &fscache.SnapshotRecord{
+			Key:      val.Key,
+			ExpireAt: val.ExpireAt,
+			Payload:  payload,
+		}
+		if err = sw.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load 从快照中恢复缓存，缓存值会以 fscache.RawValue 的形式存储，读取时使用 Option.Codec 解码
+func (L *SCache) Load(ctx context.Context, r io.Reader) error {
+	return fscache.LoadSnapshot(ctx, r, L)
+}
+
 var _ fscache.SCache = (*SCache)(nil)
+var _ fscache.Scanner = (*SCache)(nil)
+var _ fscache.Dumper = (*SCache)(nil)
+var _ fscache.Loader = (*SCache)(nil)
 
 func newUnmarshaler(val any) fscache.UnmarshalFunc {
 	return func(_ []byte, obj any) (err error) {
@@ -12,6 +12,9 @@ type value struct {
 	Key      any
 	Data     any
 	ExpireAt time.Time
+
+	// CAS 版本号，每次写入都会变化
+	CAS uint64
 }
 
 // Expired 是否已过期
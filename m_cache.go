@@ -116,18 +116,59 @@ func (mh MHasResult) Get(key any) HasResult {
 	return hasRetNot
 }
 
+// DefaultMCacheConcurrency NewMCacheBySCache 的参数 concurrent 为 true 时，默认的最大并发数
+var DefaultMCacheConcurrency = 64
+
 // NewMCacheBySCache 创建一个MCacheBySCache实例
+// 参数 concurrent 为 true 时，最多使用 DefaultMCacheConcurrency 个协程并发执行
 func NewMCacheBySCache(sCache SCache, concurrent bool) MCache {
+	opt := &MCacheOption{}
+	if concurrent {
+		opt.Concurrency = DefaultMCacheConcurrency
+	}
+	return NewMCacheBySCacheWithOption(sCache, opt)
+}
+
+// MCacheOption NewMCacheBySCacheWithOption 的配置
+type MCacheOption struct {
+	// Concurrency 最大并发数，可选，为 0 或 1 时串行执行
+	Concurrency int
+
+	// ChunkSize 每个任务处理的 key 的数量，任务内的 key 串行执行，可选，默认为 1
+	ChunkSize int
+}
+
+func (o *MCacheOption) getConcurrency() int {
+	if o == nil || o.Concurrency < 1 {
+		return 1
+	}
+	return o.Concurrency
+}
+
+func (o *MCacheOption) getChunkSize() int {
+	if o == nil || o.ChunkSize < 1 {
+		return 1
+	}
+	return o.ChunkSize
+}
+
+// NewMCacheBySCacheWithOption 创建一个MCacheBySCache实例，可以配置并发数等参数
+//
+// 批量操作时，会将 key 分为多个任务(每个任务 ChunkSize 个 key)，最多启动 Concurrency 个 worker 协程执行这些任务；
+// ctx 取消后，不再执行新的任务，未执行的 key 的结果的 Err 为 ctx.Err()
+func NewMCacheBySCacheWithOption(sCache SCache, opt *MCacheOption) MCache {
 	return &mCacheBySCache{
-		sCache:     sCache,
-		concurrent: concurrent,
+		sCache:      sCache,
+		concurrency: opt.getConcurrency(),
+		chunkSize:   opt.getChunkSize(),
 	}
 }
 
 // mCacheBySCache 通过对sCache简单封装获取到的批量查询缓存实例
 type mCacheBySCache struct {
-	sCache     SCache
-	concurrent bool
+	sCache      SCache
+	concurrency int
+	chunkSize   int
 }
 
 // MGetResult 批量查询 MGet 接口的结果
@@ -156,127 +197,101 @@ func (m *mCacheBySCache) MGet(ctx context.Context, keys []any) MGetResult {
 	if mg, ok := m.sCache.(MGetter); ok {
 		return mg.MGet(ctx, keys)
 	}
-	result := make(MGetResult, len(keys))
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-	for _, k := range keys {
-		k := k
-		wg.Add(1)
-		m.runFn(func() {
-			var val GetResult
-			defer func() {
-				if re := recover(); re != nil {
-					val = GetResult{Err: fmt.Errorf("panic:%v", re)}
-				}
-				lock.Lock()
-				result[k] = val
-				lock.Unlock()
-
-				wg.Done()
-			}()
-			val = m.sCache.Get(ctx, k)
-		})
-	}
-	wg.Wait()
-	return result
+	return runKeys(ctx, m, keys, func(k any) GetResult {
+		return m.sCache.Get(ctx, k)
+	}, func(err error) GetResult {
+		return GetResult{Err: err}
+	})
 }
 
 func (m *mCacheBySCache) MSet(ctx context.Context, kvs KVData, ttl time.Duration) MSetResult {
 	if mg, ok := m.sCache.(MSetter); ok {
 		return mg.MSet(ctx, kvs, ttl)
 	}
-	result := make(MSetResult, len(kvs))
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-	for k, v := range kvs {
-		wg.Add(1)
-		k := k
-		v := v
-		m.runFn(func() {
-			var val SetResult
-			defer func() {
-				if re := recover(); re != nil {
-					val = SetResult{Err: fmt.Errorf("panic:%v", re)}
-				}
-
-				lock.Lock()
-				result[k] = val
-				lock.Unlock()
-
-				wg.Done()
-			}()
-			val = m.sCache.Set(ctx, k, v, ttl)
-		})
+	keys := make([]any, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
 	}
-	wg.Wait()
-	return result
+	return runKeys(ctx, m, keys, func(k any) SetResult {
+		return m.sCache.Set(ctx, k, kvs[k], ttl)
+	}, func(err error) SetResult {
+		return SetResult{Err: err}
+	})
 }
 
 func (m *mCacheBySCache) MDelete(ctx context.Context, keys []any) MDeleteResult {
 	if mg, ok := m.sCache.(MDeleter); ok {
 		return mg.MDelete(ctx, keys)
 	}
-	result := make(MDeleteResult, len(keys))
-	var wg sync.WaitGroup
-	var lock sync.Mutex
-	for _, k := range keys {
-		k := k
-		wg.Add(1)
-		m.runFn(func() {
-			var val DeleteResult
-			defer func() {
-				if re := recover(); re != nil {
-					val = DeleteResult{Err: fmt.Errorf("panic:%v", re)}
-				}
-				lock.Lock()
-				result[k] = val
-				lock.Unlock()
-
-				wg.Done()
-			}()
-			val = m.sCache.Delete(ctx, k)
-		})
-	}
-	wg.Wait()
-	return result
+	return runKeys(ctx, m, keys, func(k any) DeleteResult {
+		return m.sCache.Delete(ctx, k)
+	}, func(err error) DeleteResult {
+		return DeleteResult{Err: err}
+	})
 }
 
 func (m *mCacheBySCache) MHas(ctx context.Context, keys []any) MHasResult {
 	if mg, ok := m.sCache.(MHaser); ok {
 		return mg.MHas(ctx, keys)
 	}
-	result := make(MHasResult, len(keys))
-	var wg sync.WaitGroup
+	return runKeys(ctx, m, keys, func(k any) HasResult {
+		return m.sCache.Has(ctx, k)
+	}, func(err error) HasResult {
+		return HasResult{Err: err}
+	})
+}
+
+// runKeys 对每个 key 执行 fn，fn 发生 panic 或者 ctx 取消后未执行的 key，结果由 onErr 生成
+func runKeys[R any](ctx context.Context, m *mCacheBySCache, keys []any, fn func(k any) R, onErr func(err error) R) map[any]R {
+	result := make(map[any]R, len(keys))
 	var lock sync.Mutex
+	runChunk := func(chunk []any) {
+		for _, k := range chunk {
+			var val R
+			if err := ctx.Err(); err != nil {
+				val = onErr(err)
+			} else {
+				val = callKey(k, fn, onErr)
+			}
+			lock.Lock()
+			result[k] = val
+			lock.Unlock()
+		}
+	}
 
-	for _, k := range keys {
-		k := k
-		wg.Add(1)
-		m.runFn(func() {
-			var val HasResult
-			defer func() {
-				if re := recover(); re != nil {
-					val = HasResult{Err: fmt.Errorf("panic:%v", re)}
-				}
-				lock.Lock()
-				result[k] = val
-				lock.Unlock()
-
-				wg.Done()
-			}()
-			val = m.sCache.Has(ctx, k)
-		})
+	chunks := (len(keys) + m.chunkSize - 1) / m.chunkSize
+	workers := min(m.concurrency, chunks)
+	if workers <= 1 {
+		runChunk(keys)
+		return result
 	}
+
+	tasks := make(chan []any)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for chunk := range tasks {
+				runChunk(chunk)
+			}
+		}()
+	}
+	for i := 0; i < len(keys); i += m.chunkSize {
+		tasks <- keys[i:min(i+m.chunkSize, len(keys))]
+	}
+	close(tasks)
 	wg.Wait()
 	return result
 }
 
-func (m *mCacheBySCache) runFn(fn func()) {
-	if m.concurrent {
-		go fn()
-	} else {
-		fn()
-	}
+func callKey[R any](k any, fn func(k any) R, onErr func(err error) R) (val R) {
+	defer func() {
+		if re := recover(); re != nil {
+			val = onErr(fmt.Errorf("panic:%v", re))
+		}
+	}()
+	return fn(k)
 }
 
 var _ MCache = (*mCacheBySCache)(nil)
@@ -5,7 +5,11 @@
 package fscache
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/fsgo/fst"
 )
@@ -17,3 +21,75 @@ func TestMGetResult_Get(t *testing.T) {
 		fst.Equal(t, getRetNotExists, got)
 	})
 }
+
+// slowSCache 记录最大的并发数，key 为 "panic" 时会 panic
+type slowSCache struct {
+	staticSCache
+	delay    time.Duration
+	running  atomic.Int64
+	maxRun   atomic.Int64
+	calls    atomic.Int64
+	onCalled func()
+}
+
+func (s *slowSCache) Get(ctx context.Context, key any) GetResult {
+	s.calls.Add(1)
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		old := s.maxRun.Load()
+		if n <= old || s.maxRun.CompareAndSwap(old, n) {
+			break
+		}
+	}
+	if key == "panic" {
+		panic("get failed")
+	}
+	time.Sleep(s.delay)
+	if s.onCalled != nil {
+		s.onCalled()
+	}
+	return GetResult{Payload: []byte(fmt.Sprint(key))}
+}
+
+func TestNewMCacheBySCacheWithOption(t *testing.T) {
+	keys := make([]any, 200)
+	for i := range keys {
+		keys[i] = i
+	}
+
+	t.Run("bounded", func(t *testing.T) {
+		sc := &slowSCache{delay: time.Millisecond}
+		mc := NewMCacheBySCacheWithOption(sc, &MCacheOption{Concurrency: 8, ChunkSize: 5})
+		ret := mc.MGet(context.Background(), append(keys, "panic"))
+		fst.Equal(t, 201, len(ret))
+		fst.Equal(t, "10", string(ret.Get(10).Payload))
+		fst.Error(t, ret.Get("panic").Err)
+		fst.LessOrEqual(t, sc.maxRun.Load(), int64(8))
+		fst.Greater(t, sc.maxRun.Load(), int64(1))
+	})
+
+	t.Run("serial", func(t *testing.T) {
+		sc := &slowSCache{}
+		ret := NewMCacheBySCache(sc, false).MGet(context.Background(), keys)
+		fst.NoError(t, ret.Err())
+		fst.Equal(t, int64(1), sc.maxRun.Load())
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		for _, opt := range []*MCacheOption{{}, {Concurrency: 4, ChunkSize: 2}} {
+			ctx, cancel := context.WithCancel(context.Background())
+			sc := &slowSCache{}
+			sc.onCalled = func() {
+				if sc.calls.Load() >= 10 {
+					cancel()
+				}
+			}
+			ret := NewMCacheBySCacheWithOption(sc, opt).MGet(ctx, keys)
+			fst.Equal(t, 200, len(ret))
+			fst.LessOrEqual(t, sc.calls.Load(), int64(20))
+			fst.Equal(t, context.Canceled, ret.Get(199).Err)
+			cancel()
+		}
+	})
+}
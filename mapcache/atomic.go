@@ -0,0 +1,121 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package mapcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// GetCAS 读取已缓存的值及其版本号，不会调用 New，版本号可用于 CompareAndSwap
+// 不存在、已过期或者缓存的是 New 失败的 error 时，返回 fscache.ErrNotExists
+func (mc *MapCache) GetCAS(ctx context.Context, key any) (any, uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	cv, has := mc.values.Load(key)
+	if !has {
+		return nil, 0, fscache.ErrNotExists
+	}
+	vv := cv.(*value)
+	if vv.err != nil || !vv.IsOK() {
+		return nil, 0, fscache.ErrNotExists
+	}
+	return vv.payload, vv.cas, nil
+}
+
+// update 原子的更新 key 的值
+// fn 的参数为当前未过期、且不是 error 的值，不存在时为 nil；fn 返回 nil 时不修改
+func (mc *MapCache) update(key any, fn func(old *value) (*value, error)) error {
+	for {
+		cv, loaded := mc.values.Load(key)
+		var old *value
+		if loaded {
+			if vv := cv.(*value); vv.err == nil && vv.IsOK() {
+				old = vv
+			}
+		}
+		nv, err := fn(old)
+		if err != nil || nv == nil {
+			return err
+		}
+		if loaded {
+			if mc.values.CompareAndSwap(key, cv, nv) {
+				return nil
+			}
+			continue
+		}
+		if _, loaded = mc.values.LoadOrStore(key, nv); !loaded {
+			mc.added(key)
+			return nil
+		}
+	}
+}
+
+func (mc *MapCache) expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		ttl = mc.getTTL()
+	}
+	return time.Now().Add(ttl)
+}
+
+// Incr 将 key 的整数值加上 delta，计算后的值和原值的类型相同
+// key 不存在时以 int64(0) 为初始值，ttl <= 0 时使用 TTL 作为有效期
+func (mc *MapCache) Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var num int64
+	err := mc.update(key, func(old *value) (*value, error) {
+		if old == nil {
+			num = delta
+			return mc.newValue(delta, nil, mc.expireAt(ttl)), nil
+		}
+		nv, n, err := fscache.AddInt(old.payload, delta, mc.getCodec())
+		if err != nil {
+			return nil, err
+		}
+		num = n
+		return mc.newValue(nv, nil, old.expired), nil
+	})
+	return num, err
+}
+
+// SetNX 当 key 不存在时写入，ttl <= 0 时使用 TTL 作为有效期
+func (mc *MapCache) SetNX(ctx context.Context, key any, val any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	var ok bool
+	err := mc.update(key, func(old *value) (*value, error) {
+		ok = old == nil
+		if !ok {
+			return nil, nil
+		}
+		return mc.newValue(val, nil, mc.expireAt(ttl)), nil
+	})
+	return ok, err
+}
+
+// CompareAndSwap 当 key 存在且版本号和 cas 一致时写入，cas 为 GetCAS 返回的版本号
+// ttl <= 0 时使用 TTL 作为有效期
+func (mc *MapCache) CompareAndSwap(ctx context.Context, key any, cas uint64, val any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	var ok bool
+	err := mc.update(key, func(old *value) (*value, error) {
+		ok = old != nil && old.cas == cas
+		if !ok {
+			return nil, nil
+		}
+		return mc.newValue(val, nil, mc.expireAt(ttl)), nil
+	})
+	return ok, err
+}
+
+var _ fscache.Atomic = (*MapCache)(nil)
@@ -6,9 +6,14 @@ package mapcache
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/fsgo/fscache"
 )
 
 // MapCache 一个简单的，使用 sync.Map 作为存储的缓存
@@ -27,7 +32,15 @@ type MapCache struct {
 	// 这个值是一个近似值
 	Caption int64
 
+	// Codec 导出快照(Dump)和从快照恢复(Load)时，缓存值的编解码器，可选，默认为 fscache.DefaultCodec
+	Codec fscache.Codec
+
+	// NewValue 从快照恢复(Load)时，创建用于解码缓存值的对象，可选
+	// 返回值需要是指针，缓存的是指针指向的值；为 nil 时解码为 any，如 json 解码后数字为 float64
+	NewValue func() any
+
 	count  int64
+	cas    uint64
 	values sync.Map
 }
 
@@ -51,7 +64,11 @@ func (mc *MapCache) Get(key any) (any, error) {
 }
 
 // GetContext 读取一个值
+// ctx 取消后，会直接返回 ctx.Err()
 func (mc *MapCache) GetContext(ctx context.Context, key any) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	cv, has := mc.values.Load(key)
 	if has {
 		if vv := cv.(*value); vv.IsOK() {
@@ -70,15 +87,24 @@ func (mc *MapCache) GetContext(ctx context.Context, key any) (any, error) {
 }
 
 func (mc *MapCache) store(key any, nv any, err error, ttl time.Duration) {
-	cv := &value{
-		payload: nv,
-		err:     err,
-		expired: time.Now().Add(ttl),
-	}
-	_, hasOld := mc.values.LoadOrStore(key, cv)
+	_, hasOld := mc.values.LoadOrStore(key, mc.newValue(nv, err, time.Now().Add(ttl)))
 	if hasOld {
 		return
 	}
+	mc.added(key)
+}
+
+func (mc *MapCache) newValue(nv any, err error, expired time.Time) *value {
+	return &value{
+		payload: nv,
+		err:     err,
+		expired: expired,
+		cas:     atomic.AddUint64(&mc.cas, 1),
+	}
+}
+
+// added 新增了 key 后，更新计数，超出容量时淘汰部分数据
+func (mc *MapCache) added(key any) {
 	num := atomic.AddInt64(&mc.count, 1)
 	if del := num - mc.getCaption(); del > 0 {
 		mc.clear(key, int(del))
@@ -129,10 +155,99 @@ func (mc *MapCache) Delete(key any) int {
 	return 0
 }
 
+// Scan 遍历所有未过期，且以 prefix 开头的 key，New 失败缓存的 error 不会被遍历
+func (mc *MapCache) Scan(ctx context.Context, prefix string, fn func(key any, meta fscache.ScanMeta) bool) error {
+	var err error
+	mc.values.Range(func(k, v any) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		cv := v.(*value)
+		if cv.err != nil || !cv.IsOK() || !fscache.KeyHasPrefix(k, prefix) {
+			return true
+		}
+		meta := fscache.ScanMeta{
+			ExpireAt: cv.expired,
+			Size:     -1,
+		}
+		return fn(k, meta)
+	})
+	return err
+}
+
+func (mc *MapCache) getCodec() fscache.Codec {
+	if mc.Codec == nil {
+		return fscache.DefaultCodec
+	}
+	return mc.Codec
+}
+
+// Dump 导出所有未过期的缓存，New 失败缓存的 error 不会被导出
+func (mc *MapCache) Dump(ctx context.Context, w io.Writer) error {
+	sw, err := fscache.NewSnapshotWriter(w)
+	if err != nil {
+		return err
+	}
+	mc.values.Range(func(k, v any) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		cv := v.(*value)
+		if cv.err != nil || !cv.IsOK() {
+			return true
+		}
+		var payload []byte
+		payload, err = fscache.MarshalValue(mc.getCodec().Marshal, cv.payload)
+		if err != nil {
+			err = fmt.Errorf("encode value of key %v failed: %w", k, err)
+			return false
+		}
+		err = sw.Write(&fscache.SnapshotRecord{Key: k, ExpireAt: cv.expired, Payload: payload})
+		return err == nil
+	})
+	return err
+}
+
+// Load 从快照中恢复缓存，已存在的 key 不会被覆盖
+func (mc *MapCache) Load(ctx context.Context, r io.Reader) error {
+	sr, err := fscache.NewSnapshotReader(r)
+	if err != nil {
+		return err
+	}
+	return sr.Range(ctx, func(rec *fscache.SnapshotRecord) error {
+		val, err := mc.decode(rec.Payload)
+		if err != nil {
+			return fmt.Errorf("decode value of key %v failed: %w", rec.Key, err)
+		}
+		mc.store(rec.Key, val, nil, rec.TTL())
+		return nil
+	})
+}
+
+func (mc *MapCache) decode(payload []byte) (any, error) {
+	if mc.NewValue == nil {
+		var val any
+		err := mc.getCodec().Unmarshal(payload, &val)
+		return val, err
+	}
+	ptr := mc.NewValue()
+	if err := mc.getCodec().Unmarshal(payload, ptr); err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(ptr).Elem().Interface(), nil
+}
+
+var _ fscache.Scanner = (*MapCache)(nil)
+var _ fscache.Dumper = (*MapCache)(nil)
+var _ fscache.Loader = (*MapCache)(nil)
+
 type value struct {
 	expired time.Time
 	payload any
 	err     error
+
+	// cas 版本号，每次写入都会变化
+	cas uint64
 }
 
 func (v *value) IsOK() bool {
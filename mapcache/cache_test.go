@@ -5,6 +5,7 @@
 package mapcache
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"sync"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
 )
 
 func TestMapCache(t *testing.T) {
@@ -65,6 +68,139 @@ func TestMapCache(t *testing.T) {
 	})
 }
 
+func TestMapCache_Scan(t *testing.T) {
+	mc := &MapCache{
+		New: func(ctx context.Context, key any) (any, error) {
+			if key.(int) == 3 {
+				return 0, errors.New("invalid id")
+			}
+			return key, nil
+		},
+		FailTTL: time.Minute,
+	}
+	for i := 0; i < 12; i++ {
+		_, _ = mc.Get(i)
+	}
+
+	var keys []any
+	err := mc.Scan(context.Background(), "1", func(key any, meta fscache.ScanMeta) bool {
+		keys = append(keys, key)
+		fst.True(t, meta.ExpireAt.After(time.Now()))
+		return true
+	})
+	fst.NoError(t, err)
+	fst.Equal(t, 3, len(keys))
+
+	var num int
+	err = mc.Scan(context.Background(), "", func(key any, meta fscache.ScanMeta) bool {
+		fst.NotEqual[any](t, 3, key)
+		num++
+		return true
+	})
+	fst.NoError(t, err)
+	fst.Equal(t, 11, num)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fst.Error(t, mc.Scan(ctx, "", func(key any, meta fscache.ScanMeta) bool {
+		return true
+	}))
+}
+
+func TestMapCache_Snapshot(t *testing.T) {
+	type user struct {
+		Name string
+	}
+	src := &MapCache{
+		New: func(ctx context.Context, key any) (any, error) {
+			if key.(string) == "bad" {
+				return nil, errors.New("bad key")
+			}
+			return user{Name: key.(string)}, nil
+		},
+		FailTTL: time.Minute,
+	}
+	for _, key := range []string{"a", "b", "bad"} {
+		_, _ = src.Get(key)
+	}
+	buf := &bytes.Buffer{}
+	fst.NoError(t, src.Dump(context.Background(), buf))
+
+	dst := &MapCache{
+		New: func(ctx context.Context, key any) (any, error) {
+			return nil, errors.New("should not call New")
+		},
+		NewValue: func() any {
+			return &user{}
+		},
+	}
+	fst.NoError(t, dst.Load(context.Background(), buf))
+	for _, key := range []string{"a", "b"} {
+		val, err := dst.Get(key)
+		fst.NoError(t, err)
+		fst.Equal[any](t, user{Name: key}, val)
+	}
+	_, err := dst.Get("bad")
+	fst.Error(t, err)
+
+	fst.Error(t, dst.Load(context.Background(), bytes.NewReader(nil)))
+}
+
+func TestMapCacheAtomic(t *testing.T) {
+	ctx := context.Background()
+	mc := &MapCache{
+		New: func(ctx context.Context, key any) (any, error) {
+			return 10, nil
+		},
+	}
+
+	num, err := mc.Incr(ctx, "n", 2, time.Minute)
+	fst.NoError(t, err)
+	fst.Equal(t, int64(2), num)
+
+	// 通过 New 创建的值，Incr 后类型不变
+	_, _ = mc.Get("m")
+	num, err = mc.Incr(ctx, "m", 5, time.Minute)
+	fst.NoError(t, err)
+	fst.Equal(t, int64(15), num)
+	val, err := mc.Get("m")
+	fst.NoError(t, err)
+	fst.Equal[any](t, 15, val)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mc.Incr(ctx, "n", 1, time.Minute)
+			fst.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	val, cas, err := mc.GetCAS(ctx, "n")
+	fst.NoError(t, err)
+	fst.Equal[any](t, int64(22), val)
+
+	ok, err := mc.CompareAndSwap(ctx, "n", cas, "v1", time.Minute)
+	fst.NoError(t, err)
+	fst.True(t, ok)
+	ok, err = mc.CompareAndSwap(ctx, "n", cas, "v2", time.Minute)
+	fst.NoError(t, err)
+	fst.False(t, ok)
+	_, err = mc.Incr(ctx, "n", 1, time.Minute)
+	fst.ErrorIs(t, err, fscache.ErrNotInteger)
+
+	ok, err = mc.SetNX(ctx, "x", "v1", time.Minute)
+	fst.NoError(t, err)
+	fst.True(t, ok)
+	ok, err = mc.SetNX(ctx, "x", "v2", time.Minute)
+	fst.NoError(t, err)
+	fst.False(t, ok)
+
+	_, _, err = mc.GetCAS(ctx, "not_exists")
+	fst.ErrorIs(t, err, fscache.ErrNotExists)
+}
+
 func BenchmarkMapCache(b *testing.B) {
 	mc := &MapCache{
 		New: func(ctx context.Context, key any) (any, error) {
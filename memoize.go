@@ -0,0 +1,218 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Memoize 返回 fn 的带缓存的版本
+//
+// 调用时先读取缓存，缓存不存在或者读取失败时，才会调用 fn，并将结果以 ttl 写入缓存；
+// 同一时刻相同 key 的多个调用只会执行一次 fn，其他调用等待并共享其结果。
+// fn 返回的 error 不会缓存；缓存的值为 AbsentValue 时，返回 ErrAbsent，不会调用 fn。
+// fn 的结果是共享的，调用 fn 使用的 ctx 不会随发起调用的 ctx 取消，但保留其截止时间；
+// 所有的调用(包括发起加载的调用)在自己的 ctx 取消时都会直接返回，加载会在后台继续。
+// fn panic 时，所有等待的调用都会返回包含 panic 信息的 error
+func Memoize[K comparable, V any](cache SCache, ttl time.Duration, fn func(ctx context.Context, key K) (V, error)) func(ctx context.Context, key K) (V, error) {
+	pc := &ProS[K, V]{SCache: cache}
+	group := &flightGroup[K, V]{}
+	return func(ctx context.Context, key K) (V, error) {
+		value, err := pc.Get(ctx, key)
+		if err == nil || errors.Is(err, ErrAbsent) {
+			return value, err
+		}
+		values, err := group.do(ctx, []K{key}, func(keys []K) (map[K]V, error) {
+			lctx, cancel := detachContext(ctx)
+			defer cancel()
+			v, err := fn(lctx, key)
+			if err != nil {
+				return nil, err
+			}
+			_ = pc.Set(lctx, key, v, ttl)
+			return map[K]V{key: v}, nil
+		})
+		if err != nil {
+			return value, err
+		}
+		return values[key], nil
+	}
+}
+
+// MemoizeBatch 返回批量函数 fn 的带缓存的版本
+//
+// 调用时先使用 MGet 批量读取缓存(cache 未实现 MGetter 时会并发执行 Get)，
+// 只使用缓存中不存在的 key 调用 fn，并将 fn 返回的结果使用 MSet 以 ttl 写入缓存；
+// 正在被其他调用加载的 key 不会重复加载，会等待并共享其结果。
+// fn 返回的结果中没有的 key，以及缓存的值为 AbsentValue 的 key，在返回的结果中也不存在。
+// 同 Memoize，调用 fn 使用的 ctx 不会随发起调用的 ctx 取消，但保留其截止时间
+func MemoizeBatch[K comparable, V any](cache SCache, ttl time.Duration, fn func(ctx context.Context, keys []K) (map[K]V, error)) func(ctx context.Context, keys []K) (map[K]V, error) {
+	mc := NewMCacheBySCache(cache, true)
+	group := &flightGroup[K, V]{}
+	return func(ctx context.Context, keys []K) (map[K]V, error) {
+		result := make(map[K]V, len(keys))
+		if len(keys) == 0 {
+			return result, nil
+		}
+		anyKeys := make([]any, 0, len(keys))
+		for _, k := range keys {
+			anyKeys = append(anyKeys, k)
+		}
+		rets := mc.MGet(ctx, anyKeys)
+		var misses []K
+		for _, k := range keys {
+			if _, ok := result[k]; ok {
+				continue
+			}
+			value, err := decodeResult[V](rets.Get(k))
+			switch {
+			case err == nil:
+				result[k] = value
+			case errors.Is(err, ErrAbsent):
+			default:
+				misses = append(misses, k)
+			}
+		}
+		if len(misses) == 0 {
+			return result, nil
+		}
+		values, err := group.do(ctx, misses, func(keys []K) (map[K]V, error) {
+			lctx, cancel := detachContext(ctx)
+			defer cancel()
+			values, err := fn(lctx, keys)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) > 0 {
+				kvs := make(KVData, len(values))
+				for k, v := range values {
+					kvs[k] = v
+				}
+				mc.MSet(lctx, kvs, ttl)
+			}
+			return values, nil
+		})
+		for k, v := range values {
+			result[k] = v
+		}
+		return result, err
+	}
+}
+
+// detachContext 返回不会随 ctx 取消，但有相同截止时间的 ctx
+// 加载的结果是共享的，不能因为发起加载的调用被取消而中断
+func detachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	dctx := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(dctx, deadline)
+	}
+	return dctx, func() {}
+}
+
+// flightGroup 合并相同 key 的并发加载
+type flightGroup[K comparable, V any] struct {
+	calls map[K]*flightCall[V]
+	mu    sync.Mutex
+}
+
+type flightCall[V any] struct {
+	done  chan struct{}
+	err   error
+	val   V
+	found bool
+}
+
+// do 加载 keys：没有正在加载的 key 会在新的协程中使用 fn 一次性加载，正在被其他调用加载的 key 会等待其结果；
+// 返回已加载到的值，fn 或者等待的加载返回 error 时，也会返回该 error；ctx 取消时直接返回
+func (g *flightGroup[K, V]) do(ctx context.Context, keys []K, fn func(keys []K) (map[K]V, error)) (map[K]V, error) {
+	var leads []K
+	leadCalls := make(map[K]*flightCall[V], len(keys))
+	waits := make(map[K]*flightCall[V])
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*flightCall[V])
+	}
+	for _, k := range keys {
+		if _, ok := leadCalls[k]; ok {
+			continue
+		}
+		if c, ok := g.calls[k]; ok {
+			waits[k] = c
+			continue
+		}
+		c := &flightCall[V]{done: make(chan struct{})}
+		g.calls[k] = c
+		leadCalls[k] = c
+		leads = append(leads, k)
+	}
+	g.mu.Unlock()
+
+	result := make(map[K]V, len(keys))
+	var errs []error
+	if len(leads) > 0 {
+		go g.lead(leads, leadCalls, fn)
+		for _, k := range leads {
+			c := leadCalls[k]
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-c.done:
+			}
+			if c.found {
+				result[k] = c.val
+			}
+		}
+		// 同一次加载的 err 都相同，只记录一次
+		if err := leadCalls[leads[0]].err; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for k, c := range waits {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-c.done:
+		}
+		if c.err != nil {
+			errs = append(errs, c.err)
+			continue
+		}
+		if c.found {
+			result[k] = c.val
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// lead 使用 fn 加载 keys，并将结果通知所有等待的调用，fn panic 时会转换为 error
+func (g *flightGroup[K, V]) lead(keys []K, calls map[K]*flightCall[V], fn func(keys []K) (map[K]V, error)) {
+	var err error
+	defer func() {
+		if re := recover(); re != nil {
+			err = fmt.Errorf("panic: %v", re)
+		}
+		g.mu.Lock()
+		for k, c := range calls {
+			if err != nil {
+				c.err = err
+			}
+			delete(g.calls, k)
+			close(c.done)
+		}
+		g.mu.Unlock()
+	}()
+	var values map[K]V
+	if values, err = fn(keys); err != nil {
+		return
+	}
+	for k, c := range calls {
+		c.val, c.found = values[k]
+	}
+}
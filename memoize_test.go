@@ -0,0 +1,200 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+func TestMemoize(t *testing.T) {
+	ctx := context.Background()
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	errFail := errors.New("fail")
+	get := fscache.Memoize(lc, time.Minute, func(ctx context.Context, key int) (string, error) {
+		calls.Add(1)
+		<-release
+		if key < 0 {
+			return "", errFail
+		}
+		return strconv.Itoa(key), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := get(ctx, 1)
+			fst.NoError(t, err)
+			fst.Equal(t, "1", got)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	fst.Equal(t, int64(1), calls.Load())
+
+	got, err := get(ctx, 1)
+	fst.NoError(t, err)
+	fst.Equal(t, "1", got)
+	fst.Equal(t, int64(1), calls.Load())
+
+	t.Run("error not cached", func(t *testing.T) {
+		_, err := get(ctx, -1)
+		fst.ErrorIs(t, err, errFail)
+		_, err = get(ctx, -1)
+		fst.ErrorIs(t, err, errFail)
+		fst.Equal(t, int64(3), calls.Load())
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		fst.NoError(t, lc.Set(ctx, 2, fscache.AbsentValue, time.Minute).Err)
+		_, err := get(ctx, 2)
+		fst.ErrorIs(t, err, fscache.ErrAbsent)
+		fst.Equal(t, int64(3), calls.Load())
+	})
+
+	t.Run("leader canceled", func(t *testing.T) {
+		release := make(chan struct{})
+		get := fscache.Memoize(lc, time.Minute, func(ctx context.Context, key int) (string, error) {
+			<-release
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			return "v" + strconv.Itoa(key), nil
+		})
+		leaderCtx, cancel := context.WithCancel(ctx)
+		leaderDone := make(chan struct{})
+		go func() {
+			defer close(leaderDone)
+			_, _ = get(leaderCtx, 10)
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		waiterDone := make(chan struct{})
+		go func() {
+			defer close(waiterDone)
+			got, err := get(ctx, 10)
+			fst.NoError(t, err)
+			fst.Equal(t, "v10", got)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		close(release)
+		<-leaderDone
+		<-waiterDone
+	})
+
+	t.Run("leader timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		var deadline atomic.Bool
+		get := fscache.Memoize(lc, time.Minute, func(ctx context.Context, key int) (string, error) {
+			_, ok := ctx.Deadline()
+			deadline.Store(ok)
+			<-release
+			return "v", nil
+		})
+		tctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := get(tctx, 20)
+		fst.ErrorIs(t, err, context.DeadlineExceeded)
+		fst.Less(t, time.Since(start), time.Second)
+		fst.True(t, deadline.Load())
+	})
+}
+
+func TestMemoizeBatch(t *testing.T) {
+	ctx := context.Background()
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+
+	var loaded [][]int
+	var mu sync.Mutex
+	get := fscache.MemoizeBatch(lc, time.Minute, func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		loaded = append(loaded, keys)
+		mu.Unlock()
+		result := make(map[int]string, len(keys))
+		for _, k := range keys {
+			// 奇数不存在
+			if k%2 == 0 {
+				result[k] = strconv.Itoa(k)
+			}
+		}
+		return result, nil
+	})
+
+	got, err := get(ctx, []int{2, 4, 4, 5})
+	fst.NoError(t, err)
+	fst.Equal(t, map[int]string{2: "2", 4: "4"}, got)
+	fst.Equal(t, [][]int{{2, 4, 5}}, loaded)
+
+	got, err = get(ctx, []int{2, 4, 6})
+	fst.NoError(t, err)
+	fst.Equal(t, map[int]string{2: "2", 4: "4", 6: "6"}, got)
+	fst.Equal(t, [][]int{{2, 4, 5}, {6}}, loaded)
+
+	got, err = get(ctx, nil)
+	fst.NoError(t, err)
+	fst.Empty(t, got)
+
+	t.Run("error", func(t *testing.T) {
+		errFail := errors.New("fail")
+		get := fscache.MemoizeBatch(lc, time.Minute, func(ctx context.Context, keys []int) (map[int]string, error) {
+			return nil, errFail
+		})
+		got, err := get(ctx, []int{2, 100})
+		fst.ErrorIs(t, err, errFail)
+		fst.Equal(t, map[int]string{2: "2"}, got)
+	})
+
+	t.Run("leader canceled", func(t *testing.T) {
+		release := make(chan struct{})
+		get := fscache.MemoizeBatch(lc, time.Minute, func(ctx context.Context, keys []int) (map[int]string, error) {
+			<-release
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return map[int]string{keys[0]: "v"}, nil
+		})
+		leaderCtx, cancel := context.WithCancel(ctx)
+		leaderDone := make(chan struct{})
+		go func() {
+			defer close(leaderDone)
+			_, _ = get(leaderCtx, []int{200})
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		waiterDone := make(chan struct{})
+		go func() {
+			defer close(waiterDone)
+			got, err := get(ctx, []int{200})
+			fst.NoError(t, err)
+			fst.Equal(t, map[int]string{200: "v"}, got)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		close(release)
+		<-leaderDone
+		<-waiterDone
+	})
+}
@@ -20,46 +20,86 @@ type nopCache struct{}
 
 // Get 查询，总是返回 key 不存在
 func (n *nopCache) Get(ctx context.Context, key any) fscache.GetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.GetResult{Err: err}
+	}
 	return internal.GetRetNotExists
 }
 
 // Set 写入，总是返回写成功
 func (n *nopCache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.SetResult{Err: err}
+	}
 	return internal.SetRetSuc
 }
 
 // Has 判断是否存在，总是返回 key 不存在
 func (n *nopCache) Has(ctx context.Context, key any) fscache.HasResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.HasResult{Err: err}
+	}
 	return internal.HasRetNot
 }
 
 // Delete 删除，总是成功，返回删除 0 条
 func (n *nopCache) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	if err := ctx.Err(); err != nil {
+		return fscache.DeleteResult{Err: err}
+	}
 	return internal.DeleteRetSucHas0
 }
 
 // Reset 重置
 func (n *nopCache) Reset(ctx context.Context) error {
-	return nil
+	return ctx.Err()
 }
 
 // MGet 批量获取，总是返回 key 不存在
 func (n *nopCache) MGet(ctx context.Context, keys []any) fscache.MGetResult {
+	if err := ctx.Err(); err != nil {
+		result := make(fscache.MGetResult, len(keys))
+		for _, k := range keys {
+			result[k] = fscache.GetResult{Err: err}
+		}
+		return result
+	}
 	return nil
 }
 
 // MSet 批量写入，总是返回写成功
 func (n *nopCache) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	if err := ctx.Err(); err != nil {
+		result := make(fscache.MSetResult, len(kvs))
+		for k := range kvs {
+			result[k] = fscache.SetResult{Err: err}
+		}
+		return result
+	}
 	return nil
 }
 
 // MDelete 批量删除，总是删除成功
 func (n *nopCache) MDelete(ctx context.Context, keys []any) fscache.MDeleteResult {
+	if err := ctx.Err(); err != nil {
+		result := make(fscache.MDeleteResult, len(keys))
+		for _, k := range keys {
+			result[k] = fscache.DeleteResult{Err: err}
+		}
+		return result
+	}
 	return nil
 }
 
 // MHas 判断是否存在，总是不存在
 func (n *nopCache) MHas(ctx context.Context, keys []any) fscache.MHasResult {
+	if err := ctx.Err(); err != nil {
+		result := make(fscache.MHasResult, len(keys))
+		for _, k := range keys {
+			result[k] = fscache.HasResult{Err: err}
+		}
+		return result
+	}
 	return nil
 }
 
@@ -33,6 +33,14 @@ func TestNop(t *testing.T) {
 		got := Nop.Delete(ctx, key)
 		fst.Equal(t, internal.DeleteRetSucHas0, got)
 	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		fst.ErrorIs(t, Nop.Get(ctx, key).Err, context.Canceled)
+		fst.ErrorIs(t, Nop.Set(ctx, key, value, time.Second).Err, context.Canceled)
+		fst.ErrorIs(t, Nop.MGet(ctx, []any{key}).Get(key).Err, context.Canceled)
+	})
 }
 
 func Test_nopCache_MGet(t *testing.T) {
@@ -6,11 +6,12 @@ package fscache
 
 // Option 配置
 type Option struct {
-	// Codec 编解码器，可选，默认为 msgpack
+	// Codec 编解码器，可选，默认为 DefaultCodec(json)
+	// 可选值如 JSONCodec、GobCodec、MsgpackCodec、PassthroughCodec
 	Codec Codec
 }
 
-// GetCodec 获取编解码器，若没有设置，会返回默认值(msgpack)
+// GetCodec 获取编解码器，若没有设置，会返回默认值 DefaultCodec(json)
 func (o *Option) GetCodec() Codec {
 	if o == nil || o.Codec == nil {
 		return DefaultCodec
@@ -15,12 +15,23 @@ type ProS[K any, V any] struct {
 }
 
 // Get 查询单个
+// 若缓存的值为 AbsentValue，返回 ErrAbsent
 func (pc *ProS[K, V]) Get(ctx context.Context, key K) (value V, err error) {
-	ret := pc.SCache.Get(ctx, key)
+	return decodeResult[V](pc.SCache.Get(ctx, key))
+}
+
+// decodeResult 将 GetResult 解码为 V，不存在时返回 ErrNotExists，值为 AbsentValue 时返回 ErrAbsent
+func decodeResult[V any](ret GetResult) (value V, err error) {
 	if ret.Err != nil {
 		return value, ret.Err
 	}
-	_, err = ret.Value(&value)
+	if IsAbsent(ret) {
+		return value, ErrAbsent
+	}
+	has, err := ret.Value(&value)
+	if err == nil && !has {
+		return value, ErrNotExists
+	}
 	return value, err
 }
 
@@ -30,6 +41,13 @@ func (pc *ProS[K, V]) Set(ctx context.Context, key K, value V, ttl time.Duration
 	return ret.Err
 }
 
+// SetAbsent 写入 "已知不存在" 的标记(AbsentValue)，之后 Get 会返回 ErrAbsent
+// 需要 SCache 支持 RawValue
+func (pc *ProS[K, V]) SetAbsent(ctx context.Context, key K, ttl time.Duration) error {
+	ret := pc.SCache.Set(ctx, key, AbsentValue, ttl)
+	return ret.Err
+}
+
 // Has 判断是否存在
 func (pc *ProS[K, V]) Has(ctx context.Context, key K) (has bool, err error) {
 	ret := pc.SCache.Has(ctx, key)
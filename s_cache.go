@@ -33,6 +33,9 @@ type GetResult struct {
 	Err           error
 	UnmarshalFunc UnmarshalFunc
 	Payload       []byte
+
+	// CAS 缓存值的版本号，用于 Atomic.CompareAndSwap，为 0 时表示不支持
+	CAS uint64
 }
 
 func (g GetResult) String() string {
@@ -40,19 +43,27 @@ func (g GetResult) String() string {
 }
 
 // Value 获取值
+// 若 Err 或者解码时返回的 error 为 ErrNotExists(如 ErrVersionMismatch、ErrAbsent)，会当做不存在处理；
+// 若值为 AbsentValue，也会当做不存在处理
 func (g GetResult) Value(obj any) (has bool, err error) {
-	if g.Err == ErrNotExists {
+	if errors.Is(g.Err, ErrNotExists) {
 		return false, nil
 	}
 	if g.Err != nil {
 		return false, g.Err
 	}
+	if isAbsentPayload(g.Payload) {
+		return false, nil
+	}
 
 	if g.UnmarshalFunc == nil {
 		return false, errors.New("unmarshaler is nil")
 	}
 
 	err = g.UnmarshalFunc(g.Payload, obj)
+	if errors.Is(err, ErrNotExists) {
+		return false, nil
+	}
 	return err == nil, err
 }
 
@@ -0,0 +1,42 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scanner 遍历缓存的 key，可用于管理页面、按前缀清理缓存、导出预热的 key 列表等
+// 如内存缓存、本地文件缓存，可以实现该接口
+type Scanner interface {
+	// Scan 遍历所有未过期，且以 prefix 开头的 key，fn 返回 false 时停止遍历
+	// 判断前缀时，会使用 key 的字符串形式(fmt.Sprint)；
+	// 遍历期间缓存可能被修改，新写入的 key 不一定会被遍历到。
+	// 不同的实现返回的 key 的类型可能和写入时不同，如本地文件缓存返回的是 key 的字符串形式
+	Scan(ctx context.Context, prefix string, fn func(key any, meta ScanMeta) bool) error
+}
+
+// ScanMeta 遍历时，缓存的元信息
+type ScanMeta struct {
+	// ExpireAt 过期时间，为零值时表示不过期或者未知
+	ExpireAt time.Time
+
+	// Size 缓存内容的大小，单位字节，为 -1 时表示未知
+	Size int64
+}
+
+// KeyHasPrefix 判断 key 的字符串形式是否以 prefix 开头
+func KeyHasPrefix(key any, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	if s, ok := key.(string); ok {
+		return strings.HasPrefix(s, prefix)
+	}
+	return strings.HasPrefix(fmt.Sprint(key), prefix)
+}
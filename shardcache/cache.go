@@ -0,0 +1,413 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package shardcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsgo/fscache"
+)
+
+// New 创建分片缓存
+// 使用一致性哈希将 key 分布到多个缓存中，如多个远程节点，或者不同磁盘上的多个文件缓存
+func New(opt *Option) (*Cache, error) {
+	if err := opt.Check(); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		opt: opt,
+	}
+	shards := append([]*Shard(nil), opt.Shards...)
+	c.ring.Store(newRing(shards, opt))
+	return c, nil
+}
+
+// Cache 分片缓存
+//
+// 批量接口会将 key 按照分片分组，每个分片批量执行一次，多个分片并发执行；
+// key 使用 fmt.Sprint(key) 计算哈希值
+type Cache struct {
+	opt  *Option
+	ring atomic.Pointer[ring]
+	mu   sync.Mutex
+}
+
+// AddShard 添加分片，只有部分 key 会重新分布到新的分片上
+func (c *Cache) AddShard(s *Shard) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.ring.Load()
+	shards := append(append([]*Shard(nil), old.shards...), s)
+	if err := checkShards(shards); err != nil {
+		return err
+	}
+	c.ring.Store(newRing(shards, c.opt))
+	return nil
+}
+
+// RemoveShard 删除分片，只有该分片上的 key 会重新分布到其他分片上
+func (c *Cache) RemoveShard(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.ring.Load()
+	shards := make([]*Shard, 0, len(old.shards))
+	for _, s := range old.shards {
+		if s.Name != name {
+			shards = append(shards, s)
+		}
+	}
+	if len(shards) == len(old.shards) {
+		return fmt.Errorf("shard %q not found", name)
+	}
+	if len(shards) == 0 {
+		return errors.New("cannot remove the last shard")
+	}
+	c.ring.Store(newRing(shards, c.opt))
+	return nil
+}
+
+// Shards 当前所有分片的名称
+func (c *Cache) Shards() []string {
+	r := c.ring.Load()
+	names := make([]string, len(r.shards))
+	for i, s := range r.shards {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Locate key 所在的分片的名称，第一个为主分片，其余的为副本
+func (c *Cache) Locate(key any) []string {
+	r := c.ring.Load()
+	idx := c.nodes(r, key)
+	names := make([]string, len(idx))
+	for i, n := range idx {
+		names[i] = r.shards[n].Name
+	}
+	return names
+}
+
+func (c *Cache) nodes(r *ring, key any) []int {
+	return r.lookup(c.opt.hash(fmt.Sprint(key)), c.opt.getReplicas())
+}
+
+// isMiss 是否是缓存不存在，而不是读取失败
+func isMiss(err error) bool {
+	return errors.Is(err, fscache.ErrNotExists)
+}
+
+func shardErr(s *Shard, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("shard %q: %w", s.Name, err)
+}
+
+// Get 读取，按顺序从各副本读取，直到读取成功
+// 所有副本都读取失败时，若有副本返回的不是 ErrNotExists，返回该错误
+func (c *Cache) Get(ctx context.Context, key any) fscache.GetResult {
+	r := c.ring.Load()
+	var missed []int
+	var failed fscache.GetResult
+	for _, n := range c.nodes(r, key) {
+		ret := r.shards[n].Cache.Get(ctx, key)
+		if ret.Err == nil {
+			repairs := make(map[int]fscache.KVData)
+			c.addRepair(repairs, missed, key, ret)
+			c.repair(ctx, r, repairs)
+			return ret
+		}
+		if isMiss(ret.Err) {
+			missed = append(missed, n)
+		} else if failed.Err == nil {
+			failed = ret
+		}
+	}
+	if failed.Err != nil {
+		return failed
+	}
+	return fscache.GetResult{Err: fscache.ErrNotExists}
+}
+
+// addRepair 读修复，将 ret 按照分片添加到 groups 中，之后需要写回到 missed 中的分片
+func (c *Cache) addRepair(groups map[int]fscache.KVData, missed []int, key any, ret fscache.GetResult) {
+	if c.opt.RepairTTL <= 0 || ret.Payload == nil {
+		return
+	}
+	for _, n := range missed {
+		if groups[n] == nil {
+			groups[n] = fscache.KVData{}
+		}
+		groups[n][key] = fscache.RawValue(ret.Payload)
+	}
+}
+
+// repair 读修复，将 groups 批量写回到对应的分片
+func (c *Cache) repair(ctx context.Context, r *ring, groups map[int]fscache.KVData) {
+	if len(groups) == 0 || ctx.Err() != nil {
+		return
+	}
+	parallel(groups, func(n int, kvs fscache.KVData) {
+		r.shards[n].Cache.MSet(ctx, kvs, c.opt.RepairTTL)
+	})
+}
+
+// Set 写入所有的副本，任意副本写入失败都会返回错误
+func (c *Cache) Set(ctx context.Context, key any, value any, ttl time.Duration) fscache.SetResult {
+	r := c.ring.Load()
+	var errs []error
+	for _, n := range c.nodes(r, key) {
+		s := r.shards[n]
+		errs = append(errs, shardErr(s, s.Cache.Set(ctx, key, value, ttl).Err))
+	}
+	return fscache.SetResult{Err: errors.Join(errs...)}
+}
+
+// Has 判断是否存在，任意副本存在即为存在
+func (c *Cache) Has(ctx context.Context, key any) fscache.HasResult {
+	r := c.ring.Load()
+	var result fscache.HasResult
+	for _, n := range c.nodes(r, key) {
+		ret := r.shards[n].Cache.Has(ctx, key)
+		if ret.Has {
+			return ret
+		}
+		if result.Err == nil {
+			result.Err = ret.Err
+		}
+	}
+	return result
+}
+
+// Delete 删除所有的副本，Deleted 为各副本中删除的最大条数
+func (c *Cache) Delete(ctx context.Context, key any) fscache.DeleteResult {
+	r := c.ring.Load()
+	var result fscache.DeleteResult
+	var errs []error
+	for _, n := range c.nodes(r, key) {
+		s := r.shards[n]
+		ret := s.Cache.Delete(ctx, key)
+		result.Deleted = max(result.Deleted, ret.Deleted)
+		errs = append(errs, shardErr(s, ret.Err))
+	}
+	result.Err = errors.Join(errs...)
+	return result
+}
+
+// MGet 批量读取，按照分片分组后批量读取，未读取到的 key 再从下一个副本批量读取
+func (c *Cache) MGet(ctx context.Context, keys []any) fscache.MGetResult {
+	r := c.ring.Load()
+	routes := c.routes(r, keys)
+	result := make(fscache.MGetResult, len(keys))
+	missed := make(map[any][]int)
+	failed := make(map[any]fscache.GetResult)
+	repairs := make(map[int]fscache.KVData)
+	missing := keys
+	for i := 0; i < c.opt.getReplicas() && len(missing) > 0; i++ {
+		groups := groupKeys(routes, missing, i)
+		rets := make(map[int]fscache.MGetResult, len(groups))
+		var mu sync.Mutex
+		parallel(groups, func(n int, ks []any) {
+			ret := r.shards[n].Cache.MGet(ctx, ks)
+			mu.Lock()
+			rets[n] = ret
+			mu.Unlock()
+		})
+		next := make([]any, 0, len(missing))
+		for _, k := range missing {
+			if i >= len(routes[k]) {
+				continue
+			}
+			n := routes[k][i]
+			ret := rets[n].Get(k)
+			switch {
+			case ret.Err == nil:
+				result[k] = ret
+				c.addRepair(repairs, missed[k], k, ret)
+				continue
+			case isMiss(ret.Err):
+				missed[k] = append(missed[k], n)
+			case failed[k].Err == nil:
+				failed[k] = ret
+			}
+			next = append(next, k)
+		}
+		missing = next
+	}
+	for _, k := range keys {
+		if _, has := result[k]; has {
+			continue
+		}
+		if ret, has := failed[k]; has {
+			result[k] = ret
+		} else {
+			result[k] = fscache.GetResult{Err: fscache.ErrNotExists}
+		}
+	}
+	c.repair(ctx, r, repairs)
+	return result
+}
+
+// MSet 批量写入，按照分片分组后批量写入所有的副本
+func (c *Cache) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	r := c.ring.Load()
+	groups := make(map[int]fscache.KVData)
+	for k, v := range kvs {
+		for _, n := range c.nodes(r, k) {
+			if groups[n] == nil {
+				groups[n] = fscache.KVData{}
+			}
+			groups[n][k] = v
+		}
+	}
+	errs := make(map[any][]error, len(kvs))
+	var mu sync.Mutex
+	parallel(groups, func(n int, group fscache.KVData) {
+		s := r.shards[n]
+		ret := s.Cache.MSet(ctx, group, ttl)
+		mu.Lock()
+		defer mu.Unlock()
+		for k := range group {
+			if err := ret.Get(k).Err; err != nil {
+				errs[k] = append(errs[k], shardErr(s, err))
+			}
+		}
+	})
+	result := make(fscache.MSetResult, len(kvs))
+	for k := range kvs {
+		result[k] = fscache.SetResult{Err: errors.Join(errs[k]...)}
+	}
+	return result
+}
+
+// MDelete 批量删除，按照分片分组后批量删除所有的副本
+func (c *Cache) MDelete(ctx context.Context, keys []any) fscache.MDeleteResult {
+	r := c.ring.Load()
+	routes := c.routes(r, keys)
+	groups := make(map[int][]any)
+	for i := 0; i < c.opt.getReplicas(); i++ {
+		for n, ks := range groupKeys(routes, keys, i) {
+			groups[n] = append(groups[n], ks...)
+		}
+	}
+	deleted := make(map[any]int, len(keys))
+	errs := make(map[any][]error, len(keys))
+	var mu sync.Mutex
+	parallel(groups, func(n int, ks []any) {
+		s := r.shards[n]
+		ret := s.Cache.MDelete(ctx, ks)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, k := range ks {
+			kr := ret.Get(k)
+			deleted[k] = max(deleted[k], kr.Deleted)
+			if kr.Err != nil {
+				errs[k] = append(errs[k], shardErr(s, kr.Err))
+			}
+		}
+	})
+	result := make(fscache.MDeleteResult, len(keys))
+	for _, k := range keys {
+		result[k] = fscache.DeleteResult{Deleted: deleted[k], Err: errors.Join(errs[k]...)}
+	}
+	return result
+}
+
+// MHas 批量判断是否存在，按照分片分组后批量查询，不存在的 key 再从下一个副本批量查询
+func (c *Cache) MHas(ctx context.Context, keys []any) fscache.MHasResult {
+	r := c.ring.Load()
+	routes := c.routes(r, keys)
+	result := make(fscache.MHasResult, len(keys))
+	missing := keys
+	for i := 0; i < c.opt.getReplicas() && len(missing) > 0; i++ {
+		groups := groupKeys(routes, missing, i)
+		rets := make(map[int]fscache.MHasResult, len(groups))
+		var mu sync.Mutex
+		parallel(groups, func(n int, ks []any) {
+			ret := r.shards[n].Cache.MHas(ctx, ks)
+			mu.Lock()
+			rets[n] = ret
+			mu.Unlock()
+		})
+		next := make([]any, 0, len(missing))
+		for _, k := range missing {
+			if i >= len(routes[k]) {
+				continue
+			}
+			ret := rets[routes[k][i]].Get(k)
+			if ret.Has {
+				result[k] = ret
+				continue
+			}
+			if prev := result[k]; prev.Err == nil {
+				result[k] = ret
+			}
+			next = append(next, k)
+		}
+		missing = next
+	}
+	return result
+}
+
+// Reset 重置所有实现了 fscache.ReSetter 的分片
+func (c *Cache) Reset(ctx context.Context) error {
+	var errs []error
+	for _, s := range c.ring.Load().shards {
+		if rs, ok := s.Cache.(fscache.ReSetter); ok {
+			errs = append(errs, shardErr(s, rs.Reset(ctx)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// routes 每个 key 所在的分片
+func (c *Cache) routes(r *ring, keys []any) map[any][]int {
+	routes := make(map[any][]int, len(keys))
+	for _, k := range keys {
+		if _, has := routes[k]; !has {
+			routes[k] = c.nodes(r, k)
+		}
+	}
+	return routes
+}
+
+// groupKeys 将 keys 按照第 replica 个副本所在的分片分组
+func groupKeys(routes map[any][]int, keys []any, replica int) map[int][]any {
+	groups := make(map[int][]any)
+	for _, k := range keys {
+		if replica < len(routes[k]) {
+			n := routes[k][replica]
+			groups[n] = append(groups[n], k)
+		}
+	}
+	return groups
+}
+
+// parallel 对每个分组并发的执行 fn
+func parallel[T any](groups map[int]T, fn func(n int, group T)) {
+	if len(groups) == 1 {
+		for n, g := range groups {
+			fn(n, g)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for n, g := range groups {
+		wg.Add(1)
+		go func(n int, g T) {
+			defer wg.Done()
+			fn(n, g)
+		}(n, g)
+	}
+	wg.Wait()
+}
+
+var _ fscache.Cache = (*Cache)(nil)
+var _ fscache.ReSetter = (*Cache)(nil)
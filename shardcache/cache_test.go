@@ -0,0 +1,183 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package shardcache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/cachetest"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+type countCache struct {
+	fscache.Cache
+	mGets int
+}
+
+func (c *countCache) MGet(ctx context.Context, keys []any) fscache.MGetResult {
+	c.mGets++
+	return c.Cache.MGet(ctx, keys)
+}
+
+func newShards(t *testing.T, num int) []*Shard {
+	shards := make([]*Shard, num)
+	for i := 0; i < num; i++ {
+		lc, err := lrucache.New(&lrucache.Option{Capacity: 10000})
+		fst.NoError(t, err)
+		shards[i] = &Shard{
+			Name:  fmt.Sprintf("shard_%d", i),
+			Cache: &countCache{Cache: lc},
+		}
+	}
+	return shards
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&Option{Shards: newShards(t, 3)})
+	fst.NoError(t, err)
+	cachetest.CacheTest(t, c, "shardCache")
+
+	c, err = New(&Option{Shards: newShards(t, 3), Replicas: 2})
+	fst.NoError(t, err)
+	cachetest.CacheTest(t, c, "shardCache_replicas")
+
+	_, err = New(&Option{})
+	fst.Error(t, err)
+	shards := newShards(t, 2)
+	shards[1].Name = shards[0].Name
+	_, err = New(&Option{Shards: shards})
+	fst.Error(t, err)
+}
+
+func TestCache_Distribution(t *testing.T) {
+	shards := newShards(t, 4)
+	c, err := New(&Option{Shards: shards[:3]})
+	fst.NoError(t, err)
+
+	const total = 3000
+	locate := func() map[int]string {
+		result := make(map[int]string, total)
+		for i := 0; i < total; i++ {
+			names := c.Locate(i)
+			fst.Len(t, names, 1)
+			result[i] = names[0]
+		}
+		return result
+	}
+	before := locate()
+	counts := map[string]int{}
+	for _, name := range before {
+		counts[name]++
+	}
+	fst.Len(t, counts, 3)
+	for _, n := range counts {
+		fst.Greater(t, n, total/6)
+	}
+
+	// 添加分片后，只有部分 key 迁移到新的分片上
+	fst.NoError(t, c.AddShard(shards[3]))
+	fst.Error(t, c.AddShard(shards[3]))
+	after := locate()
+	var moved int
+	for k, name := range after {
+		if name != before[k] {
+			moved++
+			fst.Equal(t, shards[3].Name, name)
+		}
+	}
+	fst.Greater(t, moved, total/8)
+	fst.Less(t, moved, total/2)
+
+	// 删除分片后，只有该分片上的 key 重新分布
+	fst.NoError(t, c.RemoveShard(shards[3].Name))
+	fst.Equal(t, before, locate())
+	fst.NoError(t, c.RemoveShard(shards[0].Name))
+	for k, name := range locate() {
+		if before[k] != shards[0].Name {
+			fst.Equal(t, before[k], name)
+		}
+	}
+	fst.Equal(t, []string{shards[1].Name, shards[2].Name}, c.Shards())
+	fst.Error(t, c.RemoveShard("not_exists"))
+}
+
+func TestCache_Batch(t *testing.T) {
+	ctx := context.Background()
+	shards := newShards(t, 3)
+	c, err := New(&Option{Shards: shards})
+	fst.NoError(t, err)
+
+	kvs := fscache.KVData{}
+	var keys []any
+	for i := 0; i < 100; i++ {
+		kvs[i] = i
+		keys = append(keys, i)
+	}
+	fst.NoError(t, c.MSet(ctx, kvs, time.Minute).Err())
+	ret := c.MGet(ctx, keys)
+	fst.NoError(t, ret.Err())
+	fst.Len(t, ret, 100)
+	for _, s := range shards {
+		fst.Equal(t, 1, s.Cache.(*countCache).mGets)
+	}
+	fst.Equal(t, 100, c.MDelete(ctx, keys).Deleted())
+}
+
+func TestCache_ReadRepair(t *testing.T) {
+	ctx := context.Background()
+	shards := newShards(t, 3)
+	c, err := New(&Option{Shards: shards, Replicas: 2, RepairTTL: time.Minute})
+	fst.NoError(t, err)
+	byName := map[string]*Shard{}
+	for _, s := range shards {
+		byName[s.Name] = s
+	}
+
+	getValue := func(t *testing.T, ret fscache.GetResult) string {
+		var v string
+		has, err := ret.Value(&v)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		return v
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		fst.NoError(t, c.Set(ctx, "k1", fscache.RawValue(`"v1"`), time.Minute).Err)
+		names := c.Locate("k1")
+		fst.Len(t, names, 2)
+		primary := byName[names[0]].Cache
+		fst.Equal(t, 1, primary.Delete(ctx, "k1").Deleted)
+
+		fst.Equal(t, "v1", getValue(t, c.Get(ctx, "k1")))
+		fst.Equal(t, "v1", getValue(t, primary.Get(ctx, "k1")))
+	})
+
+	t.Run("MGet", func(t *testing.T) {
+		kvs := fscache.KVData{}
+		var keys []any
+		for i := 0; i < 20; i++ {
+			kvs[i] = fscache.RawValue(fmt.Sprintf(`"v%d"`, i))
+			keys = append(keys, i)
+		}
+		fst.NoError(t, c.MSet(ctx, kvs, time.Minute).Err())
+		for _, k := range keys {
+			primary := byName[c.Locate(k)[0]].Cache
+			fst.Equal(t, 1, primary.Delete(ctx, k).Deleted)
+		}
+		ret := c.MGet(ctx, append(keys, "not_exists"))
+		for _, k := range keys {
+			fst.Equal(t, fmt.Sprintf("v%d", k), getValue(t, ret.Get(k)))
+			primary := byName[c.Locate(k)[0]].Cache
+			fst.Equal(t, fmt.Sprintf("v%d", k), getValue(t, primary.Get(ctx, k)))
+		}
+		fst.ErrorIs(t, ret.Get("not_exists").Err, fscache.ErrNotExists)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package shardcache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/fsgo/fscache"
+)
+
+// Shard 一个分片
+type Shard struct {
+	// Cache 分片的缓存，必填
+	Cache fscache.Cache
+
+	// Name 分片的名称，必填，不能重复
+	// 用于计算分片在哈希环上的位置，修改后该分片的数据会重新分布
+	Name string
+
+	// Weight 权重，可选，默认为 1
+	// 分片的虚拟节点数为 Option.VirtualNodes * Weight
+	Weight int
+}
+
+func (s *Shard) getWeight() int {
+	if s.Weight > 0 {
+		return s.Weight
+	}
+	return 1
+}
+
+func (s *Shard) check() error {
+	if s.Name == "" {
+		return errors.New("shard.Name is empty")
+	}
+	if s.Cache == nil {
+		return fmt.Errorf("shard %q: Cache is nil", s.Name)
+	}
+	if s.Weight < 0 {
+		return fmt.Errorf("shard %q: Weight=%d, expect >= 0", s.Name, s.Weight)
+	}
+	return nil
+}
+
+// Option 分片缓存的配置
+type Option struct {
+	// Hash 计算 key 和虚拟节点哈希值的方法，可选，默认为 xxhash
+	Hash func(str string) uint64
+
+	// Shards 所有的分片，必填
+	Shards []*Shard
+
+	// Replicas 副本数，可选，默认为 1
+	// 大于 1 时，每个 key 会写入哈希环上顺时针方向的 Replicas 个不同的分片，
+	// 读取时按顺序读取，直到读取成功
+	Replicas int
+
+	// VirtualNodes 每个分片的虚拟节点数，可选，默认为 160
+	VirtualNodes int
+
+	// RepairTTL 读修复时写入的有效期，可选，为 0 时不做读修复
+	// 当 Replicas 大于 1 时，若从后面的副本读取到值，会将值写回到缺失该值的副本中；
+	// 只有 GetResult 中包含编码后的 Payload 才能写回，所以各分片需要使用相同的 Codec
+	RepairTTL time.Duration
+}
+
+// Check 检查配置是否正确
+func (o *Option) Check() error {
+	if len(o.Shards) == 0 {
+		return errors.New("option.Shards is empty")
+	}
+	if err := checkShards(o.Shards); err != nil {
+		return err
+	}
+	if o.Replicas < 0 {
+		return fmt.Errorf("option.Replicas=%d, expect >= 0", o.Replicas)
+	}
+	if o.VirtualNodes < 0 {
+		return fmt.Errorf("option.VirtualNodes=%d, expect >= 0", o.VirtualNodes)
+	}
+	return nil
+}
+
+func checkShards(shards []*Shard) error {
+	names := make(map[string]bool, len(shards))
+	for _, s := range shards {
+		if s == nil {
+			return errors.New("shard is nil")
+		}
+		if err := s.check(); err != nil {
+			return err
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate shard %q", s.Name)
+		}
+		names[s.Name] = true
+	}
+	return nil
+}
+
+func (o *Option) getReplicas() int {
+	if o.Replicas > 0 {
+		return o.Replicas
+	}
+	return 1
+}
+
+func (o *Option) getVirtualNodes() int {
+	if o.VirtualNodes > 0 {
+		return o.VirtualNodes
+	}
+	return 160
+}
+
+func (o *Option) hash(str string) uint64 {
+	if o.Hash != nil {
+		return o.Hash(str)
+	}
+	return xxhash.Sum64String(str)
+}
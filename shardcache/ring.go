@@ -0,0 +1,80 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package shardcache
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ring 一致性哈希环，创建后只读
+type ring struct {
+	shards []*Shard
+
+	// hashes 所有虚拟节点的哈希值，升序
+	hashes []uint64
+
+	// owners 和 hashes 一一对应，为虚拟节点所属的分片在 shards 中的序号
+	owners []int
+}
+
+func newRing(shards []*Shard, opt *Option) *ring {
+	r := &ring{
+		shards: shards,
+	}
+	type vnode struct {
+		hash  uint64
+		owner int
+	}
+	var vnodes []vnode
+	for i, s := range shards {
+		num := opt.getVirtualNodes() * s.getWeight()
+		for j := 0; j < num; j++ {
+			h := opt.hash(s.Name + "#" + strconv.Itoa(j))
+			vnodes = append(vnodes, vnode{hash: h, owner: i})
+		}
+	}
+	sort.Slice(vnodes, func(i, j int) bool {
+		if vnodes[i].hash == vnodes[j].hash {
+			// 哈希冲突时，按照名称排序，保证和分片的顺序无关
+			return shards[vnodes[i].owner].Name < shards[vnodes[j].owner].Name
+		}
+		return vnodes[i].hash < vnodes[j].hash
+	})
+	r.hashes = make([]uint64, len(vnodes))
+	r.owners = make([]int, len(vnodes))
+	for i, v := range vnodes {
+		r.hashes[i] = v.hash
+		r.owners[i] = v.owner
+	}
+	return r
+}
+
+// lookup 从 hash 的位置顺时针查找 n 个不同的分片，返回分片的序号
+func (r *ring) lookup(hash uint64, n int) []int {
+	if n > len(r.shards) {
+		n = len(r.shards)
+	}
+	result := make([]int, 0, n)
+	start := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= hash
+	})
+	for i := 0; i < len(r.hashes) && len(result) < n; i++ {
+		owner := r.owners[(start+i)%len(r.hashes)]
+		if !containsInt(result, owner) {
+			result = append(result, owner)
+		}
+	}
+	return result
+}
+
+func containsInt(arr []int, v int) bool {
+	for _, a := range arr {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RawValue 已编码的缓存值
+// Set 时若 value 为 RawValue，会直接存储，不会再使用 Codec 编码，
+// 读取时使用 Codec 解码，所以需要和缓存使用的 Codec 一致。
+// 用于从快照(Dump)中恢复缓存
+type RawValue []byte
+
+// MarshalValue 编码缓存值，若 value 为 RawValue，直接返回
+func MarshalValue(encode MarshalFunc, value any) ([]byte, error) {
+	if rv, ok := value.(RawValue); ok {
+		return rv, nil
+	}
+	return encode(value)
+}
+
+// Dumper 导出缓存的快照
+// 如内存缓存，可以实现该接口，在服务重启前导出，启动后使用 Loader 或者 LoadSnapshot 恢复
+type Dumper interface {
+	// Dump 将所有未过期的缓存写入 w
+	Dump(ctx context.Context, w io.Writer) error
+}
+
+// Loader 从快照中恢复缓存
+type Loader interface {
+	// Load 从 r 中读取 Dump 导出的快照，写入缓存
+	Load(ctx context.Context, r io.Reader) error
+}
+
+const (
+	snapshotMagic = "fscache_snapshot"
+
+	// snapshotVersion 快照格式的版本
+	snapshotVersion = 1
+)
+
+// NoExpireTTL 快照中不过期的缓存，恢复时使用的有效期
+const NoExpireTTL = 365 * 24 * time.Hour
+
+// snapshotHeader 快照的头部
+type snapshotHeader struct {
+	Magic    string
+	Version  int
+	CreateAt time.Time
+}
+
+// SnapshotRecord 快照中的一条缓存
+type SnapshotRecord struct {
+	// Key 缓存的 key，需要是 gob 可以编码的类型，非内置的类型需要使用 gob.Register 注册
+	Key any
+
+	// ExpireAt 过期时间，为零值时表示不过期
+	ExpireAt time.Time
+
+	// Payload 使用 Codec 编码后的值
+	Payload []byte
+}
+
+// TTL 剩余的有效期，不过期时返回 NoExpireTTL
+func (r *SnapshotRecord) TTL() time.Duration {
+	if r.ExpireAt.IsZero() {
+		return NoExpireTTL
+	}
+	return time.Until(r.ExpireAt)
+}
+
+// SnapshotWriter 写入快照
+//
+// 快照使用 gob 编码，格式为：头部(版本等信息) + 多条 SnapshotRecord，
+// 和具体的缓存实现无关，所以可以恢复到其他类型的缓存中
+type SnapshotWriter struct {
+	enc *gob.Encoder
+}
+
+// NewSnapshotWriter 创建快照的 writer，会先写入头部
+func NewSnapshotWriter(w io.Writer) (*SnapshotWriter, error) {
+	enc := gob.NewEncoder(w)
+	h := &snapshotHeader{
+		Magic:    snapshotMagic,
+		Version:  snapshotVersion,
+		CreateAt: time.Now(),
+	}
+	if err := enc.Encode(h); err != nil {
+		return nil, err
+	}
+	return &SnapshotWriter{enc: enc}, nil
+}
+
+// Write 写入一条缓存
+func (sw *SnapshotWriter) Write(rec *SnapshotRecord) error {
+	if err := sw.enc.Encode(rec); err != nil {
+		return fmt.Errorf("encode key %v failed: %w", rec.Key, err)
+	}
+	return nil
+}
+
+// SnapshotReader 读取快照
+type SnapshotReader struct {
+	dec *gob.Decoder
+}
+
+// NewSnapshotReader 创建快照的 reader，会先读取并校验头部
+func NewSnapshotReader(r io.Reader) (*SnapshotReader, error) {
+	dec := gob.NewDecoder(r)
+	var h snapshotHeader
+	if err := dec.Decode(&h); err != nil {
+		return nil, fmt.Errorf("read snapshot header failed: %w", err)
+	}
+	if h.Magic != snapshotMagic {
+		return nil, errors.New("invalid snapshot")
+	}
+	if h.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", h.Version)
+	}
+	return &SnapshotReader{dec: dec}, nil
+}
+
+// Next 读取下一条缓存，读取完成时返回 io.EOF
+func (sr *SnapshotReader) Next() (*SnapshotRecord, error) {
+	rec := &SnapshotRecord{}
+	if err := sr.dec.Decode(rec); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("read snapshot failed: %w", err)
+	}
+	return rec, nil
+}
+
+// Range 依次读取所有未过期的缓存，fn 返回 error 时停止
+func (sr *SnapshotReader) Range(ctx context.Context, fn func(rec *SnapshotRecord) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rec, err := sr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if rec.TTL() <= 0 {
+			continue
+		}
+		if err = fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// LoadSnapshot 将快照中未过期的缓存恢复到 c 中，写入时的值为 RawValue，有效期为剩余的有效期
+// c 需要支持 RawValue，且使用的 Codec 和导出快照的缓存一致
+func LoadSnapshot(ctx context.Context, r io.Reader, c SCache) error {
+	sr, err := NewSnapshotReader(r)
+	if err != nil {
+		return err
+	}
+	return sr.Range(ctx, func(rec *SnapshotRecord) error {
+		if ret := c.Set(ctx, rec.Key, RawValue(rec.Payload), rec.TTL()); ret.Err != nil {
+			return fmt.Errorf("set key %v failed: %w", rec.Key, ret.Err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+)
+
+func TestSnapshot(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sw, err := NewSnapshotWriter(buf)
+	fst.NoError(t, err)
+	records := []*SnapshotRecord{
+		{Key: "k1", ExpireAt: time.Now().Add(time.Minute), Payload: []byte(`"v1"`)},
+		{Key: 2, Payload: []byte(`2`)},
+		{Key: "expired", ExpireAt: time.Now().Add(-time.Second), Payload: []byte(`1`)},
+	}
+	for _, rec := range records {
+		fst.NoError(t, sw.Write(rec))
+	}
+
+	t.Run("reader", func(t *testing.T) {
+		sr, err := NewSnapshotReader(bytes.NewReader(buf.Bytes()))
+		fst.NoError(t, err)
+		for _, want := range records {
+			got, err := sr.Next()
+			fst.NoError(t, err)
+			fst.Equal(t, want.Key, got.Key)
+			fst.Equal(t, want.Payload, got.Payload)
+			fst.True(t, want.ExpireAt.Equal(got.ExpireAt))
+		}
+		_, err = sr.Next()
+		fst.Equal(t, io.EOF, err)
+		fst.Equal(t, NoExpireTTL, records[1].TTL())
+	})
+
+	t.Run("LoadSnapshot", func(t *testing.T) {
+		sc := &recordSCache{}
+		fst.NoError(t, LoadSnapshot(context.Background(), bytes.NewReader(buf.Bytes()), sc))
+		fst.Equal(t, 2, len(sc.values))
+		fst.Equal[any](t, RawValue(`"v1"`), sc.values["k1"])
+		fst.Equal[any](t, RawValue(`2`), sc.values[2])
+	})
+
+	t.Run("Template not Loader", func(t *testing.T) {
+		sc := &recordSCache{}
+		c := NewTemplate(sc, false)
+		_, ok := As[Loader](c)
+		fst.False(t, ok)
+		err := c.(Loader).Load(context.Background(), bytes.NewReader(buf.Bytes()))
+		fst.ErrorIs(t, err, errors.ErrUnsupported)
+		fst.Equal(t, 0, len(sc.values))
+		fst.ErrorIs(t, c.(Dumper).Dump(context.Background(), io.Discard), errors.ErrUnsupported)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewSnapshotReader(bytes.NewReader(nil))
+		fst.Error(t, err)
+		_, err = NewSnapshotReader(bytes.NewReader(buf.Bytes()[:10]))
+		fst.Error(t, err)
+	})
+
+	t.Run("MarshalValue", func(t *testing.T) {
+		bf, err := MarshalValue(JSONCodec.Marshal, RawValue("raw"))
+		fst.NoError(t, err)
+		fst.Equal(t, "raw", string(bf))
+		bf, err = MarshalValue(JSONCodec.Marshal, "raw")
+		fst.NoError(t, err)
+		fst.Equal(t, `"raw"`, string(bf))
+	})
+}
+
+// recordSCache 记录 Set 的值
+type recordSCache struct {
+	staticSCache
+	values map[any]any
+}
+
+func (r *recordSCache) Set(ctx context.Context, key any, value any, ttl time.Duration) SetResult {
+	if r.values == nil {
+		r.values = map[any]any{}
+	}
+	r.values[key] = value
+	return setRetSuc
+}
@@ -0,0 +1,255 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// TagCache 支持按照 tag 批量失效的缓存
+//
+// 每个 tag 都有一个版本号，和数据一起存储在 SCache 中。
+// 写入数据时会记录下所有 tag 当时的版本号，读取时若任意一个 tag 的版本号已变化，则视为缓存不存在。
+// 调用 InvalidateTags 时只需要更新 tag 的版本号，不需要遍历数据。
+// 写入数据时，不存在的 tag 会先写入一个新的版本号，所以 tag 的版本号被淘汰或者过期后，
+// 读取时 tag 的版本号不一致，之前写入的数据都会失效。
+type TagCache struct {
+	// SCache 实际存储数据和 tag 版本号的缓存，必填
+	SCache SCache
+
+	// Codec 对 value 进行编解码，可选，默认为 DefaultCodec
+	Codec Codec
+
+	// TagTTL tag 版本号的有效期，可选，默认为 7 天
+	// 应大于带 tag 的缓存数据的有效期
+	TagTTL time.Duration
+}
+
+// tagEntry 带 tag 的缓存数据实际存储的内容
+type tagEntry struct {
+	// Tags tag 写入时的版本号
+	Tags map[string]int64
+
+	// Payload 使用 Codec 编码后的 value
+	Payload []byte
+}
+
+const tagKeyPrefix = "fscache_tag:"
+
+var tagVersion atomic.Int64
+
+// newTagVersion 生成一个新的 tag 版本号，在当前进程内保证单调递增
+func newTagVersion() int64 {
+	for {
+		last := tagVersion.Load()
+		next := time.Now().UnixNano()
+		if next <= last {
+			next = last + 1
+		}
+		if tagVersion.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+func (tc *TagCache) getCodec() Codec {
+	if tc.Codec == nil {
+		return DefaultCodec
+	}
+	return tc.Codec
+}
+
+func (tc *TagCache) getTagTTL() time.Duration {
+	if tc.TagTTL == 0 {
+		return 7 * 24 * time.Hour
+	}
+	return tc.TagTTL
+}
+
+// Get 查询单个，若 tag 已失效，会返回 ErrNotExists
+func (tc *TagCache) Get(ctx context.Context, key any) GetResult {
+	ret := tc.SCache.Get(ctx, key)
+	if ret.Err != nil {
+		return ret
+	}
+	var entry tagEntry
+	if _, err := ret.Value(&entry); err != nil {
+		return GetResult{Err: err}
+	}
+	if len(entry.Tags) > 0 {
+		names := make([]string, 0, len(entry.Tags))
+		for name := range entry.Tags {
+			names = append(names, name)
+		}
+		versions, err := tc.tagVersions(ctx, names)
+		if err != nil {
+			return GetResult{Err: err}
+		}
+		for name, version := range entry.Tags {
+			if versions[name] != version {
+				tc.SCache.Delete(ctx, key)
+				return getRetNotExists
+			}
+		}
+	}
+	return GetResult{Payload: entry.Payload, UnmarshalFunc: tc.getCodec().Unmarshal}
+}
+
+// Set 设置并附带有效期，不带 tag
+func (tc *TagCache) Set(ctx context.Context, key any, value any, ttl time.Duration) SetResult {
+	return tc.SetWithTags(ctx, key, value, ttl)
+}
+
+// SetWithTags 设置并附带有效期和 tag
+func (tc *TagCache) SetWithTags(ctx context.Context, key any, value any, ttl time.Duration, tags ...string) SetResult {
+	versions, err := tc.initTagVersions(ctx, tags)
+	if err != nil {
+		return SetResult{Err: err}
+	}
+	entry, err := tc.newEntry(value, versions)
+	if err != nil {
+		return SetResult{Err: err}
+	}
+	return tc.SCache.Set(ctx, key, entry, ttl)
+}
+
+// MSetWithTags 批量设置并附带有效期和 tag，所有的 key 都使用相同的 tag
+func (tc *TagCache) MSetWithTags(ctx context.Context, kvs KVData, ttl time.Duration, tags ...string) MSetResult {
+	versions, err := tc.initTagVersions(ctx, tags)
+	if err != nil {
+		return tc.mSetError(kvs, err)
+	}
+	entries := make(KVData, len(kvs))
+	result := make(MSetResult, len(kvs))
+	for k, v := range kvs {
+		entry, err := tc.newEntry(v, versions)
+		if err != nil {
+			result[k] = SetResult{Err: err}
+			continue
+		}
+		entries[k] = entry
+	}
+	for k, ret := range NewMCacheBySCache(tc.SCache, false).MSet(ctx, entries, ttl) {
+		result[k] = ret
+	}
+	return result
+}
+
+func (tc *TagCache) mSetError(kvs KVData, err error) MSetResult {
+	result := make(MSetResult, len(kvs))
+	for k := range kvs {
+		result[k] = SetResult{Err: err}
+	}
+	return result
+}
+
+func (tc *TagCache) newEntry(value any, versions map[string]int64) (tagEntry, error) {
+	payload, err := tc.getCodec().Marshal(value)
+	if err != nil {
+		return tagEntry{}, err
+	}
+	return tagEntry{Tags: versions, Payload: payload}, nil
+}
+
+// Has 判断是否存在，若 tag 已失效，返回不存在
+func (tc *TagCache) Has(ctx context.Context, key any) HasResult {
+	ret := tc.Get(ctx, key)
+	if ret.Err == nil {
+		return HasResult{Has: true}
+	}
+	return HasResult{Err: ret.Err}
+}
+
+// Delete 删除指定的 key
+func (tc *TagCache) Delete(ctx context.Context, key any) DeleteResult {
+	return tc.SCache.Delete(ctx, key)
+}
+
+// InvalidateTags 让所有带有指定 tag 的缓存失效
+func (tc *TagCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	kvs := make(KVData, len(tags))
+	for _, name := range tags {
+		kvs[tagKeyPrefix+name] = newTagVersion()
+	}
+	return NewMCacheBySCache(tc.SCache, false).MSet(ctx, kvs, tc.getTagTTL()).Err()
+}
+
+// tagVersions 批量读取 tag 当前的版本号，不存在的 tag 版本号为 0
+func (tc *TagCache) tagVersions(ctx context.Context, tags []string) (map[string]int64, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	keys := make([]any, 0, len(tags))
+	for _, name := range tags {
+		keys = append(keys, tagKeyPrefix+name)
+	}
+	rets := NewMCacheBySCache(tc.SCache, false).MGet(ctx, keys)
+	versions := make(map[string]int64, len(tags))
+	for _, name := range tags {
+		var version int64
+		if _, err := rets.Get(tagKeyPrefix + name).Value(&version); err != nil {
+			return nil, err
+		}
+		versions[name] = version
+	}
+	return versions, nil
+}
+
+// initTagVersions 批量读取 tag 当前的版本号，不存在的 tag 会写入一个新的版本号
+// SCache 实现了 Atomic 时使用 SetNX 写入，以免并发写入时覆盖其他调用写入的版本号
+func (tc *TagCache) initTagVersions(ctx context.Context, tags []string) (map[string]int64, error) {
+	versions, err := tc.tagVersions(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	ac, _ := As[Atomic](tc.SCache)
+	var exists []string
+	for _, name := range tags {
+		if versions[name] != 0 {
+			continue
+		}
+		key := tagKeyPrefix + name
+		version := newTagVersion()
+		if ac == nil {
+			if err = tc.SCache.Set(ctx, key, version, tc.getTagTTL()).Err; err != nil {
+				return nil, err
+			}
+			versions[name] = version
+			continue
+		}
+		ok, err := ac.SetNX(ctx, key, version, tc.getTagTTL())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			versions[name] = version
+		} else {
+			exists = append(exists, name)
+		}
+	}
+	if len(exists) == 0 {
+		return versions, nil
+	}
+	// 已被其他调用写入，重新读取
+	current, err := tc.tagVersions(ctx, exists)
+	if err != nil {
+		return nil, err
+	}
+	for name, version := range current {
+		if version == 0 {
+			return nil, fmt.Errorf("version of tag %q not found", name)
+		}
+		versions[name] = version
+	}
+	return versions, nil
+}
+
+var _ SCache = (*TagCache)(nil)
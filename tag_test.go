@@ -0,0 +1,106 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/chains"
+	"github.com/fsgo/fscache/filecache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+func TestTagCache(t *testing.T) {
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+
+	dir := "./testdata/tag_cache_dir/"
+	defer os.RemoveAll("./testdata/")
+	fc, err := filecache.New(&filecache.Option{Dir: dir})
+	fst.NoError(t, err)
+
+	lc2, err := lrucache.New(&lrucache.Option{Capacity: 100})
+	fst.NoError(t, err)
+	cc := chains.New(&chains.Cache{Cache: lc2}, &chains.Cache{Cache: fc})
+
+	caches := map[string]fscache.SCache{
+		"lruCache":  lc,
+		"fileCache": fc,
+		"chains":    cc,
+	}
+	for name, sc := range caches {
+		t.Run(name, func(t *testing.T) {
+			checkTagCache(t, &fscache.TagCache{SCache: sc})
+		})
+	}
+}
+
+func checkTagCache(t *testing.T, tc *fscache.TagCache) {
+	ctx := context.Background()
+	fst.NoError(t, tc.SetWithTags(ctx, "user_1_profile", "profile", time.Minute, "user_1").Err)
+	fst.NoError(t, tc.SetWithTags(ctx, "user_1_orders", 3, time.Minute, "user_1", "orders").Err)
+	fst.NoError(t, tc.SetWithTags(ctx, "user_2_profile", "profile2", time.Minute, "user_2").Err)
+	fst.NoError(t, tc.Set(ctx, "no_tag", "hello", time.Minute).Err)
+
+	var str string
+	has, err := tc.Get(ctx, "user_1_profile").Value(&str)
+	fst.NoError(t, err)
+	fst.True(t, has)
+	fst.Equal(t, "profile", str)
+
+	var num int
+	has, err = tc.Get(ctx, "user_1_orders").Value(&num)
+	fst.NoError(t, err)
+	fst.True(t, has)
+	fst.Equal(t, 3, num)
+
+	fst.NoError(t, tc.InvalidateTags(ctx, "user_1"))
+
+	fst.Equal(t, fscache.ErrNotExists, tc.Get(ctx, "user_1_profile").Err)
+	fst.Equal(t, fscache.ErrNotExists, tc.Get(ctx, "user_1_orders").Err)
+	fst.False(t, tc.Has(ctx, "user_1_orders").Has)
+
+	has, err = tc.Get(ctx, "user_2_profile").Value(&str)
+	fst.NoError(t, err)
+	fst.True(t, has)
+	fst.Equal(t, "profile2", str)
+	fst.True(t, tc.Has(ctx, "no_tag").Has)
+
+	// 失效后重新写入，可以正常读取
+	fst.NoError(t, tc.SetWithTags(ctx, "user_1_profile", "new", time.Minute, "user_1").Err)
+	has, err = tc.Get(ctx, "user_1_profile").Value(&str)
+	fst.NoError(t, err)
+	fst.True(t, has)
+	fst.Equal(t, "new", str)
+
+	ret := tc.MSetWithTags(ctx, fscache.KVData{"m1": 1, "m2": 2}, time.Minute, "batch")
+	fst.NoError(t, ret.Err())
+	fst.True(t, tc.Has(ctx, "m1").Has)
+	fst.NoError(t, tc.InvalidateTags(ctx, "batch"))
+	fst.False(t, tc.Has(ctx, "m1").Has)
+	fst.False(t, tc.Has(ctx, "m2").Has)
+}
+
+func TestTagCache_TagEvicted(t *testing.T) {
+	ctx := context.Background()
+	lc, err := lrucache.New(&lrucache.Option{Capacity: 2})
+	fst.NoError(t, err)
+	tc := &fscache.TagCache{SCache: lc}
+	fst.NoError(t, tc.SetWithTags(ctx, "k1", "v1", time.Minute, "t1").Err)
+	fst.NoError(t, tc.InvalidateTags(ctx, "t1"))
+
+	// 访问 k1 后，写入新的 key 会淘汰 tag 的版本号
+	fst.NoError(t, lc.Get(ctx, "k1").Err)
+	fst.NoError(t, lc.Set(ctx, "other", 1, time.Minute).Err)
+	fst.NoError(t, lc.Get(ctx, "k1").Err)
+
+	fst.ErrorIs(t, tc.Get(ctx, "k1").Err, fscache.ErrNotExists)
+}
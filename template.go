@@ -7,6 +7,8 @@ package fscache
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"time"
 )
 
@@ -17,6 +19,8 @@ type Template struct {
 }
 
 // NewTemplate 利用一个简单的缓存类，创建一个包含批量接口的缓存类
+// 返回的 *Template 实现了所有的可选接口，sc 未实现时返回 errors.ErrUnsupported，
+// 可以使用 As 判断 sc 实际实现了哪些可选接口
 func NewTemplate(sc SCache, concurrent bool) Cache {
 	return &Template{
 		SCache: sc,
@@ -24,6 +28,38 @@ func NewTemplate(sc SCache, concurrent bool) Cache {
 	}
 }
 
+// NewTemplateWithOption 利用一个简单的缓存类，创建一个包含批量接口的缓存类，可以配置批量接口的并发数等参数
+func NewTemplateWithOption(sc SCache, opt *MCacheOption) Cache {
+	return &Template{
+		SCache: sc,
+		MCache: NewMCacheBySCacheWithOption(sc, opt),
+	}
+}
+
+// As 判断缓存 c 实际是否实现了可选接口 T(如 Scanner、Atomic)，并返回其实现
+// 若 c 有 Unwrap() SCache 方法(如 *Template)，会使用其包装的缓存判断
+func As[T any](c any) (T, bool) {
+	for {
+		u, ok := c.(interface{ Unwrap() SCache })
+		if !ok {
+			break
+		}
+		c = u.Unwrap()
+	}
+	t, ok := c.(T)
+	return t, ok
+}
+
+// unsupported SCache 未实现可选接口时返回的错误
+func (ct *Template) unsupported(name string) error {
+	return fmt.Errorf("%w: %T is not %s", errors.ErrUnsupported, ct.SCache, name)
+}
+
+// Unwrap 返回包装的 SCache
+func (ct *Template) Unwrap() SCache {
+	return ct.SCache
+}
+
 // Get 读取
 func (ct *Template) Get(ctx context.Context, key any) GetResult {
 	return ct.SCache.Get(ctx, key)
@@ -72,5 +108,67 @@ func (ct *Template) Reset(ctx context.Context) error {
 	return errors.New("not implemented ReSetter")
 }
 
+// Scan 遍历缓存的 key，SCache 需要实现 Scanner
+func (ct *Template) Scan(ctx context.Context, prefix string, fn func(key any, meta ScanMeta) bool) error {
+	if sc, ok := ct.SCache.(Scanner); ok {
+		return sc.Scan(ctx, prefix, fn)
+	}
+	return ct.unsupported("Scanner")
+}
+
+// Dump 导出缓存的快照，SCache 需要实现 Dumper
+func (ct *Template) Dump(ctx context.Context, w io.Writer) error {
+	if d, ok := ct.SCache.(Dumper); ok {
+		return d.Dump(ctx, w)
+	}
+	return ct.unsupported("Dumper")
+}
+
+// Load 从快照中恢复缓存，SCache 需要实现 Loader
+// 快照中的值为 RawValue，不支持 RawValue 的 SCache 写入后数据会错误，所以不会使用 LoadSnapshot
+func (ct *Template) Load(ctx context.Context, r io.Reader) error {
+	if l, ok := ct.SCache.(Loader); ok {
+		return l.Load(ctx, r)
+	}
+	return ct.unsupported("Loader")
+}
+
+// Incr 将 key 的整数值加上 delta，SCache 需要实现 Atomic
+func (ct *Template) Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error) {
+	if a, ok := ct.SCache.(Atomic); ok {
+		return a.Incr(ctx, key, delta, ttl)
+	}
+	return 0, ct.unsupported("Atomic")
+}
+
+// SetNX 当 key 不存在时写入，SCache 需要实现 Atomic
+func (ct *Template) SetNX(ctx context.Context, key any, value any, ttl time.Duration) (bool, error) {
+	if a, ok := ct.SCache.(Atomic); ok {
+		return a.SetNX(ctx, key, value, ttl)
+	}
+	return false, ct.unsupported("Atomic")
+}
+
+// CompareAndSwap 当 key 的版本号和 cas 一致时写入，SCache 需要实现 Atomic
+func (ct *Template) CompareAndSwap(ctx context.Context, key any, cas uint64, value any, ttl time.Duration) (bool, error) {
+	if a, ok := ct.SCache.(Atomic); ok {
+		return a.CompareAndSwap(ctx, key, cas, value, ttl)
+	}
+	return false, ct.unsupported("Atomic")
+}
+
+// Close 关闭缓存，若 SCache 没有实现 io.Closer，直接返回 nil
+func (ct *Template) Close() error {
+	if c, ok := ct.SCache.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 var _ Cache = (*Template)(nil)
 var _ ReSetter = (*Template)(nil)
+var _ io.Closer = (*Template)(nil)
+var _ Scanner = (*Template)(nil)
+var _ Dumper = (*Template)(nil)
+var _ Loader = (*Template)(nil)
+var _ Atomic = (*Template)(nil)
@@ -0,0 +1,113 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+)
+
+type optScanner struct{}
+
+func (optScanner) Scan(ctx context.Context, prefix string, fn func(key any, meta ScanMeta) bool) error {
+	fn(prefix+"k1", ScanMeta{})
+	return nil
+}
+
+type optDumper struct{}
+
+func (optDumper) Dump(ctx context.Context, w io.Writer) error {
+	return nil
+}
+
+type optLoader struct{}
+
+func (optLoader) Load(ctx context.Context, r io.Reader) error {
+	return nil
+}
+
+type optAtomic struct{}
+
+func (optAtomic) Incr(ctx context.Context, key any, delta int64, ttl time.Duration) (int64, error) {
+	return delta, nil
+}
+
+func (optAtomic) SetNX(ctx context.Context, key any, value any, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (optAtomic) CompareAndSwap(ctx context.Context, key any, cas uint64, value any, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func TestNewTemplate(t *testing.T) {
+	ctx := context.Background()
+	check := func(t *testing.T, c Cache, scanner, dumper, loader, atomic bool) {
+		_, ok := c.(*Template)
+		fst.True(t, ok)
+		_, ok = As[Scanner](c)
+		fst.Equal(t, scanner, ok)
+		_, ok = As[Dumper](c)
+		fst.Equal(t, dumper, ok)
+		_, ok = As[Loader](c)
+		fst.Equal(t, loader, ok)
+		_, ok = As[Atomic](c)
+		fst.Equal(t, atomic, ok)
+	}
+
+	t.Run("none", func(t *testing.T) {
+		c := NewTemplate(&staticSCache{}, false)
+		check(t, c, false, false, false, false)
+		err := c.(Scanner).Scan(ctx, "p_", func(key any, meta ScanMeta) bool {
+			return true
+		})
+		fst.ErrorIs(t, err, errors.ErrUnsupported)
+		_, err = c.(Atomic).Incr(ctx, "k1", 1, time.Minute)
+		fst.ErrorIs(t, err, errors.ErrUnsupported)
+	})
+
+	t.Run("Scanner", func(t *testing.T) {
+		c := NewTemplate(&struct {
+			staticSCache
+			optScanner
+		}{}, false)
+		check(t, c, true, false, false, false)
+		var keys []any
+		err := c.(Scanner).Scan(ctx, "p_", func(key any, meta ScanMeta) bool {
+			keys = append(keys, key)
+			return true
+		})
+		fst.NoError(t, err)
+		fst.Equal(t, []any{"p_k1"}, keys)
+	})
+
+	t.Run("Loader and Atomic", func(t *testing.T) {
+		c := NewTemplateWithOption(&struct {
+			staticSCache
+			optLoader
+			optAtomic
+		}{}, nil)
+		check(t, c, false, false, true, true)
+		num, err := c.(Atomic).Incr(ctx, "k1", 3, time.Minute)
+		fst.NoError(t, err)
+		fst.Equal(t, int64(3), num)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		c := NewTemplate(&struct {
+			staticSCache
+			optScanner
+			optDumper
+			optLoader
+			optAtomic
+		}{}, true)
+		check(t, c, true, true, true, true)
+	})
+}
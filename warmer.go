@@ -0,0 +1,306 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KeySource 需要预热的 key 的来源，依次使用 key 调用 yield，yield 返回 false 时应停止并返回
+type KeySource func(ctx context.Context, yield func(key any) bool) error
+
+// SliceKeySource 从 slice 中读取 key
+func SliceKeySource(keys []any) KeySource {
+	return func(ctx context.Context, yield func(key any) bool) error {
+		for _, key := range keys {
+			if !yield(key) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// ChanKeySource 从 chan 中读取 key，直到 chan 被关闭
+func ChanKeySource(ch <-chan any) KeySource {
+	return func(ctx context.Context, yield func(key any) bool) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case key, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				if !yield(key) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// ScannerKeySource 遍历 Scanner 中以 prefix 开头的 key，如使用另一个缓存中的 key 预热
+func ScannerKeySource(sc Scanner, prefix string) KeySource {
+	return func(ctx context.Context, yield func(key any) bool) error {
+		return sc.Scan(ctx, prefix, func(key any, _ ScanMeta) bool {
+			return yield(key)
+		})
+	}
+}
+
+// WarmStats 预热的统计信息
+type WarmStats struct {
+	// Keys 从 KeySource 中读取的 key 的数量
+	Keys int
+
+	// Stored 成功写入缓存的数量
+	Stored int
+
+	// Missed Load 没有返回值的 key 的数量
+	Missed int
+
+	// Failed 加载或者写入缓存失败的 key 的数量
+	Failed int
+
+	// Elapsed 已用的时间
+	Elapsed time.Duration
+}
+
+// Warmer 缓存预热：从 KeySource 中读取 key，分批使用 Load 加载后写入缓存
+type Warmer struct {
+	// Cache 需要预热的缓存，必填
+	// 若实现了 MSetter，会使用 MSet 批量写入
+	Cache SCache
+
+	// Load 批量加载缓存值，必填
+	// 返回的 KVData 中没有的 key，不会写入缓存(计入 Missed)；返回 error 时，整批 key 都计入 Failed
+	Load func(ctx context.Context, keys []any) (KVData, error)
+
+	// TTL 缓存的有效期，必填
+	TTL time.Duration
+
+	// BatchSize 每批加载的 key 的数量，可选，默认为 100
+	BatchSize int
+
+	// Concurrency 同时加载的批次数，可选，默认为 1
+	Concurrency int
+
+	// Rate 每秒最多加载的 key 的数量，可选，为 0 时不限制
+	Rate float64
+
+	// Progress 进度回调，可选，每完成一批调用一次，不会被并发调用
+	Progress func(stats WarmStats)
+}
+
+func (w *Warmer) getBatchSize() int {
+	if w.BatchSize <= 0 {
+		return 100
+	}
+	return w.BatchSize
+}
+
+func (w *Warmer) getConcurrency() int {
+	if w.Concurrency <= 0 {
+		return 1
+	}
+	return w.Concurrency
+}
+
+// Check 检查是否正确
+func (w *Warmer) Check() error {
+	if w.Cache == nil {
+		return errors.New("warmer.Cache is nil")
+	}
+	if w.Load == nil {
+		return errors.New("warmer.Load is nil")
+	}
+	if w.TTL <= 0 {
+		return fmt.Errorf("warmer.TTL=%s, expect > 0", w.TTL)
+	}
+	return nil
+}
+
+// Run 从 src 中读取 key 并预热，直到 src 读取完成，或者 ctx 被取消
+// 某一批加载或者写入失败时，会继续预热其他的 key，返回的 error 为遇到的第一个错误
+func (w *Warmer) Run(ctx context.Context, src KeySource) (WarmStats, error) {
+	if err := w.Check(); err != nil {
+		return WarmStats{}, err
+	}
+	wr := &warmRun{
+		warmer: w,
+		start:  time.Now(),
+	}
+	if w.Rate > 0 {
+		wr.limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / w.Rate)}
+	}
+	return wr.run(ctx, src)
+}
+
+type warmRun struct {
+	warmer  *Warmer
+	limiter *rateLimiter
+	start   time.Time
+	err     error
+
+	stats WarmStats
+	mux   sync.Mutex
+}
+
+func (wr *warmRun) run(ctx context.Context, src KeySource) (WarmStats, error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []any)
+	var wg sync.WaitGroup
+	for i := 0; i < wr.warmer.getConcurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				wr.warm(runCtx, batch)
+			}
+		}()
+	}
+
+	size := wr.warmer.getBatchSize()
+	batch := make([]any, 0, size)
+	send := func() bool {
+		select {
+		case batches <- batch:
+			batch = make([]any, 0, size)
+			return true
+		case <-runCtx.Done():
+			return false
+		}
+	}
+	errSrc := src(runCtx, func(key any) bool {
+		wr.mux.Lock()
+		wr.stats.Keys++
+		wr.mux.Unlock()
+		batch = append(batch, key)
+		if len(batch) >= size {
+			return send()
+		}
+		return runCtx.Err() == nil
+	})
+	if len(batch) > 0 && runCtx.Err() == nil {
+		send()
+	}
+	close(batches)
+	wg.Wait()
+
+	wr.mux.Lock()
+	defer wr.mux.Unlock()
+	wr.stats.Elapsed = time.Since(wr.start)
+	if errSrc != nil {
+		return wr.stats, errSrc
+	}
+	if err := ctx.Err(); err != nil {
+		return wr.stats, err
+	}
+	return wr.stats, wr.err
+}
+
+func (wr *warmRun) warm(ctx context.Context, keys []any) {
+	var stored, failed int
+	var firstErr error
+	fail := func(n int, err error) {
+		failed += n
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if wr.limiter != nil {
+		if err := wr.limiter.wait(ctx, len(keys)); err != nil {
+			wr.done(0, 0, len(keys), err)
+			return
+		}
+	}
+	kvs, err := wr.warmer.Load(ctx, keys)
+	if err != nil {
+		wr.done(0, 0, len(keys), fmt.Errorf("load keys failed: %w", err))
+		return
+	}
+	if len(kvs) > 0 {
+		var ret MSetResult
+		if ms, ok := wr.warmer.Cache.(MSetter); ok {
+			ret = ms.MSet(ctx, kvs, wr.warmer.TTL)
+		} else {
+			ret = make(MSetResult, len(kvs))
+			for k, v := range kvs {
+				ret[k] = wr.warmer.Cache.Set(ctx, k, v, wr.warmer.TTL)
+			}
+		}
+		for k := range kvs {
+			if r := ret.Get(k); r.Err != nil {
+				fail(1, fmt.Errorf("set key %v failed: %w", k, r.Err))
+			} else {
+				stored++
+			}
+		}
+	}
+	var missed int
+	for _, k := range keys {
+		if _, ok := kvs[k]; !ok {
+			missed++
+		}
+	}
+	wr.done(stored, missed, failed, firstErr)
+}
+
+// done 更新统计信息并回调进度
+func (wr *warmRun) done(stored int, missed int, failed int, err error) {
+	wr.mux.Lock()
+	defer wr.mux.Unlock()
+	wr.stats.Stored += stored
+	wr.stats.Missed += missed
+	wr.stats.Failed += failed
+	if err != nil && wr.err == nil {
+		wr.err = err
+	}
+	if wr.warmer.Progress != nil {
+		stats := wr.stats
+		stats.Elapsed = time.Since(wr.start)
+		wr.warmer.Progress(stats)
+	}
+}
+
+// rateLimiter 简单的限流器，按照固定的间隔发放令牌
+type rateLimiter struct {
+	next     time.Time
+	interval time.Duration
+	mux      sync.Mutex
+}
+
+// wait 等待获取 n 个令牌
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mux.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(time.Duration(n) * l.interval)
+	l.mux.Unlock()
+
+	d := time.Until(at)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright(C) 2026 github.com/fsgo  All Rights Reserved.
+// Author: hidu <duv123@gmail.com>
+// Date: 2026/10/19
+
+package fscache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsgo/fst"
+
+	"github.com/fsgo/fscache"
+	"github.com/fsgo/fscache/lrucache"
+)
+
+// countMSetter 统计 MSet 的调用次数
+type countMSetter struct {
+	fscache.Cache
+	msets atomic.Int64
+}
+
+func (c *countMSetter) MSet(ctx context.Context, kvs fscache.KVData, ttl time.Duration) fscache.MSetResult {
+	c.msets.Add(1)
+	return c.Cache.MSet(ctx, kvs, ttl)
+}
+
+func TestWarmer(t *testing.T) {
+	newCache := func(t *testing.T) *countMSetter {
+		lc, err := lrucache.New(&lrucache.Option{Capacity: 1000})
+		fst.NoError(t, err)
+		return &countMSetter{Cache: lc}
+	}
+	keys := make([]any, 250)
+	for i := range keys {
+		keys[i] = i
+	}
+	errLoad := errors.New("load failed")
+	load := func(ctx context.Context, keys []any) (fscache.KVData, error) {
+		kvs := fscache.KVData{}
+		for _, k := range keys {
+			if k.(int) == 245 {
+				return nil, errLoad
+			}
+			if k.(int)%10 != 0 {
+				kvs[k] = k.(int) * 2
+			}
+		}
+		return kvs, nil
+	}
+
+	t.Run("run", func(t *testing.T) {
+		c := newCache(t)
+		var progress []fscache.WarmStats
+		w := &fscache.Warmer{
+			Cache:       c,
+			Load:        load,
+			TTL:         time.Minute,
+			BatchSize:   20,
+			Concurrency: 4,
+			Progress: func(stats fscache.WarmStats) {
+				progress = append(progress, stats)
+			},
+		}
+		stats, err := w.Run(context.Background(), fscache.SliceKeySource(keys))
+		fst.True(t, errors.Is(err, errLoad))
+		fst.Equal(t, 250, stats.Keys)
+		fst.Equal(t, 10, stats.Failed)
+		fst.Equal(t, 24, stats.Missed)
+		fst.Equal(t, 216, stats.Stored)
+		fst.Equal(t, int64(12), c.msets.Load())
+		fst.Equal(t, 13, len(progress))
+		fst.Equal(t, stats.Stored, progress[12].Stored)
+
+		var got int
+		has, err := c.Get(context.Background(), 11).Value(&got)
+		fst.NoError(t, err)
+		fst.True(t, has)
+		fst.Equal(t, 22, got)
+		fst.Equal(t, fscache.ErrNotExists, c.Get(context.Background(), 10).Err)
+	})
+
+	t.Run("scanner and rate", func(t *testing.T) {
+		src := newCache(t)
+		for i := 0; i < 50; i++ {
+			fst.NoError(t, src.Set(context.Background(), i+1, i, time.Minute).Err)
+		}
+		w := &fscache.Warmer{
+			Cache:     newCache(t),
+			Load:      load,
+			TTL:       time.Minute,
+			BatchSize: 10,
+			Rate:      500,
+		}
+		start := time.Now()
+		stats, err := w.Run(context.Background(), fscache.ScannerKeySource(src.Cache.(fscache.Scanner), ""))
+		fst.NoError(t, err)
+		fst.Equal(t, 50, stats.Keys)
+		fst.Equal(t, 45, stats.Stored)
+		fst.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ch := make(chan any)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case ch <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		w := &fscache.Warmer{Cache: newCache(t), Load: load, TTL: time.Minute, BatchSize: 5, Rate: 100}
+		stats, err := w.Run(ctx, fscache.ChanKeySource(ch))
+		fst.True(t, errors.Is(err, context.DeadlineExceeded))
+		fst.Less(t, stats.Keys, 100)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := (&fscache.Warmer{}).Run(context.Background(), fscache.SliceKeySource(keys))
+		fst.Error(t, err)
+	})
+}